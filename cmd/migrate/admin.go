@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// errAdminExists is returned by createAdmin when an admin exists and the creation is not forced
+var errAdminExists = errors.New("an admin user already exists (use -force to create another)")
+
+// adminOptions holds the details of the admin account to create
+type adminOptions struct {
+	Email     string
+	FirstName string
+	LastName  string
+	Password  string
+	// Role is the role of the new account (default admin)
+	Role  string
+	Force bool
+	// PasswordPolicy is checked against Password, as for registrations and password changes
+	PasswordPolicy utils.PasswordPolicy
+}
+
+// promptMissing asks for any admin detail not provided via flags. When in is a terminal, the
+// password is read without echoing it.
+func (o *adminOptions) promptMissing(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+
+	fields := []struct {
+		label  string
+		value  *string
+		secret bool
+	}{
+		{"Email", &o.Email, false},
+		{"First name", &o.FirstName, false},
+		{"Last name", &o.LastName, false},
+		{"Password", &o.Password, true},
+	}
+
+	for _, field := range fields {
+		if *field.value != "" {
+			continue
+		}
+
+		fmt.Fprintf(out, "%s: ", field.label)
+		if file, ok := in.(*os.File); ok && field.secret && term.IsTerminal(int(file.Fd())) {
+			password, err := term.ReadPassword(int(file.Fd()))
+			fmt.Fprintln(out)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", strings.ToLower(field.label), err)
+			}
+			*field.value = string(password)
+			continue
+		}
+
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read %s: %w", strings.ToLower(field.label), err)
+		}
+		*field.value = strings.TrimSpace(line)
+	}
+
+	return nil
+}
+
+// validate checks the admin details before anything is written
+func (o *adminOptions) validate() error {
+	o.Email = utils.NormalizeEmail(o.Email)
+	o.FirstName = utils.NormalizeName(o.FirstName)
+	o.LastName = utils.NormalizeName(o.LastName)
+	if o.Role == "" {
+		o.Role = models.RoleAdmin
+	}
+
+	if _, err := mail.ParseAddress(o.Email); err != nil {
+		return fmt.Errorf("invalid email address %q", o.Email)
+	}
+	if len(o.FirstName) < 2 || len(o.FirstName) > 50 {
+		return errors.New("first name must be between 2 and 50 characters")
+	}
+	if len(o.LastName) < 2 || len(o.LastName) > 50 {
+		return errors.New("last name must be between 2 and 50 characters")
+	}
+	if details := o.PasswordPolicy.Validate("password", o.Password); len(details) > 0 {
+		messages := make([]string, len(details))
+		for i, detail := range details {
+			messages[i] = detail.Message
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+	if !models.IsValidRole(o.Role) {
+		return fmt.Errorf("invalid role %q, must be one of: %s", o.Role, strings.Join(models.Roles, ", "))
+	}
+
+	return nil
+}
+
+// createAdmin creates an active user with the admin role, or the role in opts. Creating an
+// admin is refused when one already exists unless forced. It runs outside of any request, so
+// repository calls use a background context.
+func createAdmin(userRepo interfaces.UserRepository, opts adminOptions) (*models.User, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	if opts.Role == models.RoleAdmin && !opts.Force {
+		_, adminCount, err := userRepo.GetUsersByRoleWithCount(ctx, models.RoleAdmin, 1, 0, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for existing admins: %w", err)
+		}
+		if adminCount > 0 {
+			return nil, errAdminExists
+		}
+	}
+
+	if existing, _ := userRepo.GetByEmail(ctx, opts.Email); existing != nil {
+		return nil, fmt.Errorf("a user with email %s already exists", opts.Email)
+	}
+
+	// The operator vouches for the email, so the admin can log in without verifying it
+	now := time.Now()
+	admin := &models.User{
+		Email:     opts.Email,
+		Password:  opts.Password, // Hashed by the BeforeCreate hook
+		FirstName: opts.FirstName,
+		LastName:  opts.LastName,
+		Role:      opts.Role,
+		IsActive:  true,
+
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
+	}
+	if err := userRepo.Create(ctx, admin); err != nil {
+		return nil, fmt.Errorf("failed to create admin user: %w", err)
+	}
+
+	return admin, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCreateAdmin(t *testing.T) {
+	valid := adminOptions{Email: " Admin@Example.com ", FirstName: "Jane", LastName: "Doe", Password: "Correct7Horse", PasswordPolicy: utils.DefaultPasswordPolicy()}
+	with := func(change func(*adminOptions)) adminOptions {
+		opts := valid
+		change(&opts)
+		return opts
+	}
+
+	tests := []struct {
+		name        string
+		opts        adminOptions
+		existing    bool
+		wantErr     error
+		wantErrText string
+		wantRole    string
+	}{
+		{"First admin", valid, false, nil, "", models.RoleAdmin},
+		{"Admin already exists", valid, true, errAdminExists, "", ""},
+		{"Forced while an admin exists", with(func(o *adminOptions) { o.Force = true }), true, nil, "", models.RoleAdmin},
+		{"Editor while an admin exists", with(func(o *adminOptions) { o.Role = models.RoleEditor }), true, nil, "", models.RoleEditor},
+		{"Bad email", with(func(o *adminOptions) { o.Email = "not-an-email" }), false, nil, "invalid email address", ""},
+		{"Short password", with(func(o *adminOptions) { o.Password = "Abc12" }), false, nil, "password must be at least 8 characters", ""},
+		{"Weak password", with(func(o *adminOptions) { o.Password = "correcthorse" }), false, nil, "password must contain an uppercase letter; password must contain a digit", ""},
+		{"Short password under a longer minimum", with(func(o *adminOptions) { o.PasswordPolicy.MinLength = 16 }), false, nil, "password must be at least 16 characters", ""},
+		{"Invalid role", with(func(o *adminOptions) { o.Role = "root" }), false, nil, "invalid role", ""},
+		{"Short first name", with(func(o *adminOptions) { o.FirstName = " J " }), false, nil, "first name", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+			if err != nil {
+				t.Fatalf("Failed to connect to test database: %v", err)
+			}
+			if err := db.AutoMigrate(&models.User{}); err != nil {
+				t.Fatalf("Failed to migrate test database: %v", err)
+			}
+			if tt.existing {
+				if err := db.Create(&models.User{Email: "owner@example.com", Password: "Correct7Horse", FirstName: "Owner", LastName: "User", Role: models.RoleAdmin, IsActive: true}).Error; err != nil {
+					t.Fatalf("Failed to create existing admin: %v", err)
+				}
+			}
+
+			user, err := createAdmin(postgres.NewUserRepository(db), tt.opts)
+			if tt.wantErr != nil || tt.wantErrText != "" {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Fatalf("createAdmin() error = %v, want %v %q", err, tt.wantErr, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("createAdmin() error = %v", err)
+			}
+
+			if user.Email != "admin@example.com" || user.Role != tt.wantRole || !user.IsActive || !user.EmailVerified {
+				t.Errorf("createAdmin() = %s %s active=%v verified=%v, want admin@example.com %s active and verified", user.Email, user.Role, user.IsActive, user.EmailVerified, tt.wantRole)
+			}
+			if !user.CheckPassword("Correct7Horse") {
+				t.Errorf("createAdmin() did not store a usable password hash")
+			}
+		})
+	}
+}
+
+func TestAdminOptions_PromptMissing(t *testing.T) {
+	opts := adminOptions{Email: "admin@example.com"}
+	var out strings.Builder
+
+	if err := opts.promptMissing(strings.NewReader("Jane\nDoe\nCorrect7Horse\n"), &out); err != nil {
+		t.Fatalf("promptMissing() error = %v", err)
+	}
+	if opts.FirstName != "Jane" || opts.LastName != "Doe" || opts.Password != "Correct7Horse" {
+		t.Errorf("promptMissing() = %+v, want Jane Doe Correct7Horse", opts)
+	}
+	if strings.Contains(out.String(), "Email") {
+		t.Errorf("promptMissing() asked for the email given as a flag: %q", out.String())
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// migrationsDir is where -create writes new migrations, relative to the repository root
+const migrationsDir = "internal/database/migrations/versions"
+
+// migrationFile matches the name of a migration file and captures its sequence number
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.go$`)
+
+// migrationStub is the generated migration; it compiles as is, doing nothing until filled in
+var migrationStub = template.Must(template.New("migration").Parse(`package versions
+
+import (
+	"gorm.io/gorm"
+)
+
+// Migration version: {{.Version}}
+func {{.Func}}() MigrationStep {
+	return MigrationStep{
+		Version:     "{{.Version}}",
+		Description: "{{.Description}}",
+		Up: func(tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	}
+}
+`))
+
+// createMigration writes a migration stub for name to dir, numbered after the highest existing
+// migration, and returns the path of the new file and the name of its function
+func createMigration(dir, name string) (path, funcName string, err error) {
+	name = migrationName(name)
+	if name == "" {
+		return "", "", errors.New("migration name must contain letters or digits")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	last := 0
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		if match[2] == name {
+			return "", "", fmt.Errorf("migration %s already exists as %s", name, entry.Name())
+		}
+		if number, _ := strconv.Atoi(match[1]); number > last {
+			last = number
+		}
+	}
+
+	prefix := fmt.Sprintf("%03d", last+1)
+	version := prefix + "_" + name
+	funcName = "Migration" + prefix + camelCase(name)
+	path = filepath.Join(dir, version+".go")
+
+	// O_EXCL keeps an existing file intact should one appear meanwhile
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	err = migrationStub.Execute(file, map[string]string{
+		"Version":     version,
+		"Func":        funcName,
+		"Description": strings.ToUpper(name[:1]) + strings.ReplaceAll(name[1:], "_", " "),
+	})
+	if err != nil {
+		os.Remove(path)
+		return "", "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, funcName, nil
+}
+
+// migrationName turns a name such as "Add articles-table" into add_articles_table
+func migrationName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	return strings.Join(words, "_")
+}
+
+// camelCase turns add_articles_table into AddArticlesTable
+func camelCase(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word != "" {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupMigrationsDir creates a versions directory holding empty files with the given names
+func setupMigrationsDir(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package versions\n"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := setupMigrationsDir(t, "001_create_users_table.go", "008_add_user_token_version.go", "migration.go", "004_create_sessions_table_test.go")
+
+	path, funcName, err := createMigration(dir, "Add articles-table")
+	if err != nil {
+		t.Fatalf("createMigration() error = %v", err)
+	}
+	if want := filepath.Join(dir, "009_add_articles_table.go"); path != want {
+		t.Errorf("createMigration() path = %q, want %q", path, want)
+	}
+	if funcName != "Migration009AddArticlesTable" {
+		t.Errorf("createMigration() funcName = %q, want %q", funcName, "Migration009AddArticlesTable")
+	}
+
+	// The stub is valid Go declaring the migration function
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		t.Fatalf("generated file does not parse: %v", err)
+	}
+	if file.Name.Name != "versions" || file.Scope.Lookup(funcName) == nil {
+		t.Errorf("generated file declares package %s without %s", file.Name.Name, funcName)
+	}
+	content, _ := os.ReadFile(path)
+	for _, want := range []string{`Version:     "009_add_articles_table"`, `Description: "Add articles table"`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("generated file has no %s:\n%s", want, content)
+		}
+	}
+
+	// The next migration follows the new one
+	if path, _, err := createMigration(dir, "add_tags"); err != nil || filepath.Base(path) != "010_add_tags.go" {
+		t.Errorf("createMigration() = %q, %v, want 010_add_tags.go", path, err)
+	}
+}
+
+func TestCreateMigration_Rejected(t *testing.T) {
+	dir := setupMigrationsDir(t, "001_create_users_table.go")
+
+	tests := []struct {
+		name          string
+		migrationName string
+	}{
+		{"Existing name", "create users table"},
+		{"No letters or digits", " -- "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := createMigration(dir, tt.migrationName); err == nil {
+				t.Errorf("createMigration(%q) error = nil, want an error", tt.migrationName)
+			}
+		})
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory has %d files, want only the existing migration", len(entries))
+	}
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
 	"customable-corporate-site-api/internal/config"
 	"customable-corporate-site-api/internal/database"
 	"customable-corporate-site-api/internal/database/migrations"
+	"customable-corporate-site-api/internal/database/seeders"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/preflight"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"customable-corporate-site-api/internal/utils"
 
 	"flag"
 	"fmt"
@@ -16,14 +22,38 @@ func main() {
 	// Define command-line flags and initialize the application
 	upCmd := flag.Bool("up", false, "Run all pending migrations")
 	downCmd := flag.Bool("down", false, "Roll back the last migration")
+	toCmd := flag.String("to", "", "Apply or roll back migrations until the given version, e.g. 005 or 005_create_audit_logs_table")
 	statusCmd := flag.Bool("status", false, "Show migration status")
 	resetCmd := flag.Bool("reset", false, "Reset the database (WARNING: drops all data)")
-	createCmd := flag.String("create", "", "Create a new migration file ")
+	seedCmd := flag.Bool("seed", false, "Run pending seeders for the current SERVER_MODE")
+	createCmd := flag.String("create", "", "Create a migration file with the given name, e.g. add_articles_table")
+	createAdminCmd := flag.Bool("create-admin", false, "Create an admin user (prompts for any missing details)")
+
+	// Admin user details for -create-admin
+	var admin adminOptions
+	flag.StringVar(&admin.Email, "email", "", "Admin email for -create-admin")
+	flag.StringVar(&admin.FirstName, "first-name", "", "Admin first name for -create-admin")
+	flag.StringVar(&admin.LastName, "last-name", "", "Admin last name for -create-admin")
+	flag.StringVar(&admin.Password, "password", "", "Admin password for -create-admin")
+	flag.StringVar(&admin.Role, "role", models.RoleAdmin, "Role of the user created by -create-admin")
+	flag.BoolVar(&admin.Force, "force", false, "Create the admin even if one already exists")
 
 	flag.Parse()
 
+	// Creating a migration only writes a file, so it needs neither configuration nor database
+	if *createCmd != "" {
+		path, funcName, err := createMigration(migrationsDir, *createCmd)
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		log.Printf("Created migration %s", path)
+		log.Printf("Register it in internal/database/migrations/registry.go with migrator.Register(versions.%s())", funcName)
+		return
+	}
+
 	// Load configuration
 	cfg := config.Load()
+	utils.SetCollapseNameWhitespace(cfg.Server.CollapseNameWhitespace)
 
 	// Connect to the database
 	db, err := database.ConnectDB(cfg)
@@ -31,6 +61,22 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Stop on configuration or connectivity problems before touching the schema
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database instance: %v", err)
+	}
+	report := preflight.Check(context.Background(), cfg, sqlDB)
+	report.LogWarnings()
+	if err := report.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Seeded users are hashed like the ones the server creates
+	if err := models.SetBcryptCost(cfg.Password.BcryptCost); err != nil {
+		log.Fatalf("Failed to set bcrypt cost: %v", err)
+	}
+
 	// Register migrations
 	migrator := migrations.RegisterMigrations(db)
 
@@ -46,6 +92,11 @@ func main() {
 			log.Fatalf("Migration down failed: %v", err)
 		}
 
+	case *toCmd != "":
+		if err := migrator.MigrateTo(*toCmd); err != nil {
+			log.Fatalf("Migration to %s failed: %v", *toCmd, err)
+		}
+
 	case *statusCmd:
 		if err := migrator.Status(); err != nil {
 			log.Fatalf("Failed to get migration status: %v", err)
@@ -66,10 +117,27 @@ func main() {
 			log.Fatalf("Database reset failed: %v", err)
 		}
 
-	case *createCmd != "":
-		log.Printf("Creating migration: %s\n", *createCmd)
-		// Implement migration file creation
-		log.Println("Migration file creation not implemented yet")
+		// The seeded rows were dropped with their tables, so seed again from scratch
+		if err := seeders.RegisterSeeders(db, cfg.Seed).Reset(cfg.Server.Mode); err != nil {
+			log.Fatalf("Seeding after reset failed: %v", err)
+		}
+
+	case *seedCmd:
+		if err := seeders.RegisterSeeders(db, cfg.Seed).Run(cfg.Server.Mode); err != nil {
+			log.Fatalf("Seeding failed: %v", err)
+		}
+
+	case *createAdminCmd:
+		if err := admin.promptMissing(os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("Failed to read admin details: %v", err)
+		}
+
+		admin.PasswordPolicy = cfg.Password.Policy()
+		user, err := createAdmin(postgres.NewUserRepository(db), admin)
+		if err != nil {
+			log.Fatalf("Failed to create admin user: %v", err)
+		}
+		log.Printf("Created %s user %s with ID %d", user.Role, user.Email, user.ID)
 
 	default:
 		flag.Usage()
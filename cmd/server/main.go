@@ -1,15 +1,36 @@
 package main
 
 import (
+	"context"
+	"crypto/rsa"
 	"customable-corporate-site-api/internal/config"
 	"customable-corporate-site-api/internal/database"
+	"customable-corporate-site-api/internal/events"
 	"customable-corporate-site-api/internal/handlers"
+	handlersv2 "customable-corporate-site-api/internal/handlers/v2"
+	"customable-corporate-site-api/internal/health"
+	"customable-corporate-site-api/internal/jobs"
 	"customable-corporate-site-api/internal/middleware"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/preflight"
 	"customable-corporate-site-api/internal/repositories/postgres"
 	"customable-corporate-site-api/internal/services"
+	"customable-corporate-site-api/internal/store"
+	"customable-corporate-site-api/internal/tracing"
+	"customable-corporate-site-api/internal/utils"
+	"expvar"
+	"io"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -22,22 +43,225 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Refuse to start until the configuration and database are usable, listing every problem
+	checkPreflight(config, db)
+
+	if err := models.SetBcryptCost(config.Password.BcryptCost); err != nil {
+		log.Fatalf("Failed to set bcrypt cost: %v", err)
+	}
+
 	// Auto-migrate database schemas
 	if err := database.AutoMigrate(db); err != nil {
 		log.Fatalf("Failed to auto-migrate database: %v", err)
 	}
 
+	// Export traces over OTLP when a collector is configured; otherwise tracing stays off
+	var tracerProvider trace.TracerProvider
+	if config.Tracing.Enabled {
+		provider, err := tracing.NewProvider(context.Background(), config.Tracing.ServiceName)
+		if err != nil {
+			log.Fatalf("Failed to set up tracing: %v", err)
+		}
+		defer provider.Shutdown(context.Background())
+
+		if err := db.Use(database.NewTracingPlugin(provider)); err != nil {
+			log.Fatalf("Failed to instrument database: %v", err)
+		}
+		tracerProvider = provider
+	}
+
+	// Configure the response envelope, field selection and name normalization
+	utils.SetEnvelope(config.Server.ResponseEnvelope)
+	utils.SetStrictFields(config.Server.StrictFields)
+	utils.SetCollapseNameWhitespace(config.Server.CollapseNameWhitespace)
+
 	// Initialize repositories
 	userRepo := postgres.NewUserRepository(db)
+	sessionRepo := postgres.NewSessionRepository(db)
+	auditRepo := postgres.NewAuditLogRepository(db)
+	tokenBlacklist := postgres.NewTokenBlacklist(db)
+
+	// Store backing request state shared across requests (idempotency keys, login attempts,
+	// bulk delete confirmations, verification resends)
+	requestStore := store.NewMemoryStore()
+
+	// Lifecycle events shared by services and the admin event stream
+	eventBus := events.NewBus()
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo, config.JWT.Secret, config.JWT.ExpiresIn)
+	authConfig := services.AuthConfig{
+		JWTSecret:         config.JWT.Secret,
+		JWTExpiry:         config.JWT.ExpiresIn,
+		RoleExpiry:        config.JWT.RoleExpiresIn,
+		RefreshExpiry:     config.JWT.RefreshExpiresIn,
+		RememberMeExpiry:  config.JWT.RememberMeExpiresIn,
+		EmailChangeExpiry: config.JWT.EmailChangeExpiresIn,
+		EmailVerifyExpiry: config.JWT.EmailVerifyExpiresIn,
+		SigningKeys:       config.JWT.Keys,
+		ActiveKeyID:       config.JWT.ActiveKeyID,
+		Sessions:          sessionRepo,
+		Blacklist:         tokenBlacklist,
+		LockoutAttempts:   config.LoginLockout.Attempts,
+		LockoutDuration:   config.LoginLockout.Duration,
+		PasswordPolicy:    config.Password.Policy(),
+		Events:            eventBus,
+
+		BlockedEmailDomains:     config.EmailValidation.BlockedDomains,
+		ReservedEmailLocalParts: config.EmailValidation.ReservedLocalParts,
+		ReservedEmailPatterns:   config.EmailValidation.ReservedPatterns,
+
+		EmailChangeURL:           config.Mail.ConfirmEmailURL,
+		EmailVerifyURL:           config.Mail.VerifyEmailURL,
+		RequireEmailVerification: config.EmailValidation.RequireVerification,
+		VerifyResends:            requestStore,
+		VerifyResendCooldown:     config.Mail.VerifyResendCooldown,
+	}
+	privateKey, publicKey := rsaKeys(config.JWT)
+	authConfig.PrivateKey = privateKey
+	if config.EmailValidation.CheckMX {
+		authConfig.MXResolver = net.DefaultResolver
+		authConfig.MXTimeout = config.EmailValidation.MXTimeout
+		authConfig.MXStrict = config.EmailValidation.MXStrict
+	}
+	if config.Captcha.Provider != "" {
+		captcha, err := services.NewCaptchaVerifier(config.Captcha.Provider, config.Captcha.Secret)
+		if err != nil {
+			log.Fatalf("Failed to configure CAPTCHA verification: %v", err)
+		}
+		authConfig.Captcha = captcha
+	}
+	if config.Mail.Host != "" {
+		authConfig.Mailer = services.NewSMTPMailer(config.Mail.Host, config.Mail.Port, config.Mail.User, config.Mail.Password, config.Mail.From)
+	} else {
+		authConfig.Mailer = services.NewLogMailer()
+	}
+	authService := services.NewAuthServiceWithConfig(userRepo, authConfig)
+	userService := services.NewUserServiceWithConfig(userRepo, auditRepo, services.UserServiceConfig{
+		Confirmations: requestStore,
+		Sessions:      sessionRepo,
+		Verification:  authService,
+	})
+	dashboardService := services.NewDashboardService(userRepo, auditRepo)
+	auditLogService := services.NewAuditLogService(auditRepo)
+
+	// Background jobs
+	scheduler := jobs.NewScheduler()
+	scheduler.Add(jobs.Job{
+		Name:     "purge-revoked-tokens",
+		Interval: revokedTokenPurgeInterval,
+		Run: func(ctx context.Context) error {
+			_, err := tokenBlacklist.DeleteExpired(ctx)
+			return err
+		},
+	})
+	if config.Retention.PurgeDeletedUsers {
+		scheduler.Add(jobs.Job{
+			Name:     "purge-deleted-users",
+			Interval: config.Retention.PurgeInterval,
+			Run: func(ctx context.Context) error {
+				purged, err := userService.PurgeDeletedUsers(ctx, config.Retention.DeletedUserMaxAge, config.Retention.PurgeBatchSize)
+				if purged > 0 {
+					log.Printf("Purged %d deleted users", purged)
+				}
+				return err
+			},
+		})
+	}
+	scheduler.Start(context.Background())
+	defer scheduler.Stop()
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
+	authHandler := handlers.NewAuthHandlerWithConfig(authService, handlers.AuthHandlerConfig{
+		CookieMode: config.JWT.CookieMode,
+	})
+	adminHandler := handlers.NewAdminHandler(eventBus, dashboardService, auditLogService)
+	userHandler := handlers.NewUserHandler(userService)
+	authHandlerV2 := handlersv2.NewAuthHandler(authService)
+	healthHandler := handlers.NewHealthHandler(healthChecks(config, db), db)
+
+	// Throttle login attempts per email and per client IP
+	loginThrottle := middleware.LoginThrottleConfig{
+		Store:       requestStore,
+		EmailLimit:  config.LoginThrottle.EmailLimit,
+		EmailWindow: config.LoginThrottle.EmailWindow,
+		IPLimit:     config.LoginThrottle.IPLimit,
+		IPWindow:    config.LoginThrottle.IPWindow,
+	}
+
+	// Accept the access token cookie when tokens are delivered as cookies
+	jwtConfig := middleware.JWTAuthConfig{
+		Secret: config.JWT.Secret,
+		Keys:   config.JWT.Keys,
+		Leeway: config.JWT.Leeway,
+
+		PublicKey: publicKey,
+
+		// Reject access tokens issued before the user's tokens were revoked
+		TokenVersions: authService,
+		// Reject access tokens revoked by logging out
+		Blacklist: authService,
+	}
+	if config.JWT.CookieMode {
+		jwtConfig.CookieName = middleware.AccessTokenCookie
+	}
+
+	// Refuse to start with a CORS configuration browsers would reject
+	corsConfig := middleware.DefaultCORSConfig()
+	corsConfig.AllowedOrigins = config.CORS.AllowedOrigins
+	corsConfig.AllowCredentials = config.CORS.AllowCredentials
+	corsConfig = middleware.MustValidateCORSConfig(corsConfig)
+
+	// Cap the requests in flight; admins can watch the counters at /api/v1/admin/vars
+	concurrencyLimiter := middleware.NewConcurrencyLimiter(middleware.ConcurrencyLimitConfig{
+		Max:          config.Server.MaxConcurrentRequests,
+		QueueTimeout: config.Server.ConcurrencyQueueTimeout,
+		SkipPaths:    streamingPaths,
+	})
+	expvar.Publish("requests_in_flight", expvar.Func(func() interface{} { return concurrencyLimiter.InFlight() }))
+	expvar.Publish("requests_rejected", expvar.Func(func() interface{} { return concurrencyLimiter.Rejected() }))
+
+	// Panic messages are only shown outside production; the stack is always logged
+	recovery := middleware.RecoveryWithConfig(middleware.RecoveryConfig{
+		ShowErrors: config.Server.Mode != "production",
+	})
+
+	// Limit each client IP; health checks stay exempt
+	rateLimitConfig := middleware.DefaultRateLimitConfig()
+	rateLimitConfig.RequestsPerSecond = float64(config.RateLimit.RequestsPerSecond)
+	rateLimitConfig.Burst = config.RateLimit.Burst
+
+	// Maintenance mode, CORS origins and login throttle limits can be reloaded without a restart
+	live := liveConfig(config, corsConfig)
 
 	// Set up Gin router
-	router := setupRouter(authHandler, config.JWT.Secret)
+	router := setupRouter(routerConfig{
+		AuthHandler:   authHandler,
+		AdminHandler:  adminHandler,
+		UserHandler:   userHandler,
+		AuthHandlerV2: authHandlerV2,
+		HealthHandler: healthHandler,
+
+		JWT: jwtConfig,
+		APIKeys: middleware.APIKeyAuthConfig{
+			Keys: config.APIKeys.Keys,
+			Role: config.APIKeys.Role,
+		},
+		CORS:          corsConfig,
+		Store:         requestStore,
+		LoginThrottle: loginThrottle,
+
+		Recovery:         recovery,
+		AccessLog:        accessLogger(config.Log),
+		RateLimit:        middleware.RateLimit(rateLimitConfig),
+		ConcurrencyLimit: concurrencyLimiter.Handler(),
+		RequestTimeout:   config.Server.RequestTimeout,
+		Redirects: redirectPolicy{
+			TrailingSlash: config.Server.RedirectTrailingSlash,
+			FixedPath:     config.Server.RedirectFixedPath,
+		},
+		TracerProvider: tracerProvider,
+		Live:           live,
+	})
 
 	// Start the server
 	log.Printf("Starting server on port %s...", config.Server.Port)
@@ -45,41 +269,401 @@ func main() {
 	log.Fatal(router.Run(":" + config.Server.Port))
 }
 
-func setupRouter(authHandler *handlers.AuthHandler, jwtSecret string) *gin.Engine {
+// redirectPolicy chooses how the router treats a path that matches no route but would after
+// fixing it. When off, the request gets the standard 404. When on, gin redirects GET requests
+// with a 301 and other methods with a 307, which keeps the method and body, although many
+// API clients still do not follow redirects for POST.
+type redirectPolicy struct {
+	// TrailingSlash redirects /path/ to /path, or /path to /path/
+	TrailingSlash bool
+	// FixedPath redirects paths with the wrong case or redundant elements such as // and ..
+	FixedPath bool
+}
+
+// routerConfig holds the handlers, middleware and settings setupRouter wires together.
+// Optional middleware left nil is not installed, so tests set only what they exercise.
+type routerConfig struct {
+	AuthHandler   *handlers.AuthHandler
+	AdminHandler  *handlers.AdminHandler
+	UserHandler   *handlers.UserHandler
+	AuthHandlerV2 *handlersv2.AuthHandler
+	HealthHandler *handlers.HealthHandler
+
+	JWT     middleware.JWTAuthConfig
+	APIKeys middleware.APIKeyAuthConfig
+	CORS    middleware.CORSConfig
+	// Store keeps idempotent responses (default: an in-memory store)
+	Store store.Store
+	// LoginThrottle limits login attempts (default: DefaultLoginThrottleConfig on Store)
+	LoginThrottle middleware.LoginThrottleConfig
+
+	// Recovery answers panics (default: middleware.Recovery())
+	Recovery         gin.HandlerFunc
+	AccessLog        gin.HandlerFunc
+	RateLimit        gin.HandlerFunc
+	ConcurrencyLimit gin.HandlerFunc
+	// RequestTimeout bounds each request except the streaming ones; 0 disables it
+	RequestTimeout time.Duration
+	Redirects      redirectPolicy
+	TracerProvider trace.TracerProvider
+	// Live, when set, makes CORS origins, login throttle limits and maintenance mode reloadable
+	Live *config.Live
+}
+
+func setupRouter(cfg routerConfig) *gin.Engine {
+	if cfg.Store == nil {
+		cfg.Store = store.NewMemoryStore()
+	}
+	if cfg.LoginThrottle.Store == nil {
+		cfg.LoginThrottle = middleware.DefaultLoginThrottleConfig(cfg.Store)
+	}
+	if cfg.Recovery == nil {
+		cfg.Recovery = middleware.Recovery()
+	}
+
 	// Create a Gin router
-	router := gin.Default()
+	router := gin.New()
+	router.RedirectTrailingSlash = cfg.Redirects.TrailingSlash
+	router.RedirectFixedPath = cfg.Redirects.FixedPath
 
-	// Global middleware
-	router.Use(middleware.CORS())
-	router.Use(middleware.Logger())
+	// Global middleware. Panics anywhere below get the standard 500 response.
+	router.Use(gin.Logger(), cfg.Recovery)
+	router.Use(middleware.RequestIDMiddleware())
+	if cfg.TracerProvider != nil {
+		router.Use(middleware.Tracing(cfg.TracerProvider))
+	}
+	// With a live configuration, the reloadable settings are read on every request
+	cors := middleware.CORSWithConfig(cfg.CORS)
+	throttle := middleware.LoginThrottleWithConfig(cfg.LoginThrottle)
+	var configHandler *handlers.ConfigHandler
+	if cfg.Live != nil {
+		cors = reloadable(cfg.Live, func(reloaded *config.Config) gin.HandlerFunc {
+			corsConfig := cfg.CORS
+			corsConfig.AllowedOrigins = reloaded.CORS.AllowedOrigins
+			return middleware.CORSWithConfig(corsConfig)
+		})
+		throttle = reloadable(cfg.Live, func(reloaded *config.Config) gin.HandlerFunc {
+			loginThrottle := cfg.LoginThrottle
+			loginThrottle.EmailLimit = reloaded.LoginThrottle.EmailLimit
+			loginThrottle.EmailWindow = reloaded.LoginThrottle.EmailWindow
+			loginThrottle.IPLimit = reloaded.LoginThrottle.IPLimit
+			loginThrottle.IPWindow = reloaded.LoginThrottle.IPWindow
+			return middleware.LoginThrottleWithConfig(loginThrottle)
+		})
+		configHandler = handlers.NewConfigHandler(cfg.Live)
+	}
+
+	router.Use(cors)
+	if cfg.AccessLog != nil {
+		router.Use(cfg.AccessLog)
+	}
+	if cfg.Live != nil {
+		router.Use(reloadable(cfg.Live, func(reloaded *config.Config) gin.HandlerFunc {
+			return middleware.MaintenanceWithConfig(middleware.MaintenanceConfig{
+				Enabled: reloaded.Server.MaintenanceMode,
+				Allow:   maintenanceAllowedPaths,
+			})
+		}))
+	}
+	if cfg.RateLimit != nil {
+		router.Use(cfg.RateLimit)
+	}
+	if cfg.ConcurrencyLimit != nil {
+		router.Use(cfg.ConcurrencyLimit)
+	}
+	router.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		Timeout:   cfg.RequestTimeout,
+		SkipPaths: streamingPaths,
+	}))
+
+	// Unknown routes and wrong methods get the standard envelope instead of gin's plain text.
+	// Global middleware also runs in front of these handlers, so CORS still answers
+	// OPTIONS preflight with 204 for any path before they are reached.
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(func(c *gin.Context) {
+		utils.NotFoundResponse(c, "Route "+c.Request.URL.Path)
+	})
+	router.NoMethod(func(c *gin.Context) {
+		utils.MethodNotAllowedResponse(c, "Method "+c.Request.Method+" is not allowed on "+c.Request.URL.Path)
+	})
+
+	// Each API version registers its handlers on its own group. The versions share the
+	// services and middleware and differ only in the shape of their requests and responses.
+	jwtAuth := middleware.JWTAuthWithConfig(cfg.JWT)
+	routes := apiRoutes{
+		authHandler:   cfg.AuthHandler,
+		adminHandler:  cfg.AdminHandler,
+		userHandler:   cfg.UserHandler,
+		authHandlerV2: cfg.AuthHandlerV2,
+		healthHandler: cfg.HealthHandler,
+		configHandler: configHandler,
+		jwtAuth:       jwtAuth,
+		userAdminAuth: jwtAuth,
+		idempotency:   middleware.Idempotency(cfg.Store),
+		loginThrottle: throttle,
+	}
+	if len(cfg.APIKeys.Keys) > 0 {
+		routes.userAdminAuth = apiKeyOrJWT(middleware.APIKeyAuthWithConfig(cfg.APIKeys), jwtAuth)
+	}
+	registerV1Routes(router.Group("/api/v1"), routes)
+	registerV2Routes(router.Group("/api/v2"), routes)
+
+	return router
+}
 
-	// API v1 routes
-	api := router.Group("/api/v1")
+// apiRoutes holds the handlers and route middleware shared by the API versions
+type apiRoutes struct {
+	authHandler   *handlers.AuthHandler
+	adminHandler  *handlers.AdminHandler
+	userHandler   *handlers.UserHandler
+	authHandlerV2 *handlersv2.AuthHandler
+	healthHandler *handlers.HealthHandler
+	configHandler *handlers.ConfigHandler
 
+	jwtAuth gin.HandlerFunc
+	// userAdminAuth also accepts API keys, when configured, for reads on the admin user
+	// endpoints
+	userAdminAuth gin.HandlerFunc
+	idempotency   gin.HandlerFunc
+	loginThrottle gin.HandlerFunc
+}
+
+// registerV1Routes registers the stable v1 API, whose responses use the standard envelope
+func registerV1Routes(api *gin.RouterGroup, routes apiRoutes) {
 	// Public auth routes
 	auth := api.Group("/auth")
+	auth.Use(middleware.RequireJSON())
 	{
-		auth.POST("/register", authHandler.Register)
-		auth.POST("/login", authHandler.Login)
-		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/register", routes.idempotency, routes.authHandler.Register)
+		auth.POST("/login", routes.loginThrottle, routes.authHandler.Login)
+		auth.POST("/refresh", routes.authHandler.RefreshToken)
+		auth.POST("/logout", routes.authHandler.Logout)
+		auth.GET("/confirm-email", routes.authHandler.ConfirmEmailChange)
+		auth.GET("/verify-email", routes.authHandler.VerifyEmail)
+		auth.POST("/resend-verification", routes.authHandler.ResendVerification)
+		// Verifies the token itself, so expired tokens can still be described
+		auth.GET("/token/introspect", routes.authHandler.IntrospectToken)
 	}
 
 	// Protected routes
 	protected := api.Group("")
-	protected.Use(middleware.JWTAuth(jwtSecret))
+	protected.Use(routes.jwtAuth, middleware.RequireJSON())
 	{
-		protected.GET("/auth/profile", authHandler.GetProfile)
-		protected.PUT("/auth/profile", authHandler.UpdateProfile)
+		protected.GET("/auth/profile", routes.authHandler.GetProfile)
+		protected.PUT("/auth/profile", routes.authHandler.UpdateProfile)
+		protected.PATCH("/auth/profile", routes.authHandler.PatchProfile)
+		protected.POST("/auth/change-email", routes.authHandler.RequestEmailChange)
+		protected.PUT("/auth/password", routes.authHandler.ChangePassword)
+		protected.DELETE("/auth/account", routes.authHandler.DeleteAccount)
+		protected.GET("/auth/sessions", routes.authHandler.GetSessions)
+		protected.DELETE("/auth/sessions", routes.authHandler.RevokeAllSessions)
+		protected.DELETE("/auth/sessions/:id", routes.authHandler.RevokeSession)
 	}
 
-	// Health check endpoint
-	api.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "OK",
-			"message": "API is healthy",
-			"version": "1.0.0",
-		})
+	// Admin routes
+	admin := api.Group("/admin")
+	admin.Use(routes.jwtAuth, middleware.RequireAdmin())
+	{
+		admin.GET("/dashboard", routes.adminHandler.GetDashboard)
+		admin.GET("/events", routes.adminHandler.Events)
+		admin.GET("/audit-logs", routes.adminHandler.ListAuditLogs)
+		// Runtime counters such as requests_in_flight, in expvar's JSON format
+		admin.GET("/vars", gin.WrapH(expvar.Handler()))
+		// Only servers with a live configuration can reload it
+		if routes.configHandler != nil {
+			admin.POST("/reload-config", routes.configHandler.Reload)
+		}
+	}
+
+	// Admin user management routes
+	users := api.Group("/users")
+	users.Use(routes.userAdminAuth, middleware.RequireAdmin(), middleware.RequireJSON())
+	{
+		users.GET("", routes.userHandler.ListUsers)
+		users.GET("/search", routes.userHandler.SearchUsers)
+		users.DELETE("/bulk", routes.userHandler.BulkDeleteUsers)
+		users.PATCH("/bulk/status", routes.userHandler.BulkUpdateUserStatus)
+		users.GET("/active", routes.userHandler.ListActiveUsers)
+		users.GET("/deleted", routes.userHandler.ListDeletedUsers)
+		users.GET("/export", routes.userHandler.ExportUsers)
+		users.GET("/role/:role", routes.userHandler.ListUsersByRole)
+		users.GET("/:id", routes.userHandler.GetUser)
+		users.PUT("/:id", routes.userHandler.UpdateUser)
+		users.PATCH("/:id/role", routes.userHandler.UpdateUserRole)
+		users.PATCH("/:id/status", routes.userHandler.UpdateUserStatus)
+		users.POST("/:id/revoke-tokens", routes.userHandler.RevokeTokens)
+		users.POST("/:id/restore", routes.userHandler.RestoreUser)
+	}
+
+	// Health check endpoint; load balancers and uptime checkers may probe it with HEAD
+	utils.GETWithHEAD(api, "/health", routes.healthHandler.Health)
+	utils.GETWithHEAD(api, "/health/ready", routes.healthHandler.Ready)
+}
+
+// registerV2Routes registers the v2 API, whose success responses are returned without the
+// envelope. Endpoints are added here as they gain a v2 representation.
+func registerV2Routes(api *gin.RouterGroup, routes apiRoutes) {
+	// Protected routes
+	protected := api.Group("")
+	protected.Use(routes.jwtAuth, middleware.RequireJSON())
+	{
+		protected.GET("/auth/profile", routes.authHandlerV2.GetProfile)
+	}
+}
+
+// checkPreflight runs the preflight check, logging its warnings and exiting on any error
+func checkPreflight(config *config.Config, db *gorm.DB) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database instance: %v", err)
+	}
+
+	report := preflight.Check(context.Background(), config, sqlDB)
+	report.LogWarnings()
+	if err := report.Err(); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Preflight check passed")
+}
+
+// revokedTokenPurgeInterval is how often expired entries are evicted from the token blacklist
+const revokedTokenPurgeInterval = time.Hour
+
+// maintenanceAllowedPaths are still served in maintenance mode, so load balancers keep the
+// server and admins can log in and switch maintenance off again
+var maintenanceAllowedPaths = []string{
+	"/api/v1/health",
+	"/api/v1/admin",
+	"/api/v1/auth/login",
+	"/api/v1/auth/refresh",
+}
+
+// streamingPaths keep their response open for as long as the client listens, so they run
+// without the request timeout and outside the concurrency limit
+var streamingPaths = []string{
+	"/api/v1/admin/events",
+	"/api/v1/users/export",
+}
+
+// liveConfig holds the configuration for reloading, refusing reloaded CORS origins that
+// browsers would reject
+func liveConfig(cfg *config.Config, corsConfig middleware.CORSConfig) *config.Live {
+	return config.NewLiveWithConfig(cfg, config.LiveConfig{
+		Validate: func(reloaded *config.Config) error {
+			corsConfig.AllowedOrigins = reloaded.CORS.AllowedOrigins
+			return corsConfig.Validate()
+		},
 	})
+}
 
-	return router
+// apiKeyOrJWT authenticates requests carrying an X-API-Key header with apiKeyAuth and all
+// others with jwtAuth. API keys are read-only: they carry no user, while every write records
+// the acting user on the audit log, so writes with a key are refused with 403.
+func apiKeyOrJWT(apiKeyAuth, jwtAuth gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(middleware.APIKeyHeader) == "" {
+			jwtAuth(c)
+			return
+		}
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			utils.ForbiddenResponse(c, "API keys are read-only; use an access token for changes")
+			c.Abort()
+			return
+		}
+		apiKeyAuth(c)
+	}
+}
+
+// reloadable builds a middleware from the live configuration and builds it again on the
+// first request after each reload. State kept outside the middleware, such as the login
+// attempts counted in the request store, carries over.
+func reloadable(live *config.Live, build func(cfg *config.Config) gin.HandlerFunc) gin.HandlerFunc {
+	type built struct {
+		cfg     *config.Config
+		handler gin.HandlerFunc
+	}
+	var current atomic.Pointer[built]
+
+	return func(c *gin.Context) {
+		cfg := live.Current()
+		b := current.Load()
+		if b == nil || b.cfg != cfg {
+			b = &built{cfg: cfg, handler: build(cfg)}
+			current.Store(b)
+		}
+		b.handler(c)
+	}
+}
+
+// healthChecks registers the dependencies reported by the readiness endpoint. The API cannot
+// serve requests without the database; without the mail server only email delivery fails.
+func healthChecks(config *config.Config, db *gorm.DB) *health.Registry {
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database instance: %v", err)
+	}
+
+	registry := health.NewRegistry(0)
+	registry.Register(health.Database(sqlDB), true)
+	if config.Mail.Host != "" {
+		registry.Register(health.TCP("mail", net.JoinHostPort(config.Mail.Host, config.Mail.Port)), false)
+	}
+	return registry
+}
+
+// rsaKeys loads the RS256 signing and verification keys, or returns nil keys for HS256
+func rsaKeys(jwtConfig config.JWTConfig) (*rsa.PrivateKey, *rsa.PublicKey) {
+	if jwtConfig.Algorithm != "RS256" {
+		return nil, nil
+	}
+
+	pem, err := os.ReadFile(jwtConfig.PrivateKeyPath)
+	if err != nil {
+		log.Fatalf("Failed to read JWT private key: %v", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+	if err != nil {
+		log.Fatalf("Failed to parse JWT private key: %v", err)
+	}
+
+	if jwtConfig.PublicKeyPath == "" {
+		return privateKey, &privateKey.PublicKey
+	}
+
+	pem, err = os.ReadFile(jwtConfig.PublicKeyPath)
+	if err != nil {
+		log.Fatalf("Failed to read JWT public key: %v", err)
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+	if err != nil {
+		log.Fatalf("Failed to parse JWT public key: %v", err)
+	}
+	return privateKey, publicKey
+}
+
+// accessLogger keeps the console logger by default and switches to the configurable
+// logger when JSON output or a rotating log file is requested
+func accessLogger(logConfig config.LogConfig) gin.HandlerFunc {
+	if !logConfig.JSON && logConfig.FilePath == "" {
+		return middleware.Logger()
+	}
+
+	var output io.Writer = os.Stdout
+	if logConfig.FilePath != "" {
+		output = io.MultiWriter(os.Stdout, middleware.NewRotatingFileWriter(middleware.RotatingFileConfig{
+			Path:       logConfig.FilePath,
+			MaxSizeMB:  logConfig.MaxSizeMB,
+			MaxBackups: logConfig.MaxBackups,
+			MaxAgeDays: logConfig.MaxAgeDays,
+		}))
+	}
+
+	format := middleware.LogFormatText
+	if logConfig.JSON {
+		format = middleware.LogFormatJSON
+	}
+	return middleware.LoggingWithConfig(middleware.LoggerConfig{
+		Format: format,
+		Output: output,
+	})
 }
@@ -0,0 +1,496 @@
+package main
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/config"
+	"customable-corporate-site-api/internal/events"
+	"customable-corporate-site-api/internal/handlers"
+	handlersv2 "customable-corporate-site-api/internal/handlers/v2"
+	"customable-corporate-site-api/internal/health"
+	"customable-corporate-site-api/internal/middleware"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"customable-corporate-site-api/internal/services"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// testRouterConfig returns a router configuration whose handlers have no services; tests
+// replace the handlers and settings they exercise
+func testRouterConfig() routerConfig {
+	return routerConfig{
+		AuthHandler:   handlers.NewAuthHandler(nil),
+		AdminHandler:  handlers.NewAdminHandler(events.NewBus(), nil, nil),
+		UserHandler:   handlers.NewUserHandler(nil),
+		AuthHandlerV2: handlersv2.NewAuthHandler(nil),
+		HealthHandler: handlers.NewHealthHandler(health.NewRegistry(0), nil),
+
+		JWT:            middleware.JWTAuthConfig{Secret: "test_secret-key"},
+		CORS:           middleware.DefaultCORSConfig(),
+		RequestTimeout: middleware.DefaultRequestTimeout,
+	}
+}
+
+func setupTestRouter() *gin.Engine {
+	return setupTestRouterWithRedirects(redirectPolicy{})
+}
+
+func setupTestRouterWithRedirects(redirects redirectPolicy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := testRouterConfig()
+	cfg.Redirects = redirects
+	return setupRouter(cfg)
+}
+
+func TestRouter_HealthHEAD(t *testing.T) {
+	router := setupTestRouter()
+
+	req := httptest.NewRequest(http.MethodHead, "/api/v1/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("HEAD /health status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w.Body.Len() != 0 || w.Header().Get("Content-Length") == "" {
+		t.Errorf("HEAD /health body = %q, Content-Length = %q, want headers only", w.Body.String(), w.Header().Get("Content-Length"))
+	}
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	router := setupTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/login", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /auth/login status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := w.Header().Get("Allow"); !strings.Contains(allow, http.MethodPost) {
+		t.Errorf("GET /auth/login Allow = %q, want it to contain %q", allow, http.MethodPost)
+	}
+
+	var body struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response body %q: %v", w.Body.String(), err)
+	}
+	if body.Success {
+		t.Errorf("GET /auth/login success = true, want false")
+	}
+	if body.Message == "" {
+		t.Errorf("GET /auth/login message is empty, want an explanation")
+	}
+}
+
+func TestRouter_UnknownPath(t *testing.T) {
+	router := setupTestRouter()
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req := httptest.NewRequest(method, "/api/v1/does-not-exist", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s /does-not-exist status = %d, want %d", method, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestRouter_Options(t *testing.T) {
+	router := setupTestRouter()
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"Known route", "/api/v1/auth/login"},
+		{"Protected route", "/api/v1/auth/profile"},
+		{"Unknown route", "/api/v1/does-not-exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", "http://localhost:3000")
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Errorf("OPTIONS %s status = %d, want %d", tt.path, w.Code, http.StatusNoContent)
+			}
+			if w.Header().Get("Access-Control-Allow-Methods") == "" {
+				t.Errorf("OPTIONS %s is missing the CORS preflight headers", tt.path)
+			}
+		})
+	}
+}
+
+func TestRouter_NotFound(t *testing.T) {
+	router := setupTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/does-not-exist", nil)
+	req.Header.Set("X-Request-ID", "test-request-id")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("GET /does-not-exist status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("GET /does-not-exist Content-Type = %q, want application/json", ct)
+	}
+
+	var body struct {
+		Success   bool   `json:"success"`
+		Message   string `json:"message"`
+		Timestamp string `json:"timestamp"`
+		RequestID string `json:"request_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response body %q: %v", w.Body.String(), err)
+	}
+	if body.Success {
+		t.Errorf("GET /does-not-exist success = true, want false")
+	}
+	if !strings.Contains(body.Message, "/api/v1/does-not-exist") {
+		t.Errorf("GET /does-not-exist message = %q, want it to contain the path", body.Message)
+	}
+	if body.Timestamp == "" {
+		t.Errorf("GET /does-not-exist timestamp is empty")
+	}
+	if body.RequestID != "test-request-id" {
+		t.Errorf("GET /does-not-exist request_id = %q, want %q", body.RequestID, "test-request-id")
+	}
+}
+
+func TestRouter_RedirectPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		redirects    redirectPolicy
+		method       string
+		path         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"Trailing slash on POST is a 404 by default", redirectPolicy{}, http.MethodPost, "/api/v1/auth/login/", http.StatusNotFound, ""},
+		{"Trailing slash on GET is a 404 by default", redirectPolicy{}, http.MethodGet, "/api/v1/health/", http.StatusNotFound, ""},
+		{"Wrong case is a 404 by default", redirectPolicy{}, http.MethodPost, "/api/v1/Auth/Login", http.StatusNotFound, ""},
+		{"Trailing slash redirect keeps POST", redirectPolicy{TrailingSlash: true}, http.MethodPost, "/api/v1/auth/login/", http.StatusTemporaryRedirect, "/api/v1/auth/login"},
+		{"Trailing slash redirect on GET", redirectPolicy{TrailingSlash: true}, http.MethodGet, "/api/v1/health/", http.StatusMovedPermanently, "/api/v1/health"},
+		{"Fixed path redirect", redirectPolicy{FixedPath: true}, http.MethodPost, "/api/v1/Auth/Login", http.StatusTemporaryRedirect, "/api/v1/auth/login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTestRouterWithRedirects(tt.redirects)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"email":"test@example.com","password":"password123"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, tt.wantStatus)
+			}
+			if location := w.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("%s %s Location = %q, want %q", tt.method, tt.path, location, tt.wantLocation)
+			}
+			if tt.wantStatus == http.StatusNotFound && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+				t.Errorf("%s %s Content-Type = %q, want the JSON 404", tt.method, tt.path, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestRouter_ProfileVersions(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	// Both versions are served by the same service
+	authService := services.NewAuthService(postgres.NewUserRepository(db), "test_secret-key", time.Hour)
+	if _, err := authService.Register(context.Background(), &services.RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+	login, err := authService.Login(context.Background(), &services.LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to log in test user: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	cfg := testRouterConfig()
+	cfg.AuthHandler = handlers.NewAuthHandler(authService)
+	cfg.AuthHandlerV2 = handlersv2.NewAuthHandler(authService)
+	router := setupRouter(cfg)
+
+	getProfile := func(target string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", "Bearer "+login.Token.AccessToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want %d (%s)", target, w.Code, http.StatusOK, w.Body.String())
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode GET %s body: %v", target, err)
+		}
+		return body
+	}
+
+	// v1 wraps the profile in the envelope
+	v1 := getProfile("/api/v1/auth/profile")
+	data, _ := v1["data"].(map[string]interface{})
+	if v1["success"] != true || data["first_name"] != "John" {
+		t.Errorf("GET /api/v1/auth/profile body = %v, want the enveloped v1 profile", v1)
+	}
+
+	// v2 returns the profile itself, with the name grouped
+	v2 := getProfile("/api/v2/auth/profile")
+	name, _ := v2["name"].(map[string]interface{})
+	if _, wrapped := v2["success"]; wrapped || v2["email"] != "test@example.com" || name["full"] != "John Doe" {
+		t.Errorf("GET /api/v2/auth/profile body = %v, want the flat v2 profile", v2)
+	}
+
+	// v2 routes are protected like v1
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/auth/profile", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/v2/auth/profile without token status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRouter_RevokeTokens(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Session{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	userRepo := postgres.NewUserRepository(db)
+	sessionRepo := postgres.NewSessionRepository(db)
+	authService := services.NewAuthServiceWithConfig(userRepo, services.AuthConfig{
+		JWTSecret: "test_secret-key",
+		JWTExpiry: time.Hour,
+		Sessions:  sessionRepo,
+	})
+	userService := services.NewUserServiceWithConfig(userRepo, postgres.NewAuditLogRepository(db), services.UserServiceConfig{
+		Sessions: sessionRepo,
+	})
+
+	ctx := context.Background()
+	for _, email := range []string{"admin@example.com", "user@example.com"} {
+		if _, err := authService.Register(ctx, &services.RegisterRequest{Email: email, Password: "password123", FirstName: "John", LastName: "Doe"}); err != nil {
+			t.Fatalf("Failed to register %s: %v", email, err)
+		}
+	}
+	db.Model(&models.User{}).Where("email = ?", "admin@example.com").Update("role", models.RoleAdmin)
+
+	adminLogin, err := authService.Login(ctx, &services.LoginRequest{Email: "admin@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to log in admin: %v", err)
+	}
+	userLogin, err := authService.Login(ctx, &services.LoginRequest{Email: "user@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to log in user: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	jwtConfig := middleware.JWTAuthConfig{Secret: "test_secret-key", TokenVersions: authService}
+	cfg := testRouterConfig()
+	cfg.AuthHandler = handlers.NewAuthHandler(authService)
+	cfg.UserHandler = handlers.NewUserHandler(userService)
+	cfg.AuthHandlerV2 = handlersv2.NewAuthHandler(authService)
+	cfg.JWT = jwtConfig
+	router := setupRouter(cfg)
+
+	request := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(http.MethodGet, "/api/v1/auth/profile", userLogin.Token.AccessToken, ""); w.Code != http.StatusOK {
+		t.Fatalf("profile before revocation status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	target := "/api/v1/users/" + strconv.FormatUint(uint64(userLogin.User.ID), 10) + "/revoke-tokens"
+	if w := request(http.MethodPost, target, adminLogin.Token.AccessToken, ""); w.Code != http.StatusOK {
+		t.Fatalf("POST %s status = %d, want %d (%s)", target, w.Code, http.StatusOK, w.Body.String())
+	}
+
+	// The access token issued before the revocation no longer works, and neither does the refresh token
+	w := request(http.MethodGet, "/api/v1/auth/profile", userLogin.Token.AccessToken, "")
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "TOKEN_REVOKED") {
+		t.Errorf("profile after revocation = %d %s, want 401 TOKEN_REVOKED", w.Code, w.Body.String())
+	}
+	if w := request(http.MethodPost, "/api/v1/auth/refresh", "", `{"refresh_token":"`+userLogin.Token.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after revocation status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	// Other users are unaffected, and logging in again issues working tokens
+	if w := request(http.MethodGet, "/api/v1/auth/profile", adminLogin.Token.AccessToken, ""); w.Code != http.StatusOK {
+		t.Errorf("admin profile status = %d, want %d", w.Code, http.StatusOK)
+	}
+	relogin, err := authService.Login(ctx, &services.LoginRequest{Email: "user@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to log in again: %v", err)
+	}
+	if w := request(http.MethodGet, "/api/v1/auth/profile", relogin.Token.AccessToken, ""); w.Code != http.StatusOK {
+		t.Errorf("profile after logging in again status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRouter_UserAdminAPIKey(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	userService := services.NewUserService(postgres.NewUserRepository(db), postgres.NewAuditLogRepository(db))
+	gin.SetMode(gin.TestMode)
+	cfg := testRouterConfig()
+	cfg.UserHandler = handlers.NewUserHandler(userService)
+	cfg.APIKeys = middleware.APIKeyAuthConfig{Keys: []string{"integration-key"}}
+	router := setupRouter(cfg)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		apiKey     string
+		wantStatus int
+	}{
+		{"Valid key", http.MethodGet, "/api/v1/users", "integration-key", http.StatusOK},
+		{"Invalid key", http.MethodGet, "/api/v1/users", "wrong-key", http.StatusUnauthorized},
+		{"Neither key nor token", http.MethodGet, "/api/v1/users", "", http.StatusUnauthorized},
+		{"Key on a JWT-only route", http.MethodGet, "/api/v1/auth/profile", "integration-key", http.StatusUnauthorized},
+		{"Key on a write route", http.MethodPut, "/api/v1/users/1", "integration-key", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.apiKey != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d (%s)", tt.method, tt.target, w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_ReloadConfig_Maintenance(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	authService := services.NewAuthService(postgres.NewUserRepository(db), "test_secret-key", time.Hour)
+	ctx := context.Background()
+	if _, err := authService.Register(ctx, &services.RegisterRequest{Email: "admin@example.com", Password: "password123", FirstName: "John", LastName: "Doe"}); err != nil {
+		t.Fatalf("Failed to register admin: %v", err)
+	}
+	db.Model(&models.User{}).Where("email = ?", "admin@example.com").Update("role", models.RoleAdmin)
+	adminLogin, err := authService.Login(ctx, &services.LoginRequest{Email: "admin@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to log in admin: %v", err)
+	}
+
+	// Each reload toggles maintenance mode, standing in for an edited .env file
+	live := config.NewLiveWithConfig(&config.Config{}, config.LiveConfig{
+		Load: func(current *config.Config) (*config.Config, error) {
+			reloaded := *current
+			reloaded.Server.MaintenanceMode = !current.Server.MaintenanceMode
+			return &reloaded, nil
+		},
+	})
+
+	gin.SetMode(gin.TestMode)
+	cfg := testRouterConfig()
+	cfg.AuthHandler = handlers.NewAuthHandler(authService)
+	cfg.AuthHandlerV2 = handlersv2.NewAuthHandler(authService)
+	cfg.Live = live
+	router := setupRouter(cfg)
+
+	request := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+adminLogin.Token.AccessToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(http.MethodGet, "/api/v1/auth/profile"); w.Code != http.StatusOK {
+		t.Fatalf("profile before maintenance status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if w := request(http.MethodPost, "/api/v1/admin/reload-config"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "maintenance=true") {
+		t.Fatalf("reload status = %d %s, want 200 with maintenance on", w.Code, w.Body.String())
+	}
+
+	// The next request sees maintenance mode; health checks and admin routes still work
+	w := request(http.MethodGet, "/api/v1/auth/profile")
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "MAINTENANCE") {
+		t.Errorf("profile in maintenance = %d %s, want 503 MAINTENANCE", w.Code, w.Body.String())
+	}
+	if w := request(http.MethodGet, "/api/v1/health"); w.Code != http.StatusOK {
+		t.Errorf("health in maintenance status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if w := request(http.MethodPost, "/api/v1/admin/reload-config"); w.Code != http.StatusOK {
+		t.Fatalf("second reload status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if w := request(http.MethodGet, "/api/v1/auth/profile"); w.Code != http.StatusOK {
+		t.Errorf("profile after maintenance status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
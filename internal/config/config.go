@@ -1,22 +1,60 @@
 package config
 
 import (
+	"customable-corporate-site-api/internal/utils"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	JWT      JWTConfig
+	CORS     CORSConfig
+	Log      LogConfig
+
+	EmailValidation EmailValidationConfig
+	Captcha         CaptchaConfig
+	Tracing         TracingConfig
+	LoginThrottle   LoginThrottleConfig
+	LoginLockout    LoginLockoutConfig
+	Password        PasswordConfig
+	RateLimit       RateLimitConfig
+	APIKeys         APIKeyConfig
+	Retention       RetentionConfig
+	Seed            SeedConfig
+	Mail            MailConfig
 }
 
 type ServerConfig struct {
 	Port string
 	Mode string
+	// ResponseEnvelope wraps success responses in the standard envelope; when false the
+	// data is returned directly (clients can still override per request with ?envelope=)
+	ResponseEnvelope bool
+	// RequestTimeout bounds how long a request may run, including its database work; 0 disables it
+	RequestTimeout time.Duration
+	// RedirectTrailingSlash redirects /path/ to /path (and back) when only the other one is
+	// routed; RedirectFixedPath also redirects paths with the wrong case or extra slashes.
+	// Both are off by default, so such requests get a 404 instead of a redirect.
+	RedirectTrailingSlash bool
+	RedirectFixedPath     bool
+	// StrictFields answers 400 for unknown names in ?fields= instead of ignoring them
+	StrictFields bool
+	// CollapseNameWhitespace stores names with runs of inner whitespace replaced by one space;
+	// names are always trimmed
+	CollapseNameWhitespace bool
+	// MaxConcurrentRequests caps the requests handled at once (0 disables it); requests over
+	// the cap wait up to ConcurrencyQueueTimeout for a slot before getting a 503
+	MaxConcurrentRequests   int
+	ConcurrencyQueueTimeout time.Duration
+	// MaintenanceMode answers 503 to every request except health checks and admin routes;
+	// it can be switched without a restart through POST /api/v1/admin/reload-config
+	MaintenanceMode bool
 }
 
 type DatabaseConfig struct {
@@ -26,23 +64,201 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// LogLevel is the gorm log level: silent, error, warn or info. When empty it is info in
+	// development and warn in production.
+	LogLevel string
+	// SlowThreshold is the duration above which queries are logged as slow; 0 disables it
+	SlowThreshold time.Duration
+	// ConnectRetries is how many times to try connecting at startup, waiting ConnectBackoff
+	// after the first failure and twice as long after each further one
+	ConnectRetries int
+	ConnectBackoff time.Duration
 }
 
 type JWTConfig struct {
 	Secret    string
 	ExpiresIn time.Duration
+	// RoleExpiresIn overrides ExpiresIn for the access tokens of specific roles
+	RoleExpiresIn map[string]time.Duration
+	// RefreshExpiresIn and RememberMeExpiresIn are the refresh token lifetimes for
+	// regular logins and for logins with remember_me
+	RefreshExpiresIn    time.Duration
+	RememberMeExpiresIn time.Duration
+	// EmailChangeExpiresIn is the lifetime of the links confirming a new email
+	EmailChangeExpiresIn time.Duration
+	// EmailVerifyExpiresIn is the lifetime of the links verifying a new user's email
+	EmailVerifyExpiresIn time.Duration
+	Leeway               time.Duration
+	// Keys maps key IDs to signing secrets for key rotation; ActiveKeyID selects the
+	// key used to sign new tokens
+	Keys        map[string]string
+	ActiveKeyID string
+	// CookieMode delivers tokens as HttpOnly cookies instead of in the JSON body
+	CookieMode bool
+	// Algorithm is HS256 (shared secret) or RS256. With RS256 tokens are signed with the
+	// PEM private key at PrivateKeyPath and verified with the PEM public key at
+	// PublicKeyPath, which defaults to the private key's public half
+	Algorithm      string
+	PrivateKeyPath string
+	PublicKeyPath  string
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string
+	// AllowCredentials requires AllowedOrigins to list explicit origins
+	AllowCredentials bool
+}
+
+type LogConfig struct {
+	// JSON writes access logs as one JSON object per line
+	JSON bool
+	// FilePath enables a rotating access log file in addition to stdout
+	FilePath   string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+}
+
+// RetentionConfig controls the job that permanently removes soft-deleted users
+type RetentionConfig struct {
+	PurgeDeletedUsers bool
+	// DeletedUserMaxAge is how long a soft-deleted user is kept before it is purged
+	DeletedUserMaxAge time.Duration
+	PurgeInterval     time.Duration
+	PurgeBatchSize    int
+}
+
+// MailConfig holds the SMTP server used for transactional email. Without a Host, emails are
+// logged instead of sent.
+type MailConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+	// ConfirmEmailURL is the link sent to confirm a new email; the token is added as a query parameter
+	ConfirmEmailURL string
+	// VerifyEmailURL is the link sent to new users to verify their email; empty sends none
+	VerifyEmailURL string
+	// VerifyResendCooldown is how long an email waits before its verification link can be resent
+	VerifyResendCooldown time.Duration
+}
+
+// SeedConfig holds the details of the admin user created by the admin seeder
+type SeedConfig struct {
+	AdminEmail    string
+	AdminPassword string
+}
+
+// LoginThrottleConfig limits login attempts per email and per client IP; a limit of 0 disables it
+type LoginThrottleConfig struct {
+	EmailLimit  int
+	EmailWindow time.Duration
+	IPLimit     int
+	IPWindow    time.Duration
+}
+
+// LoginLockoutConfig locks an account for Duration after Attempts consecutive failed logins;
+// 0 attempts disables it
+type LoginLockoutConfig struct {
+	Attempts int
+	Duration time.Duration
+}
+
+// PasswordConfig controls how passwords are hashed and how strong new passwords must be
+type PasswordConfig struct {
+	// BcryptCost is the bcrypt cost of new hashes; existing hashes are upgraded at the next login
+	BcryptCost int
+	// MinLength is the minimum length of new passwords
+	MinLength int
+	// Strict also requires upper and lower case letters and a digit, and rejects common
+	// passwords; relaxed mode only checks the length
+	Strict bool
+}
+
+// Policy returns the rules new passwords must follow; relaxed mode only checks the length
+func (c PasswordConfig) Policy() utils.PasswordPolicy {
+	if !c.Strict {
+		return utils.PasswordPolicy{MinLength: c.MinLength}
+	}
+	policy := utils.DefaultPasswordPolicy()
+	policy.MinLength = c.MinLength
+	return policy
+}
+
+// RateLimitConfig limits the requests of each client IP to RequestsPerSecond, allowing bursts
+// of Burst requests; 0 requests per second disables it
+type RateLimitConfig struct {
+	RequestsPerSecond int
+	Burst             int
+}
+
+// APIKeyConfig lists the static keys integrations may send in X-API-Key instead of a JWT to
+// call the admin user endpoints, acting with Role; no keys disables API key access
+type APIKeyConfig struct {
+	Keys []string
+	Role string
+}
+
+// TracingConfig enables OpenTelemetry tracing. The OTLP exporter itself is configured by
+// the standard OTEL_EXPORTER_OTLP_* environment variables.
+type TracingConfig struct {
+	// Enabled is set when an OTLP endpoint is configured
+	Enabled     bool
+	ServiceName string
+}
+
+// EmailValidationConfig holds the extra checks applied to registration emails
+type EmailValidationConfig struct {
+	// CheckMX rejects email domains without mail servers
+	CheckMX bool
+	// MXStrict also rejects emails whose MX lookup fails or times out
+	MXStrict  bool
+	MXTimeout time.Duration
+	// BlockedDomains rejects these email domains; "*.example.com" blocks its subdomains
+	BlockedDomains []string
+	// ReservedLocalParts and ReservedPatterns reject public registrations of reserved
+	// addresses, by local part (e.g. postmaster) or by a regex on the whole email
+	ReservedLocalParts []string
+	ReservedPatterns   []*regexp.Regexp
+	// RequireVerification refuses logins until the user opens the link verifying their email
+	RequireVerification bool
+}
+
+// defaultReservedLocalParts are reserved when RESERVED_EMAILS is not set
+var defaultReservedLocalParts = []string{
+	"abuse", "admin", "administrator", "hostmaster", "postmaster",
+	"root", "security", "webmaster", "noreply", "no-reply",
+}
+
+// CaptchaConfig enables CAPTCHA verification on registration; an empty Provider disables it
+type CaptchaConfig struct {
+	// Provider is recaptcha, hcaptcha or turnstile
+	Provider string
+	Secret   string
 }
 
 func Load() *Config {
 	// Load .env file if it exists
-	if err := godotenv.Load(); err != nil {
+	if err := loadDotenv(); err != nil {
 		log.Println("No .env file found, relying on environment variables")
 	}
 
 	config := &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Mode: getEnv("SERVER_MODE", "development"),
+			Port:             getEnv("PORT", "8080"),
+			Mode:             getEnv("SERVER_MODE", "development"),
+			ResponseEnvelope: getEnvBool("RESPONSE_ENVELOPE", true),
+			RequestTimeout:   getEnvDuration("REQUEST_TIMEOUT", 30*time.Second),
+
+			RedirectTrailingSlash: getEnvBool("REDIRECT_TRAILING_SLASH", false),
+			RedirectFixedPath:     getEnvBool("REDIRECT_FIXED_PATH", false),
+			StrictFields:          getEnvBool("STRICT_FIELDS", false),
+
+			CollapseNameWhitespace: getEnvBool("COLLAPSE_NAME_WHITESPACE", true),
+
+			MaxConcurrentRequests:   getEnvInt("MAX_CONCURRENT_REQUESTS", 0),
+			ConcurrencyQueueTimeout: getEnvDuration("CONCURRENCY_QUEUE_TIMEOUT", 0),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -51,31 +267,109 @@ func Load() *Config {
 			Password: getEnv("DB_PASSWORD", "your_password"),
 			DBName:   getEnv("DB_NAME", "your_db_name"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+			LogLevel:      getEnv("DB_LOG_LEVEL", ""),
+			SlowThreshold: getEnvDuration("DB_SLOW_THRESHOLD", 200*time.Millisecond),
+
+			ConnectRetries: getEnvInt("DB_CONNECT_RETRIES", 5),
+			ConnectBackoff: getEnvDuration("DB_CONNECT_BACKOFF", time.Second),
 		},
 		JWT: JWTConfig{
-			Secret:    getEnv("JWT_SECRET", "your_jwt_secret_key"),
-			ExpiresIn: 24 * time.Hour,
+			Secret:               getEnv("JWT_SECRET", "your_jwt_secret_key"),
+			ExpiresIn:            getEnvDuration("JWT_EXPIRES_IN", 24*time.Hour),
+			RoleExpiresIn:        parseDurationPairs(getEnv("JWT_ROLE_EXPIRES_IN", "")),
+			RefreshExpiresIn:     getEnvDuration("JWT_REFRESH_EXPIRES_IN", 7*24*time.Hour),
+			RememberMeExpiresIn:  getEnvDuration("JWT_REMEMBER_ME_EXPIRES_IN", 30*24*time.Hour),
+			EmailChangeExpiresIn: getEnvDuration("EMAIL_CHANGE_EXPIRES_IN", 24*time.Hour),
+			EmailVerifyExpiresIn: getEnvDuration("EMAIL_VERIFY_EXPIRES_IN", 24*time.Hour),
+			Leeway:               getEnvDuration("JWT_LEEWAY", 30*time.Second),
+			Keys:                 parseKeyPairs(getEnv("JWT_KEYS", "")),
+			ActiveKeyID:          getEnv("JWT_ACTIVE_KEY_ID", ""),
+			CookieMode:           getEnvBool("AUTH_COOKIE_MODE", false),
+			Algorithm:            strings.ToUpper(getEnv("JWT_ALG", "HS256")),
+			PrivateKeyPath:       getEnv("JWT_PRIVATE_KEY_PATH", ""),
+			PublicKeyPath:        getEnv("JWT_PUBLIC_KEY_PATH", ""),
 		},
-	}
+		CORS: CORSConfig{
+			AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+		},
+		Log: LogConfig{
+			JSON:       getEnvBool("LOG_JSON", false),
+			FilePath:   getEnv("LOG_FILE_PATH", ""),
+			MaxSizeMB:  getEnvInt("LOG_FILE_MAX_SIZE_MB", 100),
+			MaxBackups: getEnvInt("LOG_FILE_MAX_BACKUPS", 7),
+			MaxAgeDays: getEnvInt("LOG_FILE_MAX_AGE_DAYS", 30),
+		},
+		EmailValidation: EmailValidationConfig{
+			CheckMX:   getEnvBool("VALIDATE_EMAIL_MX", false),
+			MXStrict:  getEnvBool("VALIDATE_EMAIL_MX_STRICT", false),
+			MXTimeout: getEnvDuration("VALIDATE_EMAIL_MX_TIMEOUT", 2*time.Second),
 
-	// Validate critical configurations
-	if config.JWT.Secret == "your_jwt_secret_key" {
-		log.Println("Warning: Using default JWT secret key. Please set JWT_SECRET in environment variables for better security.")
-	}
+			RequireVerification: getEnvBool("REQUIRE_EMAIL_VERIFICATION", false),
+			BlockedDomains: append(
+				splitList(getEnv("BLOCKED_EMAIL_DOMAINS", "")),
+				readList(getEnv("BLOCKED_EMAIL_DOMAINS_FILE", ""))...,
+			),
+			ReservedLocalParts: getEnvList("RESERVED_EMAILS", defaultReservedLocalParts),
+			ReservedPatterns: compilePatterns("RESERVED_EMAIL_PATTERNS", append(
+				splitList(getEnv("RESERVED_EMAIL_PATTERNS", "")),
+				readList(getEnv("RESERVED_EMAIL_PATTERNS_FILE", ""))...,
+			)),
+		},
+		Captcha: CaptchaConfig{
+			Provider: strings.ToLower(getEnv("CAPTCHA_PROVIDER", "")),
+			Secret:   getEnv("CAPTCHA_SECRET", ""),
+		},
+		LoginLockout: LoginLockoutConfig{
+			Attempts: getEnvInt("LOGIN_LOCKOUT_ATTEMPTS", 5),
+			Duration: getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		},
+		Password: PasswordConfig{
+			BcryptCost: getEnvInt("BCRYPT_COST", 10),
+			MinLength:  getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			Strict:     getEnvBool("PASSWORD_STRICT", true),
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: getEnvInt("RATE_LIMIT_RPS", 0),
+			Burst:             getEnvInt("RATE_LIMIT_BURST", 20),
+		},
+		APIKeys: APIKeyConfig{
+			Keys: getEnvList("API_KEYS", nil),
+			Role: strings.ToLower(getEnv("API_KEY_ROLE", "admin")),
+		},
+		Retention: RetentionConfig{
+			PurgeDeletedUsers: getEnvBool("PURGE_DELETED_USERS", false),
+			DeletedUserMaxAge: time.Duration(getEnvInt("DELETED_USER_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			PurgeInterval:     getEnvDuration("PURGE_INTERVAL", 24*time.Hour),
+			PurgeBatchSize:    getEnvInt("PURGE_BATCH_SIZE", 500),
+		},
+		Seed: SeedConfig{
+			AdminEmail:    getEnv("SEED_ADMIN_EMAIL", "admin@company.com"),
+			AdminPassword: getEnv("SEED_ADMIN_PASSWORD", ""),
+		},
+		Mail: MailConfig{
+			Host:            getEnv("SMTP_HOST", ""),
+			Port:            getEnv("SMTP_PORT", "587"),
+			User:            getEnv("SMTP_USER", ""),
+			Password:        getEnv("SMTP_PASSWORD", ""),
+			From:            getEnv("MAIL_FROM", "no-reply@localhost"),
+			ConfirmEmailURL: getEnv("CONFIRM_EMAIL_URL", "http://localhost:8080/api/v1/auth/confirm-email"),
+			VerifyEmailURL:  getEnv("VERIFY_EMAIL_URL", "http://localhost:8080/api/v1/auth/verify-email"),
 
-	if config.Server.Mode != "development" && config.Server.Mode != "production" {
-		log.Fatalf("Invalid SERVER_MODE: %s. Must be 'development' or 'production'.", config.Server.Mode)
+			VerifyResendCooldown: getEnvDuration("VERIFY_EMAIL_RESEND_COOLDOWN", time.Minute),
+		},
+		Tracing: TracingConfig{
+			Enabled:     getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", getEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")) != "",
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "customable-corporate-site-api"),
+		},
 	}
 
-	if config.Database.Host == "your_db_host" || config.Database.User == "your_user" || config.Database.DBName == "your_db_name" {
-		log.Fatal("Database configuration is incomplete.")
-	}
+	loadReloadable(config)
 
-	// Log loaded configuration (excluding sensitive info)
-	log.Printf("Configuration loaded: Server Mode=%s", config.Server.Mode)
-	log.Printf("Server will start on port: %s", config.Server.Port)
-	log.Printf("Database Host: %s, Port: %s, User: %s, DBName: %s, SSLMode: %s",
-		config.Database.Host, config.Database.Port, config.Database.User, config.Database.DBName, config.Database.SSLMode)
+	// Validation happens in the preflight check, so every problem is reported at once
+
+	// Log the effective configuration on one line, without secrets
+	log.Printf("Configuration loaded: %s", config.Summary())
 
 	return config
 }
@@ -86,3 +380,127 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid boolean %q for %s, using default %t", value, key, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid integer %q for %s, using default %d", value, key, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid duration %q for %s, using default %s", value, key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// getEnvList parses a comma-separated list. Unlike getEnv, the default only applies when the
+// variable is unset, so setting it to an empty value clears the list.
+func getEnvList(key string, defaultValue []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	return splitList(value)
+}
+
+// compilePatterns compiles regular expressions read from key; an invalid one stops the server
+func compilePatterns(key string, patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			log.Fatalf("Invalid pattern %q in %s: %v", pattern, key, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// splitList parses a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readList reads one entry per line from the file at path, skipping blank lines and
+// # comments. An empty path yields no entries; an unreadable file stops the server.
+func readList(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", path, err)
+	}
+
+	var items []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
+// parseKeyPairs parses a comma-separated list of id:secret pairs
+func parseKeyPairs(value string) map[string]string {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		id, secret, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || id == "" || secret == "" {
+			continue
+		}
+		keys[id] = secret
+	}
+	return keys
+}
+
+// parseDurationPairs parses a comma-separated list of name:duration pairs, skipping invalid durations
+func parseDurationPairs(value string) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	for name, raw := range parseKeyPairs(value) {
+		duration, err := time.ParseDuration(raw)
+		if err != nil || duration <= 0 {
+			log.Printf("Warning: invalid duration %q for %s, ignoring", raw, name)
+			continue
+		}
+		durations[name] = duration
+	}
+	return durations
+}
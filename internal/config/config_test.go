@@ -0,0 +1,61 @@
+package config
+
+import (
+	"customable-corporate-site-api/internal/utils"
+	"testing"
+	"time"
+)
+
+func TestLoad_JWTExpiry(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantAccess  time.Duration
+		wantRefresh time.Duration
+	}{
+		{"Defaults", nil, 24 * time.Hour, 168 * time.Hour},
+		{"Configured", map[string]string{"JWT_EXPIRES_IN": "15m", "JWT_REFRESH_EXPIRES_IN": "72h"}, 15 * time.Minute, 72 * time.Hour},
+		{"Invalid values fall back", map[string]string{"JWT_EXPIRES_IN": "soon", "JWT_REFRESH_EXPIRES_IN": "7d"}, 24 * time.Hour, 168 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useDotenv(t, "")
+			t.Setenv("JWT_EXPIRES_IN", "")
+			t.Setenv("JWT_REFRESH_EXPIRES_IN", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			config := Load()
+			if config.JWT.ExpiresIn != tt.wantAccess {
+				t.Errorf("Load() JWT.ExpiresIn = %v, want %v", config.JWT.ExpiresIn, tt.wantAccess)
+			}
+			if config.JWT.RefreshExpiresIn != tt.wantRefresh {
+				t.Errorf("Load() JWT.RefreshExpiresIn = %v, want %v", config.JWT.RefreshExpiresIn, tt.wantRefresh)
+			}
+		})
+	}
+}
+
+func TestPasswordConfig_Policy(t *testing.T) {
+	strict := utils.DefaultPasswordPolicy()
+	strict.MinLength = 12
+
+	tests := []struct {
+		name   string
+		config PasswordConfig
+		want   utils.PasswordPolicy
+	}{
+		{"Relaxed", PasswordConfig{MinLength: 10}, utils.PasswordPolicy{MinLength: 10}},
+		{"Strict", PasswordConfig{MinLength: 12, Strict: true}, strict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Policy(); got != tt.want {
+				t.Errorf("Policy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Reload reads these settings again; every other setting, such as the database connection,
+// the JWT keys and the port, is read once at boot and only changes on restart:
+//
+//	MAINTENANCE_MODE
+//	CORS_ALLOWED_ORIGINS
+//	LOGIN_THROTTLE_EMAIL_LIMIT, LOGIN_THROTTLE_EMAIL_WINDOW
+//	LOGIN_THROTTLE_IP_LIMIT, LOGIN_THROTTLE_IP_WINDOW
+func loadReloadable(config *Config) {
+	config.Server.MaintenanceMode = getEnvBool("MAINTENANCE_MODE", false)
+	config.CORS.AllowedOrigins = splitList(getEnv("CORS_ALLOWED_ORIGINS", "*"))
+	config.LoginThrottle = LoginThrottleConfig{
+		EmailLimit:  getEnvInt("LOGIN_THROTTLE_EMAIL_LIMIT", 5),
+		EmailWindow: getEnvDuration("LOGIN_THROTTLE_EMAIL_WINDOW", 15*time.Minute),
+		IPLimit:     getEnvInt("LOGIN_THROTTLE_IP_LIMIT", 20),
+		IPWindow:    getEnvDuration("LOGIN_THROTTLE_IP_WINDOW", 15*time.Minute),
+	}
+}
+
+// Reload re-reads the .env file and returns a copy of config with the reloadable settings
+// replaced. As at boot, variables set in the process environment win over the file.
+func Reload(config *Config) (*Config, error) {
+	if err := loadDotenv(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	reloaded := *config
+	loadReloadable(&reloaded)
+	return &reloaded, nil
+}
+
+var (
+	dotenvMu sync.Mutex
+	// processEnv holds the variables set before the .env file was first read
+	processEnv map[string]bool
+	// dotenvKeys holds the variables last set from the .env file
+	dotenvKeys map[string]bool
+)
+
+// loadDotenv sets the variables of the .env file that the process environment does not set.
+// Unlike godotenv.Load it can run again: values changed in the file replace the ones it set
+// before, and variables removed from the file are unset.
+func loadDotenv() error {
+	dotenvMu.Lock()
+	defer dotenvMu.Unlock()
+
+	if processEnv == nil {
+		processEnv = make(map[string]bool)
+		for _, key := range envKeys() {
+			processEnv[key] = true
+		}
+	}
+
+	values, err := godotenv.Read()
+	if err != nil {
+		return err
+	}
+	for key := range dotenvKeys {
+		if _, ok := values[key]; !ok {
+			os.Unsetenv(key)
+		}
+	}
+	dotenvKeys = make(map[string]bool, len(values))
+	for key, value := range values {
+		if processEnv[key] {
+			continue
+		}
+		os.Setenv(key, value)
+		dotenvKeys[key] = true
+	}
+	return nil
+}
+
+// envKeys returns the names of the variables in the process environment
+func envKeys() []string {
+	var keys []string
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// LiveConfig holds the configuration of a Live
+type LiveConfig struct {
+	// Load returns the configuration replacing current (default Reload)
+	Load func(current *Config) (*Config, error)
+	// Validate rejects a reloaded configuration, keeping the current one in effect
+	Validate func(config *Config) error
+}
+
+// Live holds the configuration in effect while the server runs. Reload swaps in a new one
+// atomically, so middleware that reads Current on every request applies it from the next
+// request on.
+type Live struct {
+	current  atomic.Pointer[Config]
+	reloadMu sync.Mutex
+	load     func(current *Config) (*Config, error)
+	validate func(config *Config) error
+}
+
+// NewLive holds config until it is reloaded from the .env file and the environment
+func NewLive(config *Config) *Live {
+	return NewLiveWithConfig(config, LiveConfig{})
+}
+
+// NewLiveWithConfig holds config until it is reloaded with a custom loader or validation
+func NewLiveWithConfig(config *Config, cfg LiveConfig) *Live {
+	live := &Live{load: cfg.Load, validate: cfg.Validate}
+	if live.load == nil {
+		live.load = Reload
+	}
+	live.current.Store(config)
+	return live
+}
+
+// Current returns the configuration in effect
+func (l *Live) Current() *Config {
+	return l.current.Load()
+}
+
+// Reload loads and validates a new configuration and puts it in effect
+func (l *Live) Reload() (*Config, error) {
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+
+	config, err := l.load(l.Current())
+	if err != nil {
+		return nil, err
+	}
+	if l.validate != nil {
+		if err := l.validate(config); err != nil {
+			return nil, err
+		}
+	}
+	l.current.Store(config)
+	return config, nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// useDotenv runs the test in a directory whose .env file holds content, forgetting what
+// earlier loads set
+func useDotenv(t *testing.T, content string) {
+	t.Helper()
+	if processEnv == nil {
+		t.Chdir(t.TempDir())
+		t.Cleanup(func() {
+			for key := range dotenvKeys {
+				os.Unsetenv(key)
+			}
+			processEnv, dotenvKeys = nil, nil
+		})
+	}
+	if err := os.WriteFile(".env", []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	// The process environment wins over the file, as at boot
+	t.Setenv("LOGIN_THROTTLE_IP_LIMIT", "7")
+	useDotenv(t, "MAINTENANCE_MODE=true\nCORS_ALLOWED_ORIGINS=https://example.com\nLOGIN_THROTTLE_IP_LIMIT=99\nPORT=1234\n")
+
+	current := &Config{Server: ServerConfig{Port: "9090"}}
+	reloaded, err := Reload(current)
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !reloaded.Server.MaintenanceMode {
+		t.Error("Reload() MaintenanceMode = false, want true from the file")
+	}
+	if want := []string{"https://example.com"}; !reflect.DeepEqual(reloaded.CORS.AllowedOrigins, want) {
+		t.Errorf("Reload() AllowedOrigins = %v, want %v", reloaded.CORS.AllowedOrigins, want)
+	}
+	if reloaded.LoginThrottle.IPLimit != 7 {
+		t.Errorf("Reload() IPLimit = %d, want 7 from the process environment", reloaded.LoginThrottle.IPLimit)
+	}
+	// Settings that need a restart keep their boot values
+	if reloaded.Server.Port != "9090" {
+		t.Errorf("Reload() Port = %q, want %q", reloaded.Server.Port, "9090")
+	}
+	if current.Server.MaintenanceMode {
+		t.Error("Reload() changed the current configuration")
+	}
+
+	// Settings removed from the file go back to their defaults
+	useDotenv(t, "CORS_ALLOWED_ORIGINS=https://example.com\n")
+	reloaded, err = Reload(reloaded)
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if reloaded.Server.MaintenanceMode {
+		t.Error("Reload() MaintenanceMode = true, want false once removed from the file")
+	}
+}
+
+func TestLive_Reload(t *testing.T) {
+	boot := &Config{Server: ServerConfig{Port: "9090"}}
+	var validateErr error
+	live := NewLiveWithConfig(boot, LiveConfig{
+		Load: func(current *Config) (*Config, error) {
+			reloaded := *current
+			reloaded.Server.MaintenanceMode = !current.Server.MaintenanceMode
+			return &reloaded, nil
+		},
+		Validate: func(config *Config) error { return validateErr },
+	})
+
+	if live.Current() != boot {
+		t.Fatalf("Current() = %+v, want the boot configuration", live.Current())
+	}
+
+	reloaded, err := live.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if live.Current() != reloaded || !reloaded.Server.MaintenanceMode {
+		t.Errorf("Current() after Reload() = %+v, want the reloaded configuration", live.Current())
+	}
+
+	// A configuration failing validation is not put in effect
+	validateErr = errors.New("invalid origins")
+	if _, err := live.Reload(); !errors.Is(err, validateErr) {
+		t.Errorf("Reload() error = %v, want %v", err, validateErr)
+	}
+	if live.Current() != reloaded {
+		t.Errorf("Current() after a rejected Reload() = %+v, want the previous configuration", live.Current())
+	}
+}
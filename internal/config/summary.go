@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Summary describes the effective configuration on one line of key=value pairs, for the startup
+// log. Secrets, passwords and signing keys are left out entirely, as is whether they still have
+// their defaults; the preflight check reports that instead.
+func (c *Config) Summary() string {
+	var b strings.Builder
+	add := func(key string, value interface{}) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		text := fmt.Sprint(value)
+		if text == "" || strings.ContainsAny(text, " \t\"=") {
+			text = strconv.Quote(text)
+		}
+		b.WriteString(key + "=" + text)
+	}
+
+	add("mode", c.Server.Mode)
+	add("port", c.Server.Port)
+	add("maintenance", c.Server.MaintenanceMode)
+	add("db", c.Database.Host+":"+c.Database.Port+"/"+c.Database.DBName)
+	add("db_sslmode", c.Database.SSLMode)
+	add("jwt_alg", c.JWT.Algorithm)
+	add("jwt_keys", len(c.JWT.Keys))
+	add("jwt_expiry", c.JWT.ExpiresIn)
+	add("refresh_expiry", c.JWT.RefreshExpiresIn)
+	add("cookie_mode", c.JWT.CookieMode)
+	add("cors_origins", strings.Join(c.CORS.AllowedOrigins, ","))
+	add("login_throttle", c.LoginThrottle.EmailLimit > 0 || c.LoginThrottle.IPLimit > 0)
+	add("login_lockout", c.LoginLockout.Attempts)
+	add("bcrypt_cost", c.Password.BcryptCost)
+	add("password_strict", c.Password.Strict)
+	add("rate_limit_rps", c.RateLimit.RequestsPerSecond)
+	add("api_keys", len(c.APIKeys.Keys))
+	add("max_concurrent_requests", c.Server.MaxConcurrentRequests)
+	add("request_timeout", c.Server.RequestTimeout)
+	add("captcha", enabledOr(c.Captcha.Provider, "off"))
+	add("mail", enabledOr(c.Mail.Host, "log"))
+	add("tracing", c.Tracing.Enabled)
+	add("purge_deleted_users", c.Retention.PurgeDeletedUsers)
+	add("log_json", c.Log.JSON)
+	return b.String()
+}
+
+// enabledOr returns value, or fallback when value is empty
+func enabledOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
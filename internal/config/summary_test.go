@@ -0,0 +1,73 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Summary(t *testing.T) {
+	cfg := &Config{
+		Server:   ServerConfig{Port: "9090", Mode: "production", RequestTimeout: 30 * time.Second},
+		Database: DatabaseConfig{Host: "db.internal", Port: "5432", User: "app-db-user", Password: "db-password-secret", DBName: "site", SSLMode: "require"},
+		JWT: JWTConfig{
+			Secret:           "jwt-secret-value",
+			ExpiresIn:        24 * time.Hour,
+			RefreshExpiresIn: 7 * 24 * time.Hour,
+			Keys:             map[string]string{"2024": "rotated-key-secret"},
+			ActiveKeyID:      "2024",
+			Algorithm:        "HS256",
+		},
+		CORS:          CORSConfig{AllowedOrigins: []string{"https://example.com", "https://admin.example.com"}},
+		Captcha:       CaptchaConfig{Provider: "turnstile", Secret: "captcha-secret-value"},
+		Mail:          MailConfig{Host: "smtp.example.com", User: "mailer-user", Password: "smtp-password-secret"},
+		LoginThrottle: LoginThrottleConfig{EmailLimit: 5},
+		Seed:          SeedConfig{AdminPassword: "seed-password-secret"},
+	}
+
+	summary := cfg.Summary()
+	if strings.Contains(summary, "\n") {
+		t.Errorf("Summary() = %q, want a single line", summary)
+	}
+
+	for _, want := range []string{
+		"mode=production",
+		"port=9090",
+		"db=db.internal:5432/site",
+		"jwt_expiry=24h0m0s",
+		"jwt_keys=1",
+		"cors_origins=https://example.com,https://admin.example.com",
+		"captcha=turnstile",
+		"mail=smtp.example.com",
+		"login_throttle=true",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() = %q, want it to contain %q", summary, want)
+		}
+	}
+
+	for _, secret := range []string{
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.JWT.Secret,
+		cfg.JWT.Keys["2024"],
+		cfg.Captcha.Secret,
+		cfg.Mail.User,
+		cfg.Mail.Password,
+		cfg.Seed.AdminPassword,
+	} {
+		if strings.Contains(summary, secret) {
+			t.Errorf("Summary() = %q, want it to leave out %q", summary, secret)
+		}
+	}
+}
+
+func TestConfig_Summary_Defaults(t *testing.T) {
+	summary := (&Config{}).Summary()
+
+	for _, want := range []string{`mode=""`, "captcha=off", "mail=log", "login_throttle=false"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() = %q, want it to contain %q", summary, want)
+		}
+	}
+}
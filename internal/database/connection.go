@@ -1,9 +1,11 @@
 package database
 
 import (
+	"context"
 	"customable-corporate-site-api/internal/config"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -11,53 +13,96 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// maxConnectBackoff caps the wait between connection attempts
+const maxConnectBackoff = 30 * time.Second
+
+// connectPingTimeout bounds each connection attempt
+const connectPingTimeout = 5 * time.Second
+
+// sleep waits between connection attempts; tests replace it to run without delays
+var sleep = time.Sleep
+
+// ConnectDB opens the connection pool and waits until the database answers, trying up to
+// cfg.Database.ConnectRetries times with exponential backoff so the server can start
+// alongside a database that is still coming up. It returns the last error once every
+// attempt has failed.
 func ConnectDB(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+	// Timestamps are stored and read back in UTC so API responses do not depend on the server's timezone
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.DBName, cfg.Database.SSLMode)
 
 	// GORM configuration
+	// Connectivity is checked by connect below, with retries, rather than when opening the pool
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		DisableAutomaticPing: true,
+		Logger:               NewLogger(log.New(os.Stdout, "\r\n", log.LstdFlags), logLevel(cfg), cfg.Database.SlowThreshold),
 		NowFunc: func() time.Time {
-			return time.Now().Local()
+			return time.Now().UTC()
 		},
 	}
 
-	// Set logger level based on environment
-	if cfg.Server.Mode == "release" {
-		gormConfig.Logger = logger.Default.LogMode(logger.Error)
+	attempts := cfg.Database.ConnectRetries
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := cfg.Database.ConnectBackoff
+
+	for attempt := 1; ; attempt++ {
+		log.Printf("Connecting to database (attempt %d of %d)...", attempt, attempts)
+		db, err := connect(dsn, gormConfig)
+		if err == nil {
+			return db, nil
+		}
+		if attempt == attempts {
+			return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", attempts, err)
+		}
+
+		log.Printf("Failed to connect to database: %v; retrying in %v", err, backoff)
+		sleep(backoff)
+		backoff = min(2*backoff, maxConnectBackoff)
 	}
+}
 
-	log.Println("Connecting to database...")
+// connect opens the connection pool and pings the database, closing the pool on failure
+func connect(dsn string, gormConfig *gorm.Config) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
 	// Get underlying sql.DB to set connection pool parameters
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("Failed to get database instance: %v", err)
 		return nil, fmt.Errorf("failed to get database instance: %w", err)
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), connectPingTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
 	// Configure connection pool
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	// Test the database connection
-	if err := sqlDB.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	log.Println("Database connection established")
-
 	return db, nil
 }
 
+// logLevel returns the configured gorm log level, defaulting by server mode. An invalid
+// level is reported by the preflight check; it falls back to the default here.
+func logLevel(cfg *config.Config) logger.LogLevel {
+	if level, err := ParseLogLevel(cfg.Database.LogLevel); err == nil {
+		return level
+	}
+	if cfg.Server.Mode == "production" {
+		return logger.Warn
+	}
+	return logger.Info
+}
+
 func AutoMigrate(db *gorm.DB, models ...interface{}) error {
 	log.Println("Starting database migration...")
 
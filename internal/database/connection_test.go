@@ -0,0 +1,51 @@
+package database
+
+import (
+	"customable-corporate-site-api/internal/config"
+	"testing"
+	"time"
+)
+
+func TestConnectDB_Retries(t *testing.T) {
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	// Nothing listens on port 1, so every attempt is refused at once
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Host:           "127.0.0.1",
+		Port:           "1",
+		User:           "test",
+		DBName:         "test",
+		SSLMode:        "disable",
+		LogLevel:       "silent",
+		ConnectRetries: 5,
+		ConnectBackoff: 10 * time.Second,
+	}}
+
+	db, err := ConnectDB(cfg)
+	if err == nil {
+		t.Fatalf("ConnectDB() = %v, want an error", db)
+	}
+
+	want := []time.Duration{10 * time.Second, 20 * time.Second, maxConnectBackoff, maxConnectBackoff}
+	if len(waits) != len(want) {
+		t.Fatalf("ConnectDB() waited %v, want %v: 5 attempts", waits, want)
+	}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("wait %d = %v, want %v", i+1, waits[i], want[i])
+		}
+	}
+}
+
+func TestConnectDB_NoRetries(t *testing.T) {
+	var waits int
+	sleep = func(time.Duration) { waits++ }
+	defer func() { sleep = time.Sleep }()
+
+	cfg := &config.Config{Database: config.DatabaseConfig{Host: "127.0.0.1", Port: "1", SSLMode: "disable", LogLevel: "silent"}}
+	if _, err := ConnectDB(cfg); err == nil || waits != 0 {
+		t.Errorf("ConnectDB() error = %v after %d waits, want an error after a single attempt", err, waits)
+	}
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/tracing"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// ParseLogLevel maps a DB_LOG_LEVEL value to a gorm log level
+func ParseLogLevel(level string) (logger.LogLevel, error) {
+	switch level {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "warn":
+		return logger.Warn, nil
+	case "info":
+		return logger.Info, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q: must be 'silent', 'error', 'warn' or 'info'", level)
+	}
+}
+
+// queryLogger is gorm's default logger with each line tagged with the request ID from the
+// statement's context, so queries run with db.WithContext(ctx) can be matched to the
+// request that made them.
+type queryLogger struct {
+	writer logger.Writer
+	config logger.Config
+}
+
+// NewLogger returns a gorm logger that writes to writer. At warn level and above, queries
+// taking longer than slowThreshold are logged as slow; a threshold of 0 disables this.
+func NewLogger(writer logger.Writer, level logger.LogLevel, slowThreshold time.Duration) logger.Interface {
+	return &queryLogger{
+		writer: writer,
+		config: logger.Config{
+			SlowThreshold:             slowThreshold,
+			LogLevel:                  level,
+			IgnoreRecordNotFoundError: true,
+		},
+	}
+}
+
+// LogMode returns a copy of the logger at level
+func (l *queryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.config.LogLevel = level
+	return &copied
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.config.LogLevel >= logger.Info {
+		l.printf(ctx, "[info] "+msg, args...)
+	}
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.config.LogLevel >= logger.Warn {
+		l.printf(ctx, "[warn] "+msg, args...)
+	}
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.config.LogLevel >= logger.Error {
+		l.printf(ctx, "[error] "+msg, args...)
+	}
+}
+
+// Trace logs a statement after it ran: failed statements at error level, slow ones at warn
+// level and every statement at info level
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.config.LogLevel <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.config.LogLevel >= logger.Error && !(l.config.IgnoreRecordNotFoundError && errors.Is(err, gorm.ErrRecordNotFound)):
+		sql, rows := fc()
+		l.printf(ctx, "%s [error] %v\n[%.3fms] [rows:%s] %s", utils.FileWithLineNum(), err, milliseconds(elapsed), formatRows(rows), sql)
+	case l.config.SlowThreshold > 0 && elapsed > l.config.SlowThreshold && l.config.LogLevel >= logger.Warn:
+		sql, rows := fc()
+		l.printf(ctx, "%s [warn] SLOW SQL >= %v\n[%.3fms] [rows:%s] %s", utils.FileWithLineNum(), l.config.SlowThreshold, milliseconds(elapsed), formatRows(rows), sql)
+	case l.config.LogLevel >= logger.Info:
+		sql, rows := fc()
+		l.printf(ctx, "%s\n[%.3fms] [rows:%s] %s", utils.FileWithLineNum(), milliseconds(elapsed), formatRows(rows), sql)
+	}
+}
+
+// printf writes a log line, prefixed with the request ID when ctx carries one
+func (l *queryLogger) printf(ctx context.Context, format string, args ...interface{}) {
+	if requestID := tracing.RequestIDFromContext(ctx); requestID != "" {
+		format = "[request_id=%s] " + format
+		args = append([]interface{}{requestID}, args...)
+	}
+	l.writer.Printf(format, args...)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1e6
+}
+
+// formatRows formats an affected row count; gorm reports -1 when it is unknown
+func formatRows(rows int64) string {
+	if rows == -1 {
+		return "-"
+	}
+	return fmt.Sprint(rows)
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/tracing"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// captureWriter collects the lines written by the gorm logger
+type captureWriter struct {
+	lines []string
+}
+
+func (w *captureWriter) Printf(format string, args ...interface{}) {
+	w.lines = append(w.lines, fmt.Sprintf(format, args...))
+}
+
+// slowQuery counts to a hundred thousand in a recursive CTE, which takes SQLite well over a millisecond
+const slowQuery = `WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 100000) SELECT count(*) FROM n`
+
+func setupLoggerDB(t *testing.T, level logger.LogLevel, slowThreshold time.Duration) (*gorm.DB, *captureWriter) {
+	writer := &captureWriter{}
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: NewLogger(writer, level, slowThreshold)})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	return db, writer
+}
+
+func TestNewLogger_SlowQuery(t *testing.T) {
+	db, writer := setupLoggerDB(t, logger.Warn, time.Millisecond)
+
+	ctx := tracing.WithRequestID(context.Background(), "req-123")
+	var count int64
+	if err := db.WithContext(ctx).Raw(slowQuery).Scan(&count).Error; err != nil {
+		t.Fatalf("slow query error = %v", err)
+	}
+
+	if len(writer.lines) != 1 {
+		t.Fatalf("logged %d lines, want the slow query: %q", len(writer.lines), writer.lines)
+	}
+	line := writer.lines[0]
+	for _, want := range []string{"[request_id=req-123]", "[warn] SLOW SQL >= 1ms", "WITH RECURSIVE n(i)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("slow query log = %q, want it to contain %q", line, want)
+		}
+	}
+}
+
+func TestNewLogger_Levels(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         logger.LogLevel
+		slowThreshold time.Duration
+		query         string
+		wantLines     int
+	}{
+		{"Fast query at warn", logger.Warn, time.Hour, "SELECT 1", 0},
+		{"Fast query at info", logger.Info, time.Hour, "SELECT 1", 1},
+		{"Slow query with threshold disabled", logger.Warn, 0, slowQuery, 0},
+		{"Slow query at error", logger.Error, time.Millisecond, slowQuery, 0},
+		{"Failed query at error", logger.Error, time.Hour, "SELECT * FROM missing", 1},
+		{"Failed query when silent", logger.Silent, time.Hour, "SELECT * FROM missing", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, writer := setupLoggerDB(t, tt.level, tt.slowThreshold)
+
+			var result []map[string]interface{}
+			db.Raw(tt.query).Scan(&result)
+
+			if len(writer.lines) != tt.wantLines {
+				t.Errorf("logged %q, want %d lines", writer.lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		level   string
+		want    logger.LogLevel
+		wantErr bool
+	}{
+		{"silent", logger.Silent, false},
+		{"error", logger.Error, false},
+		{"warn", logger.Warn, false},
+		{"info", logger.Info, false},
+		{"debug", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			got, err := ParseLogLevel(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLogLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLogLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
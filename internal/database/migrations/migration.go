@@ -1,8 +1,10 @@
 package migrations
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"customable-corporate-site-api/internal/database/migrations/versions"
@@ -26,6 +28,9 @@ func (Migration) TableName() string {
 // MigrationStep is imported from versions package
 type MigrationStep = versions.MigrationStep
 
+// ErrUnknownVersion is returned by MigrateTo for a version that is not registered
+var ErrUnknownVersion = errors.New("unknown migration version")
+
 // Migratior handles database migrations.
 type Migrator struct {
 	db         *gorm.DB
@@ -78,31 +83,9 @@ func (m *Migrator) Up() error {
 			continue
 		}
 
-		log.Printf("Running migration: %s - %s", migration.Version, migration.Description)
-
-		// Run migration in a transaction
-		err := m.db.Transaction(func(tx *gorm.DB) error {
-			if err := migration.Up(tx); err != nil {
-				return fmt.Errorf("failed to execute migration %s: %w", migration.Version, err)
-			}
-
-			// Record the executed migration
-			newMigration := Migration{
-				Version:     migration.Version,
-				Description: migration.Description,
-				ExecutedAt:  time.Now(),
-			}
-			if err := tx.Create(&newMigration).Error; err != nil {
-				return fmt.Errorf("failed to record migration %s: %w", migration.Version, err)
-			}
-			return nil
-		})
-
-		if err != nil {
-			return fmt.Errorf("failed to run migration %s: %w", migration.Version, err)
+		if err := m.applyStep(migration); err != nil {
+			return err
 		}
-
-		log.Printf("Successfully ran migration: %s", migration.Version)
 		pendingCount++
 	}
 
@@ -115,13 +98,126 @@ func (m *Migrator) Up() error {
 	return nil
 }
 
+// MigrateTo brings the database to targetVersion: it applies the pending migrations up to and
+// including the target, or rolls back the executed migrations registered after it, newest
+// first. Each step runs in its own transaction. The target may be given as the full version
+// or its number, e.g. "005".
+func (m *Migrator) MigrateTo(targetVersion string) error {
+	target := -1
+	for i, migration := range m.migrations {
+		if migration.Version == targetVersion || versionNumber(migration.Version) == targetVersion {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownVersion, targetVersion)
+	}
+
+	if err := m.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize migrations table: %w", err)
+	}
+
+	var executedMigrations []Migration
+	if err := m.db.Find(&executedMigrations).Error; err != nil {
+		return fmt.Errorf("failed to fetch executed migrations: %w", err)
+	}
+	executed := make(map[string]Migration, len(executedMigrations))
+	for _, migration := range executedMigrations {
+		executed[migration.Version] = migration
+	}
+
+	log.Printf("Migrating to %s...", m.migrations[target].Version)
+
+	// Roll back everything after the target, newest first
+	for i := len(m.migrations) - 1; i > target; i-- {
+		record, ok := executed[m.migrations[i].Version]
+		if !ok {
+			continue
+		}
+		if err := m.rollbackStep(m.migrations[i], record); err != nil {
+			return err
+		}
+	}
+
+	// Then apply whatever is pending up to the target
+	for _, migration := range m.migrations[:target+1] {
+		if _, ok := executed[migration.Version]; ok {
+			continue
+		}
+		if err := m.applyStep(migration); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Database is at %s.", m.migrations[target].Version)
+	return nil
+}
+
+// applyStep runs a migration and records it in a single transaction
+func (m *Migrator) applyStep(migration MigrationStep) error {
+	log.Printf("Running migration: %s - %s", migration.Version, migration.Description)
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := migration.Up(tx); err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", migration.Version, err)
+		}
+
+		// Record the executed migration
+		newMigration := Migration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			ExecutedAt:  time.Now(),
+		}
+		if err := tx.Create(&newMigration).Error; err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", migration.Version, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run migration %s: %w", migration.Version, err)
+	}
+
+	log.Printf("Successfully ran migration: %s", migration.Version)
+	return nil
+}
+
+// rollbackStep rolls back a migration and removes its record in a single transaction
+func (m *Migrator) rollbackStep(migration MigrationStep, record Migration) error {
+	log.Printf("Rolling back migration: %s - %s", migration.Version, migration.Description)
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := migration.Down(tx); err != nil {
+			return fmt.Errorf("failed to execute rollback for migration %s: %w", migration.Version, err)
+		}
+
+		if err := tx.Delete(&record).Error; err != nil {
+			return fmt.Errorf("failed to remove migration record %s: %w", record.Version, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration %s: %w", migration.Version, err)
+	}
+
+	log.Printf("Successfully rolled back migration: %s", migration.Version)
+	return nil
+}
+
+// versionNumber returns the number a version starts with, e.g. "005" for "005_create_audit_logs_table"
+func versionNumber(version string) string {
+	number, _, _ := strings.Cut(version, "_")
+	return number
+}
+
 // Down rolls back the last executed migration.
 func (m *Migrator) Down() error {
 	log.Println("Rolling back the last migration...")
 
-	// Get the last executed migration
+	// Get the last executed migration; migrations recorded within the same clock tick are
+	// told apart by their ID
 	var lastMigration Migration
-	if err := m.db.Order("executed_at desc").First(&lastMigration).Error; err != nil {
+	if err := m.db.Order("executed_at desc, id desc").First(&lastMigration).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			log.Println("No migrations to roll back.")
 			return nil
@@ -129,11 +225,12 @@ func (m *Migrator) Down() error {
 		return fmt.Errorf("failed to fetch last migration: %w", err)
 	}
 
-	// Find migration step
+	// Find migration step. Index into m.migrations rather than taking the address of the
+	// range variable, which is a copy.
 	var migrationStep *MigrationStep
-	for _, migration := range m.migrations {
-		if migration.Version == lastMigration.Version {
-			migrationStep = &migration
+	for i := range m.migrations {
+		if m.migrations[i].Version == lastMigration.Version {
+			migrationStep = &m.migrations[i]
 			break
 		}
 	}
@@ -142,29 +239,7 @@ func (m *Migrator) Down() error {
 		return fmt.Errorf("migration step not found for version: %s", lastMigration.Version)
 	}
 
-	log.Printf("Rolling back migration: %s - %s", migrationStep.Version, migrationStep.Description)
-
-	// Run rollback in a transaction
-	err := m.db.Transaction(func(tx *gorm.DB) error {
-		// Execute rollback
-		if err := migrationStep.Down(tx); err != nil {
-			return fmt.Errorf("failed to execute rollback for migration %s: %w", migrationStep.Version, err)
-		}
-
-		// Remove the migration record
-		if err := tx.Delete(&lastMigration).Error; err != nil {
-			return fmt.Errorf("failed to remove migration record %s: %w", lastMigration.Version, err)
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to roll back migration %s: %w", migrationStep.Version, err)
-	}
-
-	log.Printf("Successfully rolled back migration: %s", migrationStep.Version)
-	return nil
+	return m.rollbackStep(*migrationStep, lastMigration)
 }
 
 // Status shows migration status.
@@ -205,19 +280,31 @@ func (m *Migrator) Status() error {
 	return nil
 }
 
-// Reset drops all tables and re-runs all migrations.
+// Reset drops the tables created by the registered migrations, along with the migration
+// history, and re-runs all migrations. Tables are dropped newest migration first, so tables
+// referencing others go before them, in one transaction where the database supports it.
 func (m *Migrator) Reset() error {
 	log.Println("Warning: This will drop all tables and re-run all migrations. Proceeding...")
 	log.Println("Resetting tables...")
 
-	// Drop migrations table first to avoid foreign key issues
-	if err := m.db.Migrator().DropTable(&Migration{}); err != nil {
-		return fmt.Errorf("failed to drop migrations table: %w", err)
-	}
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			tables := m.migrations[i].Tables
+			for j := len(tables) - 1; j >= 0; j-- {
+				if err := tx.Migrator().DropTable(tables[j]); err != nil {
+					return fmt.Errorf("failed to drop tables of migration %s: %w", m.migrations[i].Version, err)
+				}
+			}
+		}
 
-	// Drop all registered tables
-	// Note: In a real application, you would drop all tables here.
-	// For simplicity, we are only dropping the migrations table.
+		if err := tx.Migrator().DropTable(&Migration{}); err != nil {
+			return fmt.Errorf("failed to drop migrations table: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
 	// Re-run migrations
 	if err := m.Up(); err != nil {
@@ -0,0 +1,189 @@
+package migrations
+
+import (
+	"customable-corporate-site-api/internal/models"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeTable is created by the fake migrations, one table per version
+type fakeTable struct {
+	ID uint
+}
+
+// setupMigrator registers a fake migration per version, each creating and dropping its own table
+func setupMigrator(t *testing.T, versions ...string) (*Migrator, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	migrator := NewMigrator(db)
+	for _, version := range versions {
+		table := "fake_" + version
+		migrator.Register(MigrationStep{
+			Version:     version,
+			Description: "Create " + table,
+			Up: func(tx *gorm.DB) error {
+				return tx.Table(table).Migrator().CreateTable(&fakeTable{})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(table)
+			},
+		})
+	}
+	return migrator, db
+}
+
+// executedVersions returns the recorded migrations, in version order
+func executedVersions(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var versions []string
+	if err := db.Model(&Migration{}).Pluck("version", &versions).Error; err != nil {
+		t.Fatalf("Failed to read executed migrations: %v", err)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+func TestMigrator_MigrateTo(t *testing.T) {
+	versions := []string{"001_first", "002_second", "003_third"}
+
+	tests := []struct {
+		name  string
+		setup func(m *Migrator) error
+	}{
+		{"Forward from an empty database", func(m *Migrator) error { return nil }},
+		{"Backward from the latest version", func(m *Migrator) error { return m.Up() }},
+		{"Already at the target", func(m *Migrator) error { return m.MigrateTo("002_second") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrator, db := setupMigrator(t, versions...)
+			if err := tt.setup(migrator); err != nil {
+				t.Fatalf("setup error = %v", err)
+			}
+
+			if err := migrator.MigrateTo("002_second"); err != nil {
+				t.Fatalf("MigrateTo() error = %v", err)
+			}
+
+			if got := strings.Join(executedVersions(t, db), ","); got != "001_first,002_second" {
+				t.Errorf("executed = %s, want 001_first,002_second", got)
+			}
+			for version, want := range map[string]bool{"001_first": true, "002_second": true, "003_third": false} {
+				if got := db.Migrator().HasTable("fake_" + version); got != want {
+					t.Errorf("table of %s exists = %v, want %v", version, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrator_MigrateTo_VersionNumber(t *testing.T) {
+	migrator, db := setupMigrator(t, "001_first", "002_second", "003_third")
+
+	if err := migrator.MigrateTo("001"); err != nil {
+		t.Fatalf("MigrateTo() error = %v", err)
+	}
+	if got := strings.Join(executedVersions(t, db), ","); got != "001_first" {
+		t.Errorf("executed = %s, want 001_first", got)
+	}
+}
+
+func TestMigrator_MigrateTo_UnknownVersion(t *testing.T) {
+	migrator, db := setupMigrator(t, "001_first", "002_second")
+
+	if err := migrator.MigrateTo("004_missing"); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("MigrateTo() error = %v, want %v", err, ErrUnknownVersion)
+	}
+	if db.Migrator().HasTable(&Migration{}) {
+		t.Error("MigrateTo() with an unknown version touched the database")
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Each step records its version when rolled back
+	var rolledBack []string
+	migrator := NewMigrator(db)
+	for _, version := range []string{"001_first", "002_second", "003_third"} {
+		migrator.Register(MigrationStep{
+			Version:     version,
+			Description: "Fake migration " + version,
+			Up:          func(tx *gorm.DB) error { return nil },
+			Down: func(tx *gorm.DB) error {
+				rolledBack = append(rolledBack, version)
+				return nil
+			},
+		})
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	for _, want := range []string{"003_third", "002_second"} {
+		rolledBack = nil
+		if err := migrator.Down(); err != nil {
+			t.Fatalf("Down() error = %v", err)
+		}
+		if len(rolledBack) != 1 || rolledBack[0] != want {
+			t.Errorf("Down() ran the rollback of %v, want only %s", rolledBack, want)
+		}
+	}
+
+	if got := strings.Join(executedVersions(t, db), ","); got != "001_first" {
+		t.Errorf("executed = %s, want 001_first", got)
+	}
+}
+
+func TestMigrator_Reset(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	migrator := RegisterMigrations(db)
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	for _, email := range []string{"first@example.com", "second@example.com"} {
+		if err := db.Create(&models.User{Email: email, Password: "password123", FirstName: "John", LastName: "Doe"}).Error; err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+	if err := db.Create(&models.AuditLog{ActorID: 1, Action: models.AuditActionUserUpdated}).Error; err != nil {
+		t.Fatalf("Failed to create audit log: %v", err)
+	}
+
+	if err := migrator.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	// The admin is created by the seeders, not by migration 003, so no user remains
+	for _, model := range []interface{}{&models.User{}, &models.AuditLog{}} {
+		var count int64
+		if err := db.Unscoped().Model(model).Count(&count).Error; err != nil {
+			t.Fatalf("Failed to count %T after Reset(): %v", model, err)
+		}
+		if count != 0 {
+			t.Errorf("%T rows after Reset() = %d, want 0", model, count)
+		}
+	}
+
+	var applied int64
+	db.Model(&Migration{}).Count(&applied)
+	if int(applied) != len(migrator.migrations) {
+		t.Errorf("migrations after Reset() = %d, want all %d re-applied", applied, len(migrator.migrations))
+	}
+}
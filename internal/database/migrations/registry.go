@@ -14,6 +14,14 @@ func RegisterMigrations(db *gorm.DB) *Migrator {
 	migrator.Register(versions.Migration001CreateUsersTable())
 	migrator.Register(versions.Migration002AddUserIndexes())
 	migrator.Register(versions.Migration003SeedAdminUser())
+	migrator.Register(versions.Migration004CreateSessionsTable())
+	migrator.Register(versions.Migration005CreateAuditLogsTable())
+	migrator.Register(versions.Migration006AddSessionRememberMe())
+	migrator.Register(versions.Migration007AddUserPendingEmail())
+	migrator.Register(versions.Migration008AddUserTokenVersion())
+	migrator.Register(versions.Migration009CreateRevokedTokensTable())
+	migrator.Register(versions.Migration010AddUserLoginLockout())
+	migrator.Register(versions.Migration011AddUserEmailVerified())
 
 	return migrator
 }
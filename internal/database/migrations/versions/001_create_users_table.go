@@ -12,6 +12,8 @@ type MigrationStep struct {
 	Description string
 	Up          func(tx *gorm.DB) error
 	Down        func(tx *gorm.DB) error
+	// Tables lists the models whose tables the step creates, so a reset can drop them
+	Tables []interface{}
 }
 
 // Migration version: 001_create_users_table
@@ -25,5 +27,6 @@ func Migration001CreateUsersTable() MigrationStep {
 		Down: func(tx *gorm.DB) error {
 			return tx.Migrator().DropTable(&models.User{})
 		},
+		Tables: []interface{}{&models.User{}},
 	}
 }
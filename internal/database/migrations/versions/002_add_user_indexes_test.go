@@ -0,0 +1,43 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMigration002AddUserIndexes(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	migration := Migration002AddUserIndexes()
+	if err := migration.Up(db); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	for _, index := range userIndexes {
+		if !db.Migrator().HasIndex("users", index.name) {
+			t.Errorf("index %s missing after Up()", index.name)
+		}
+	}
+
+	if err := migration.Down(db); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	for _, index := range userIndexes {
+		if db.Migrator().HasIndex("users", index.name) {
+			t.Errorf("index %s still exists after Down()", index.name)
+		}
+	}
+
+	// Running Up again restores them
+	if err := migration.Up(db); err != nil {
+		t.Fatalf("Up() after Down() error = %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Migration version: 004_create_sessions_table
+func Migration004CreateSessionsTable() MigrationStep {
+	return MigrationStep{
+		Version:     "004_create_sessions_table",
+		Description: "Create sessions table",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Session{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.Session{})
+		},
+		Tables: []interface{}{&models.Session{}},
+	}
+}
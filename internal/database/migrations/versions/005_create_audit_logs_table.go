@@ -0,0 +1,22 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Migration version: 005_create_audit_logs_table
+func Migration005CreateAuditLogsTable() MigrationStep {
+	return MigrationStep{
+		Version:     "005_create_audit_logs_table",
+		Description: "Create audit logs table",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.AuditLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.AuditLog{})
+		},
+		Tables: []interface{}{&models.AuditLog{}},
+	}
+}
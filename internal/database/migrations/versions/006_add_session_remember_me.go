@@ -0,0 +1,25 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Migration version: 006_add_session_remember_me
+func Migration006AddSessionRememberMe() MigrationStep {
+	return MigrationStep{
+		Version:     "006_add_session_remember_me",
+		Description: "Add remember_me column to sessions table",
+		Up: func(tx *gorm.DB) error {
+			// 004 migrates the current model, so fresh databases already have the column
+			if tx.Migrator().HasColumn(&models.Session{}, "RememberMe") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&models.Session{}, "RememberMe")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&models.Session{}, "RememberMe")
+		},
+	}
+}
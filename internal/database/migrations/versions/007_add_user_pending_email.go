@@ -0,0 +1,25 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Migration version: 007_add_user_pending_email
+func Migration007AddUserPendingEmail() MigrationStep {
+	return MigrationStep{
+		Version:     "007_add_user_pending_email",
+		Description: "Add pending_email column to users table",
+		Up: func(tx *gorm.DB) error {
+			// 001 migrates the current model, so fresh databases already have the column
+			if tx.Migrator().HasColumn(&models.User{}, "PendingEmail") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&models.User{}, "PendingEmail")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&models.User{}, "PendingEmail")
+		},
+	}
+}
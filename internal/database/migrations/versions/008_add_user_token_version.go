@@ -0,0 +1,25 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Migration version: 008_add_user_token_version
+func Migration008AddUserTokenVersion() MigrationStep {
+	return MigrationStep{
+		Version:     "008_add_user_token_version",
+		Description: "Add token_version column to users table",
+		Up: func(tx *gorm.DB) error {
+			// 001 migrates the current model, so fresh databases already have the column
+			if tx.Migrator().HasColumn(&models.User{}, "TokenVersion") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&models.User{}, "TokenVersion")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&models.User{}, "TokenVersion")
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Migration version: 009_create_revoked_tokens_table
+func Migration009CreateRevokedTokensTable() MigrationStep {
+	return MigrationStep{
+		Version:     "009_create_revoked_tokens_table",
+		Description: "Create revoked tokens table",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.RevokedToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.RevokedToken{})
+		},
+		Tables: []interface{}{&models.RevokedToken{}},
+	}
+}
@@ -0,0 +1,35 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Migration version: 010_add_user_login_lockout
+func Migration010AddUserLoginLockout() MigrationStep {
+	return MigrationStep{
+		Version:     "010_add_user_login_lockout",
+		Description: "Add failed_login_attempts and locked_until columns to users table",
+		Up: func(tx *gorm.DB) error {
+			// 001 migrates the current model, so fresh databases already have the columns
+			for _, column := range []string{"FailedLoginAttempts", "LockedUntil"} {
+				if tx.Migrator().HasColumn(&models.User{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&models.User{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"LockedUntil", "FailedLoginAttempts"} {
+				if err := tx.Migrator().DropColumn(&models.User{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package versions
+
+import (
+	"customable-corporate-site-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Migration version: 011_add_user_email_verified
+func Migration011AddUserEmailVerified() MigrationStep {
+	return MigrationStep{
+		Version:     "011_add_user_email_verified",
+		Description: "Add email_verified and email_verified_at columns to users table",
+		Up: func(tx *gorm.DB) error {
+			// 001 migrates the current model, so fresh databases already have the columns
+			if tx.Migrator().HasColumn(&models.User{}, "EmailVerified") {
+				return nil
+			}
+			for _, column := range []string{"EmailVerified", "EmailVerifiedAt"} {
+				if err := tx.Migrator().AddColumn(&models.User{}, column); err != nil {
+					return err
+				}
+			}
+
+			// Accounts created before verification existed count as verified, so requiring
+			// verification does not lock them out
+			return tx.Model(&models.User{}).Unscoped().Where("1 = 1").
+				UpdateColumns(map[string]interface{}{
+					"email_verified":    true,
+					"email_verified_at": gorm.Expr("created_at"),
+				}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"EmailVerifiedAt", "EmailVerified"} {
+				if err := tx.Migrator().DropColumn(&models.User{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package seeders
+
+import (
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AdminSeeder creates the initial admin user, unless the database already has an admin.
+type AdminSeeder struct {
+	Email     string
+	Password  string
+	FirstName string
+	LastName  string
+}
+
+// Name identifies the seeder in the seeds table
+func (s AdminSeeder) Name() string {
+	return "admin_user"
+}
+
+// Environments returns no environments: every deployment needs an admin
+func (s AdminSeeder) Environments() []string {
+	return nil
+}
+
+// Seed creates the admin user. The password must be provided; there is no default.
+func (s AdminSeeder) Seed(tx *gorm.DB) error {
+	var count int64
+	if err := tx.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if len(s.Password) < 6 {
+		return errors.New("SEED_ADMIN_PASSWORD must be set to at least 6 characters")
+	}
+
+	// The operator vouches for the email, so the admin can log in without verifying it
+	now := time.Now()
+	admin := models.User{
+		Email:     utils.NormalizeEmail(s.Email),
+		Password:  s.Password,
+		FirstName: utils.NormalizeName(s.FirstName),
+		LastName:  utils.NormalizeName(s.LastName),
+		Role:      models.RoleAdmin,
+		IsActive:  true,
+
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
+	}
+	return tx.Create(&admin).Error
+}
@@ -0,0 +1,22 @@
+package seeders
+
+import (
+	"customable-corporate-site-api/internal/config"
+
+	"gorm.io/gorm"
+)
+
+// RegisterSeeders registers all seeders.
+func RegisterSeeders(db *gorm.DB, cfg config.SeedConfig) *Runner {
+	runner := NewRunner(db)
+
+	// Register seeders in the order they should run
+	runner.Register(AdminSeeder{
+		Email:     cfg.AdminEmail,
+		Password:  cfg.AdminPassword,
+		FirstName: "John",
+		LastName:  "Doe",
+	})
+
+	return runner
+}
@@ -0,0 +1,129 @@
+package seeders
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Environments seeders can be limited to, matching SERVER_MODE
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+// Seeder inserts data into a migrated database. Each seeder runs at most once per database.
+type Seeder interface {
+	// Name identifies the seeder in the seeds table
+	Name() string
+	// Environments lists where the seeder runs; an empty list means every environment
+	Environments() []string
+	// Seed inserts the data, inside a transaction
+	Seed(tx *gorm.DB) error
+}
+
+// Seed records a seeder that has run.
+type Seed struct {
+	ID         uint      `gorm:"primaryKey"`
+	Name       string    `gorm:"unique;not null"`
+	ExecutedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for the Seed model.
+func (Seed) TableName() string {
+	return "seeds"
+}
+
+// Runner runs registered seeders that have not run yet.
+type Runner struct {
+	db      *gorm.DB
+	seeders []Seeder
+}
+
+// NewRunner creates a new Runner instance.
+func NewRunner(db *gorm.DB) *Runner {
+	return &Runner{db: db}
+}
+
+// Register registers a seeder; seeders run in registration order.
+func (r *Runner) Register(seeder Seeder) {
+	r.seeders = append(r.seeders, seeder)
+}
+
+// Run runs the pending seeders that apply to env. Seeders for other environments are skipped
+// without being recorded, so they still run if the database is later seeded in their environment.
+func (r *Runner) Run(env string) error {
+	log.Printf("Running seeders for %s...", env)
+
+	if err := r.db.AutoMigrate(&Seed{}); err != nil {
+		return fmt.Errorf("failed to initialize seeds table: %w", err)
+	}
+
+	var executedSeeds []Seed
+	if err := r.db.Find(&executedSeeds).Error; err != nil {
+		return fmt.Errorf("failed to fetch executed seeds: %w", err)
+	}
+
+	executed := make(map[string]bool)
+	for _, seed := range executedSeeds {
+		executed[seed.Name] = true
+	}
+
+	seededCount := 0
+	for _, seeder := range r.seeders {
+		if executed[seeder.Name()] {
+			continue
+		}
+		if !appliesTo(seeder, env) {
+			log.Printf("Skipping seeder %s: not enabled for %s", seeder.Name(), env)
+			continue
+		}
+
+		log.Printf("Running seeder: %s", seeder.Name())
+
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			if err := seeder.Seed(tx); err != nil {
+				return err
+			}
+			return tx.Create(&Seed{Name: seeder.Name(), ExecutedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to run seeder %s: %w", seeder.Name(), err)
+		}
+
+		seededCount++
+	}
+
+	if seededCount == 0 {
+		log.Println("No seeders to run.")
+	} else {
+		log.Printf("Successfully ran %d seeders.", seededCount)
+	}
+
+	return nil
+}
+
+// Reset forgets which seeders have run and runs them again for env, e.g. after the
+// migrations were reset and the seeded rows dropped with their tables.
+func (r *Runner) Reset(env string) error {
+	if err := r.db.Migrator().DropTable(&Seed{}); err != nil {
+		return fmt.Errorf("failed to drop seeds table: %w", err)
+	}
+	return r.Run(env)
+}
+
+// appliesTo reports whether the seeder runs in env
+func appliesTo(seeder Seeder, env string) bool {
+	environments := seeder.Environments()
+	if len(environments) == 0 {
+		return true
+	}
+	for _, environment := range environments {
+		if environment == env {
+			return true
+		}
+	}
+	return false
+}
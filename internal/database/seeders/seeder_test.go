@@ -0,0 +1,166 @@
+package seeders
+
+import (
+	"customable-corporate-site-api/internal/models"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// countingSeeder counts how often it runs, optionally failing
+type countingSeeder struct {
+	name         string
+	environments []string
+	runs         int
+	err          error
+}
+
+func (s *countingSeeder) Name() string           { return s.name }
+func (s *countingSeeder) Environments() []string { return s.environments }
+
+func (s *countingSeeder) Seed(tx *gorm.DB) error {
+	s.runs++
+	return s.err
+}
+
+func setupSeedDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestRunner_Run(t *testing.T) {
+	db := setupSeedDB(t)
+	everywhere := &countingSeeder{name: "everywhere"}
+	demo := &countingSeeder{name: "demo", environments: []string{Development}}
+
+	runner := NewRunner(db)
+	runner.Register(everywhere)
+	runner.Register(demo)
+
+	// Seeders for other environments are skipped, and not recorded
+	if err := runner.Run(Production); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if everywhere.runs != 1 || demo.runs != 0 {
+		t.Errorf("Run(production) runs = %d, %d, want 1, 0", everywhere.runs, demo.runs)
+	}
+
+	// Seeding again runs only what has not run yet
+	for i := 0; i < 2; i++ {
+		if err := runner.Run(Development); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+	if everywhere.runs != 1 || demo.runs != 1 {
+		t.Errorf("Run(development) runs = %d, %d, want 1, 1", everywhere.runs, demo.runs)
+	}
+
+	var seeds []Seed
+	db.Order("id").Find(&seeds)
+	if len(seeds) != 2 || seeds[0].Name != "everywhere" || seeds[1].Name != "demo" {
+		t.Errorf("seeds table = %+v, want everywhere and demo", seeds)
+	}
+}
+
+func TestRunner_Reset(t *testing.T) {
+	db := setupSeedDB(t)
+	seeder := &countingSeeder{name: "everywhere"}
+	runner := NewRunner(db)
+	runner.Register(seeder)
+
+	for i := 0; i < 2; i++ {
+		if err := runner.Reset(Production); err != nil {
+			t.Fatalf("Reset() error = %v", err)
+		}
+	}
+	if seeder.runs != 2 {
+		t.Errorf("runs after two resets = %d, want 2", seeder.runs)
+	}
+
+	var seeds int64
+	db.Model(&Seed{}).Count(&seeds)
+	if seeds != 1 {
+		t.Errorf("seeds table has %d rows, want 1", seeds)
+	}
+}
+
+func TestRunner_RunFailure(t *testing.T) {
+	db := setupSeedDB(t)
+	failing := &countingSeeder{name: "failing", err: errors.New("boom")}
+	after := &countingSeeder{name: "after"}
+
+	runner := NewRunner(db)
+	runner.Register(failing)
+	runner.Register(after)
+
+	if err := runner.Run(Development); err == nil {
+		t.Fatal("Run() error = nil, want the seeder's error")
+	}
+	if after.runs != 0 {
+		t.Errorf("Run() ran %d seeders after a failure, want 0", after.runs)
+	}
+
+	// The failed seeder is not recorded, so it runs again once fixed
+	failing.err = nil
+	if err := runner.Run(Development); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if failing.runs != 2 || after.runs != 1 {
+		t.Errorf("Run() after fix runs = %d, %d, want 2, 1", failing.runs, after.runs)
+	}
+}
+
+func TestAdminSeeder(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  *models.User
+		password  string
+		wantErr   bool
+		wantAdmin string
+	}{
+		{"Creates the admin", nil, "Secret123#", false, "admin@example.com"},
+		{"Requires a password", nil, "", true, ""},
+		{"Keeps an existing admin", &models.User{Email: "owner@example.com", Password: "password123", FirstName: "Ada", LastName: "Owner", Role: models.RoleAdmin}, "", false, "owner@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupSeedDB(t)
+			if tt.existing != nil {
+				if err := db.Create(tt.existing).Error; err != nil {
+					t.Fatalf("Failed to create test user: %v", err)
+				}
+			}
+
+			runner := NewRunner(db)
+			runner.Register(AdminSeeder{Email: " Admin@Example.com ", Password: tt.password, FirstName: "John", LastName: "Doe"})
+			err := runner.Run(Production)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var admins []models.User
+			db.Where("role = ?", models.RoleAdmin).Find(&admins)
+			if tt.wantAdmin == "" {
+				if len(admins) != 0 {
+					t.Errorf("Run() created %d admins, want none", len(admins))
+				}
+				return
+			}
+			if len(admins) != 1 || admins[0].Email != tt.wantAdmin {
+				t.Fatalf("Run() admins = %+v, want only %s", admins, tt.wantAdmin)
+			}
+			if tt.existing == nil && !admins[0].CheckPassword(tt.password) {
+				t.Error("Run() stored a password that does not match SEED_ADMIN_PASSWORD")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// tracerName identifies the spans created by this package
+const tracerName = "customable-corporate-site-api/internal/database"
+
+// spanKey stores the span of a statement between its before and after callbacks
+const spanKey = "tracing:span"
+
+// tracingPlugin records a client span for every database call. Spans are children of the
+// span in the statement's context, so queries run with db.WithContext(ctx) nest under
+// the request that made them.
+type tracingPlugin struct {
+	tracer trace.Tracer
+}
+
+// NewTracingPlugin returns a gorm plugin that traces database calls; register it with db.Use
+func NewTracingPlugin(provider trace.TracerProvider) gorm.Plugin {
+	return &tracingPlugin{tracer: provider.Tracer(tracerName)}
+}
+
+// Name returns the plugin name
+func (p *tracingPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the tracing callbacks around every statement type
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", p.before("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", p.after("create")),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", p.before("select")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", p.after("select")),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", p.before("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", p.after("update")),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", p.after("delete")),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", p.before("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", p.after("row")),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", p.after("raw")),
+	)
+}
+
+// before starts the span of a statement
+func (p *tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := p.tracer.Start(tx.Statement.Context, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", tx.Dialector.Name()),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(spanKey, span)
+	}
+}
+
+// after ends the span of a statement, naming it after its table and recording the query and any error
+func (p *tracingPlugin) after(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		value, _ := tx.InstanceGet(spanKey)
+		span, ok := value.(trace.Span)
+		if !ok {
+			return
+		}
+		defer span.End()
+
+		if table := tx.Statement.Table; table != "" {
+			span.SetName(operation + " " + table)
+			span.SetAttributes(attribute.String("db.collection.name", table))
+		}
+		span.SetAttributes(
+			attribute.String("db.query.text", tx.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+		)
+
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(tx.Error)
+			span.SetStatus(codes.Error, tx.Error.Error())
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+func TestTracingPlugin(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	if err := db.Use(NewTracingPlugin(provider)); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	var user models.User
+	db.WithContext(ctx).Where("email = ?", "missing@example.com").First(&user)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want the query and its parent", len(spans))
+	}
+
+	query := spans[0]
+	if query.Name != "select users" {
+		t.Errorf("span name = %q, want %q", query.Name, "select users")
+	}
+	if query.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("query span parent = %s, want the request span %s", query.Parent.SpanID(), parent.SpanContext().SpanID())
+	}
+	if len(query.Events) != 0 {
+		t.Errorf("query span events = %v, want record not found left unrecorded", query.Events)
+	}
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Lifecycle event types
+const (
+	UserRegistered = "user.registered"
+)
+
+// defaultBufferSize is how many events a subscriber can fall behind before events are dropped
+const defaultBufferSize = 16
+
+// Event represents something that happened in the application
+type Event struct {
+	Type       string      `json:"type"`
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// New creates an event of the given type occurring now
+func New(eventType string, data interface{}) Event {
+	return Event{
+		Type:       eventType,
+		Data:       data,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// Bus fans out published events to every subscriber
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus creates a new event bus
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber. The returned function unsubscribes and must be
+// called once the subscriber stops reading, after which the channel is closed.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, defaultBufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			close(ch)
+			b.mu.Unlock()
+		})
+	}
+	return ch, unsubscribe
+}
+
+// Publish delivers the event to every subscriber without blocking.
+// Subscribers whose buffer is full miss the event.
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
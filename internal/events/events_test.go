@@ -0,0 +1,41 @@
+package events
+
+import "testing"
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := NewBus()
+	first, unsubscribeFirst := bus.Subscribe()
+	second, unsubscribeSecond := bus.Subscribe()
+	defer unsubscribeSecond()
+
+	bus.Publish(New(UserRegistered, "test@example.com"))
+
+	for _, ch := range []<-chan Event{first, second} {
+		event := <-ch
+		if event.Type != UserRegistered {
+			t.Errorf("Subscribe() event type = %q, want %q", event.Type, UserRegistered)
+		}
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	bus.Publish(New(UserRegistered, "other@example.com"))
+
+	if _, ok := <-first; ok {
+		t.Errorf("Subscribe() channel received an event after unsubscribing")
+	}
+	if event := <-second; event.Data != "other@example.com" {
+		t.Errorf("Subscribe() event data = %v, want %v", event.Data, "other@example.com")
+	}
+}
+
+func TestBus_PublishDoesNotBlock(t *testing.T) {
+	bus := NewBus()
+	_, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	// A subscriber that never reads must not stall publishers
+	for i := 0; i < defaultBufferSize*2; i++ {
+		bus.Publish(New(UserRegistered, i))
+	}
+}
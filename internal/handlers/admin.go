@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"customable-corporate-site-api/internal/events"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/services"
+	"customable-corporate-site-api/internal/utils"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultHeartbeatInterval keeps idle event streams open through proxies that close silent connections
+const defaultHeartbeatInterval = 15 * time.Second
+
+// AdminHandler handles admin dashboard HTTP requests.
+type AdminHandler struct {
+	events    *events.Bus
+	dashboard *services.DashboardService
+	auditLogs *services.AuditLogService
+	heartbeat time.Duration
+}
+
+// NewAdminHandler creates a new instance of AdminHandler.
+func NewAdminHandler(bus *events.Bus, dashboard *services.DashboardService, auditLogs *services.AuditLogService) *AdminHandler {
+	return &AdminHandler{events: bus, dashboard: dashboard, auditLogs: auditLogs, heartbeat: defaultHeartbeatInterval}
+}
+
+// GetDashboard returns the admin dashboard summary.
+// @Summary Get the admin dashboard
+// @Description Get user statistics and the most recent audit log entries. The summary is cached for a short time.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.Dashboard
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/admin/dashboard [get]
+func (h *AdminHandler) GetDashboard(c *gin.Context) {
+	dashboard, err := h.dashboard.GetDashboard(c.Request.Context())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve dashboard", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Dashboard retrieved successfully", dashboard)
+}
+
+// ListAuditLogs searches the audit log.
+// @Summary Search the audit log
+// @Description Get audit log entries, newest first, with the actor's email. from and to take a date, covering the whole day, or an RFC 3339 timestamp and are inclusive. With format=csv, every matching entry is downloaded as CSV instead of a page.
+// @Tags Admin
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param actor_id query int false "Actor user ID"
+// @Param action query string false "Action, e.g. user.updated"
+// @Param from query string false "Earliest creation date or time"
+// @Param to query string false "Latest creation date or time"
+// @Param format query string false "Response format" Enums(json, csv)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} utils.PaginationResponse{data=[]models.AuditLogEntry}
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/admin/audit-logs [get]
+func (h *AdminHandler) ListAuditLogs(c *gin.Context) {
+	var query services.AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	switch format := c.DefaultQuery("format", "json"); format {
+	case "json":
+	case "csv":
+		h.exportAuditLogs(c, &query)
+		return
+	default:
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", []utils.ErrorDetail{{
+			Code:    utils.CodeInvalidChoice,
+			Field:   "format",
+			Message: "format must be one of json, csv",
+			Value:   format,
+		}})
+		return
+	}
+
+	page := utils.BindPageRequest(c)
+	entries, total, err := h.auditLogs.Search(c.Request.Context(), &query, page.Offset(), page.PageSize)
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve audit log", err)
+		return
+	}
+
+	utils.RespondPaged(c, entries, total, page)
+}
+
+// auditLogCSVHeader is the header row of audit log exports
+var auditLogCSVHeader = []string{"id", "created_at", "actor_id", "actor_email", "action", "target_type", "target_id", "ip_address", "details"}
+
+// exportAuditLogs sends every entry matching query as a CSV download
+func (h *AdminHandler) exportAuditLogs(c *gin.Context, query *services.AuditLogQuery) {
+	entries, err := h.auditLogs.Export(c.Request.Context(), query)
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to export audit log", err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="audit-logs.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(auditLogCSVHeader)
+	for _, entry := range entries {
+		w.Write(auditLogCSVRow(entry))
+	}
+	w.Flush()
+}
+
+// auditLogCSVRow formats an entry for export. Text that spreadsheets would run as a formula is
+// prefixed with a quote, since emails and details come from user input.
+func auditLogCSVRow(entry models.AuditLogEntry) []string {
+	row := []string{
+		strconv.FormatUint(uint64(entry.ID), 10),
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatUint(uint64(entry.ActorID), 10),
+		entry.ActorEmail,
+		entry.Action,
+		entry.TargetType,
+		strconv.FormatUint(uint64(entry.TargetID), 10),
+		entry.IPAddress,
+		entry.Details,
+	}
+	return escapeCSVFormulas(row)
+}
+
+// escapeCSVFormulas prefixes the cells that spreadsheets would run as a formula with a quote
+func escapeCSVFormulas(row []string) []string {
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			row[i] = "'" + cell
+		}
+	}
+	return row
+}
+
+// Events streams lifecycle events to admins.
+// @Summary Stream admin notifications
+// @Description Stream lifecycle events such as new registrations as Server-Sent Events.
+// @Tags Admin
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} events.Event
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/admin/events [get]
+func (h *AdminHandler) Events(c *gin.Context) {
+	stream, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	// Let the client know the subscription is live before the first event arrives
+	io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			io.WriteString(w, ": heartbeat\n\n")
+			return true
+		}
+	})
+}
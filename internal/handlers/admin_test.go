@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"customable-corporate-site-api/internal/events"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"customable-corporate-site-api/internal/services"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminHandler_Events(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bus := events.NewBus()
+	handler := NewAdminHandler(bus, nil, nil)
+
+	router := gin.New()
+	router.GET("/admin/events", handler.Events)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/admin/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Events() Content-Type = %q, want text/event-stream", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readLine := func() string {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event stream: %v", err)
+		}
+		return strings.TrimRight(line, "\n")
+	}
+
+	// Wait for the subscription to be live before publishing
+	if line := readLine(); line != ": connected" {
+		t.Fatalf("Events() first line = %q, want %q", line, ": connected")
+	}
+	readLine()
+
+	bus.Publish(events.New(events.UserRegistered, map[string]string{"email": "test@example.com"}))
+
+	if line := readLine(); line != "event:"+events.UserRegistered {
+		t.Fatalf("Events() event line = %q, want %q", line, "event:"+events.UserRegistered)
+	}
+
+	data := strings.TrimPrefix(readLine(), "data:")
+	var event struct {
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatalf("Failed to decode event data %q: %v", data, err)
+	}
+	if event.Data["email"] != "test@example.com" {
+		t.Errorf("Events() event data = %v, want email test@example.com", event.Data)
+	}
+}
+
+func TestAdminHandler_GetDashboard(t *testing.T) {
+	router, db := setupUserHandler(t)
+	dashboard := services.NewDashboardService(postgres.NewUserRepository(db), postgres.NewAuditLogRepository(db))
+	router.GET("/admin/dashboard", NewAdminHandler(events.NewBus(), dashboard, nil).GetDashboard)
+
+	w := serve(router, http.MethodGet, "/admin/dashboard", "", nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetDashboard() status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			Users              models.UserStats  `json:"users"`
+			RecentAuditEntries []models.AuditLog `json:"recent_audit_entries"`
+			GeneratedAt        time.Time         `json:"generated_at"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if body.Data.Users.Total != 2 || body.Data.Users.ByRole[models.RoleAdmin] != 1 {
+		t.Errorf("GetDashboard() users = %+v, want 2 total with 1 admin", body.Data.Users)
+	}
+	if body.Data.GeneratedAt.IsZero() {
+		t.Error("GetDashboard() generated_at is missing")
+	}
+}
+
+func setupAuditLogs(t *testing.T) *gin.Engine {
+	router, db := setupUserHandler(t)
+	auditRepo := postgres.NewAuditLogRepository(db)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, entry := range []*models.AuditLog{
+		{ActorID: 1, Action: models.AuditActionUserUpdated, TargetType: "user", TargetID: 2, Details: `{"first_name":"Jane"}`, CreatedAt: day.Add(9 * time.Hour)},
+		{ActorID: 1, Action: models.AuditActionUserDeleted, TargetType: "user", TargetID: 3, Details: "=HYPERLINK()", CreatedAt: day.Add(20 * time.Hour)},
+		{ActorID: 2, Action: models.AuditActionUserUpdated, TargetType: "user", TargetID: 2, CreatedAt: day.AddDate(0, 0, 1)},
+	} {
+		if err := auditRepo.Create(context.Background(), entry); err != nil {
+			t.Fatalf("Failed to create audit log entry: %v", err)
+		}
+	}
+
+	router.GET("/admin/audit-logs", NewAdminHandler(events.NewBus(), nil, services.NewAuditLogService(auditRepo)).ListAuditLogs)
+	return router
+}
+
+func TestAdminHandler_ListAuditLogs(t *testing.T) {
+	router := setupAuditLogs(t)
+
+	tests := []struct {
+		name          string
+		query         string
+		wantTargetIDs []uint
+		wantTotal     int
+	}{
+		{"All, newest first", "", []uint{2, 3, 2}, 3},
+		{"Actor", "?actor_id=1", []uint{3, 2}, 2},
+		{"Action", "?action=user.updated", []uint{2, 2}, 2},
+		{"Inclusive dates", "?from=2024-03-01&to=2024-03-01", []uint{3, 2}, 2},
+		{"Page", "?page=2&page_size=2", []uint{2}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/admin/audit-logs"+tt.query, "", nil, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("ListAuditLogs() status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var body struct {
+				Data       []models.AuditLogEntry `json:"data"`
+				Pagination struct {
+					TotalItems int `json:"total_items"`
+				} `json:"pagination"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response body: %v", err)
+			}
+			if body.Pagination.TotalItems != tt.wantTotal {
+				t.Errorf("ListAuditLogs() total = %d, want %d", body.Pagination.TotalItems, tt.wantTotal)
+			}
+			if len(body.Data) != len(tt.wantTargetIDs) {
+				t.Fatalf("ListAuditLogs() returned %d entries, want %d", len(body.Data), len(tt.wantTargetIDs))
+			}
+			for i, entry := range body.Data {
+				if entry.TargetID != tt.wantTargetIDs[i] {
+					t.Errorf("ListAuditLogs() entry %d target = %d, want %d", i, entry.TargetID, tt.wantTargetIDs[i])
+				}
+				if entry.ActorEmail == "" {
+					t.Errorf("ListAuditLogs() entry %d has no actor email", i)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ListAuditLogs_InvalidQuery(t *testing.T) {
+	router := setupAuditLogs(t)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantField string
+	}{
+		{"Malformed date", "?from=01/03/2024", "from"},
+		{"Reversed range", "?from=2024-03-02&to=2024-03-01", "to"},
+		{"Reversed range on export", "?from=2024-03-02&to=2024-03-01&format=csv", "to"},
+		{"Unknown format", "?format=xml", "format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/admin/audit-logs"+tt.query, "", nil, "")
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("ListAuditLogs() status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(w.Body.String(), `"field":"`+tt.wantField+`"`) {
+				t.Errorf("ListAuditLogs() body = %s, want an error on %s", w.Body.String(), tt.wantField)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ListAuditLogs_CSV(t *testing.T) {
+	router := setupAuditLogs(t)
+
+	w := serve(router, http.MethodGet, "/admin/audit-logs?actor_id=1&format=csv&page_size=1", "", nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("ListAuditLogs() status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("ListAuditLogs() Content-Type = %q, want text/csv", ct)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	// The export ignores pagination
+	if len(rows) != 3 {
+		t.Fatalf("ListAuditLogs() CSV has %d rows, want a header and 2 entries", len(rows))
+	}
+	if strings.Join(rows[0], ",") != strings.Join(auditLogCSVHeader, ",") {
+		t.Errorf("ListAuditLogs() CSV header = %v, want %v", rows[0], auditLogCSVHeader)
+	}
+	if rows[1][3] != "admin@example.com" || rows[1][4] != models.AuditActionUserDeleted {
+		t.Errorf("ListAuditLogs() first CSV row = %v, want the newest entry by admin@example.com", rows[1])
+	}
+	if rows[1][8] != "'=HYPERLINK()" {
+		t.Errorf("ListAuditLogs() details = %q, want the formula escaped", rows[1][8])
+	}
+}
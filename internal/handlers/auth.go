@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"customable-corporate-site-api/internal/middleware"
 	"customable-corporate-site-api/internal/services"
 	"customable-corporate-site-api/internal/utils"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,11 +15,24 @@ import (
 // AuthHandler handles authentication-related HTTP requests.
 type AuthHandler struct {
 	authService *services.AuthService
+	cookieMode  bool
+}
+
+// AuthHandlerConfig holds the configuration for AuthHandler.
+type AuthHandlerConfig struct {
+	// CookieMode delivers tokens as Secure, HttpOnly, SameSite=Strict cookies and
+	// omits them from the JSON body of login and refresh responses
+	CookieMode bool
 }
 
 // NewAuthHandler creates a new instance of AuthHandler.
 func NewAuthHandler(authService *services.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+	return NewAuthHandlerWithConfig(authService, AuthHandlerConfig{})
+}
+
+// NewAuthHandlerWithConfig creates a new instance of AuthHandler with custom configuration.
+func NewAuthHandlerWithConfig(authService *services.AuthService, config AuthHandlerConfig) *AuthHandler {
+	return &AuthHandler{authService: authService, cookieMode: config.CookieMode}
 }
 
 // Register handles user registration.
@@ -33,13 +50,19 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err)
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
 		return
 	}
 
+	// The client IP is passed on to the CAPTCHA provider
+	req.IPAddress = c.ClientIP()
+
 	// Call service to register user
-	resp, err := h.authService.Register(&req)
+	resp, err := h.authService.Register(c.Request.Context(), &req)
 	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to register user", err)
 		return
 	}
@@ -56,23 +79,41 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Param loginRequest body services.LoginRequest true "Login Request"
 // @Success 200 {object} services.AuthResponse
 // @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} utils.APIResponse "Email not verified, when verification is required"
+// @Failure 423 {object} utils.APIResponse "Account temporarily locked after repeated failed logins"
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req services.LoginRequest
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err)
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
 		return
 	}
 
+	// Record the client on the session
+	req.UserAgent = c.Request.UserAgent()
+	req.IPAddress = c.ClientIP()
+
 	// Call service to login user
-	resp, err := h.authService.Login(&req)
+	resp, err := h.authService.Login(c.Request.Context(), &req)
+	if errors.Is(err, services.ErrAccountLocked) {
+		utils.ErrorResponseWithCode(c, http.StatusLocked, utils.CodeAccountLocked, "Account temporarily locked after too many failed logins, please try again later", err)
+		return
+	}
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		utils.ErrorResponseWithCode(c, http.StatusForbidden, utils.CodeEmailNotVerified, "Please verify your email address before logging in", err)
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid email or password", err)
 		return
 	}
 
+	if h.cookieMode {
+		h.setTokenCookies(c, resp.Token)
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "User logged in successfully", resp)
 }
 
@@ -87,32 +128,81 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Failure 400 {object} services.ErrorResponse
 // @Router /api/v1/auth/refresh-token [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	var req struct {
-		RefreshToken string `json:"refresh_token" binding:"required"`
+	// In cookie mode the refresh token cookie is used when present
+	var refreshToken string
+	if h.cookieMode {
+		refreshToken, _ = c.Cookie(middleware.RefreshTokenCookie)
 	}
 
-	// Bind and validate request
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err)
-		return
+	if refreshToken == "" {
+		var req RefreshTokenRequest
+
+		// Bind and validate request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+			return
+		}
+		refreshToken = req.RefreshToken
 	}
 
 	// Call service to refresh token
-	tokenResp, err := h.authService.RefreshToken(req.RefreshToken)
+	tokenResp, err := h.authService.RefreshToken(c.Request.Context(), refreshToken)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid refresh token", err)
 		return
 	}
 
+	if h.cookieMode {
+		h.setTokenCookies(c, tokenResp)
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", tokenResp)
 }
 
+// Logout handles logging out the current client.
+// @Summary Logout
+// @Description Revoke the access token (from the Authorization header or cookie) and the refresh token (from the cookie or request body), end the refresh token's session and clear the auth cookies.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param refreshTokenRequest body services.RefreshTokenRequest false "Refresh Token Request"
+// @Success 200 {object} utils.APIResponse
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	refreshToken, _ := c.Cookie(middleware.RefreshTokenCookie)
+	if refreshToken == "" && c.Request.ContentLength != 0 {
+		var req RefreshTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+			return
+		}
+		refreshToken = req.RefreshToken
+	}
+
+	accessToken, _ := c.Cookie(middleware.AccessTokenCookie)
+	if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		accessToken = bearer
+	}
+
+	if accessToken != "" || refreshToken != "" {
+		if err := h.authService.Logout(c.Request.Context(), accessToken, refreshToken); err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to logout", err)
+			return
+		}
+	}
+
+	h.clearTokenCookies(c)
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged out successfully", nil)
+}
+
 // GetProfile handles fetching the authenticated user's profile.
 // @Summary Get user profile
 // @Description Retrieve the profile of the authenticated user.
 // @Tags Auth
 // @Produce json
 // @Security BearerAuth
+// @Param fields query string false "Comma-separated fields to return, e.g. id,email,role"
 // @Success 200 {object} services.UserResponse
 // @Failure 401 {object} services.ErrorResponse
 // @Failure 404 {object} services.ErrorResponse
@@ -133,13 +223,18 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	}
 
 	// Call service to get user profile
-	profile, err := h.authService.GetProfile(id)
+	profile, err := h.authService.GetProfile(c.Request.Context(), id)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "User not found", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "User profile retrieved successfully", profile)
+	data, ok := utils.SelectFields(c, profile, userResponseFields)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User profile retrieved successfully", data)
 }
 
 // UpdateProfile handles updating the authenticated user's profile.
@@ -174,13 +269,16 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data", err)
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
 		return
 	}
 
 	// Call service to update user profile
-	updatedProfile, err := h.authService.UpdateProfile(id, &req)
+	updatedProfile, err := h.authService.UpdateProfile(c.Request.Context(), id, &req)
 	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update profile", err)
 		return
 	}
@@ -188,6 +286,381 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Profile updated successfully", updatedProfile)
 }
 
+// PatchProfile handles partially updating the authenticated user's profile.
+// @Summary Partially update user profile
+// @Description Update only the fields present in the request body.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param patchProfileRequest body services.PatchProfileRequest true "Patch Profile Request"
+// @Success 200 {object} services.UserResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Router /api/v1/auth/profile [patch]
+func (h *AuthHandler) PatchProfile(c *gin.Context) {
+	id, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.PatchProfileRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	// Call service to patch user profile
+	updatedProfile, err := h.authService.PatchProfile(c.Request.Context(), id, &req)
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update profile", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Profile updated successfully", updatedProfile)
+}
+
+// RequestEmailChange handles requesting an email change confirmed by email.
+// @Summary Request email change
+// @Description Send a confirmation link to the new email. The email changes once the link is opened; until then the current email keeps working. Requires the current password.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param changeEmailRequest body services.ChangeEmailRequest true "Change Email Request"
+// @Success 202 {object} services.EmailChangeResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Router /api/v1/auth/change-email [post]
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	id, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.ChangeEmailRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	resp, err := h.authService.RequestEmailChange(c.Request.Context(), id, &req)
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrInvalidCurrentPassword):
+			invalidCurrentPasswordResponse(c)
+		case errors.Is(err, services.ErrEmailTaken):
+			utils.ConflictResponse(c, "Email is already in use", err)
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to request email change", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Confirmation email sent", resp)
+}
+
+// ConfirmEmailChange handles the link confirming a new email.
+// @Summary Confirm email change
+// @Description Make the pending email the account's email, using the token from the confirmation link.
+// @Tags Auth
+// @Produce json
+// @Param token query string true "Confirmation token"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Router /api/v1/auth/confirm-email [get]
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeTokenMissing, "Confirmation token is required", nil)
+		return
+	}
+
+	user, err := h.authService.ConfirmEmailChange(c.Request.Context(), token)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidEmailChangeToken):
+			utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeTokenInvalid, "Confirmation link is invalid or expired", err)
+		case errors.Is(err, services.ErrEmailTaken):
+			utils.ConflictResponse(c, "Email is already in use", err)
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to change email", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Email changed successfully", user)
+}
+
+// VerifyEmail handles the link verifying a new user's email.
+// @Summary Verify email
+// @Description Mark the account's email as verified, using the token from the link sent at registration. Opening the link again succeeds.
+// @Tags Auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Router /api/v1/auth/verify-email [get]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeTokenMissing, "Verification token is required", nil)
+		return
+	}
+
+	if err := h.authService.VerifyEmail(c.Request.Context(), token); err != nil {
+		if errors.Is(err, services.ErrInvalidEmailVerifyToken) {
+			utils.ErrorResponseWithCode(c, http.StatusBadRequest, utils.CodeTokenInvalid, "Verification link is invalid or expired", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify email", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Email verified successfully", nil)
+}
+
+// ResendVerification handles requests for a new email verification link.
+// @Summary Resend verification email
+// @Description Send a new link verifying the account's email, at most once per cooldown per email. Unknown and already verified emails get the same response, without an email.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body services.ResendVerificationRequest true "Account email"
+// @Success 202 {object} utils.APIResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Router /api/v1/auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req services.ResendVerificationRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	if err := h.authService.ResendVerificationEmail(c.Request.Context(), &req); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to resend verification email", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "If the account needs verifying, a new link has been sent", nil)
+}
+
+// ChangePassword handles changing the authenticated user's password.
+// @Summary Change password
+// @Description Replace the password. Requires the current password. Every token issued before, including the one making the request, stops working and every session is revoked, so the user must log in again.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param changePasswordRequest body services.ChangePasswordRequest true "Change Password Request"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/auth/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	id, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.ChangePasswordRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	if err := h.authService.ChangePassword(c.Request.Context(), id, req.CurrentPassword, req.NewPassword); err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrInvalidCurrentPassword) {
+			invalidCurrentPasswordResponse(c)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to change password", err)
+		return
+	}
+
+	if h.cookieMode {
+		h.clearTokenCookies(c)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Password changed successfully, please log in again", nil)
+}
+
+// DeleteAccount handles deleting the authenticated user's account.
+// @Summary Delete account
+// @Description Delete the authenticated user's account and revoke all sessions. Requires the current password.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param deleteAccountRequest body services.DeleteAccountRequest true "Delete Account Request"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/auth/account [delete]
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	id, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.DeleteAccountRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	if err := h.authService.DeleteAccount(c.Request.Context(), id, &req); err != nil {
+		if errors.Is(err, services.ErrInvalidCurrentPassword) {
+			invalidCurrentPasswordResponse(c)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete account", err)
+		return
+	}
+
+	if h.cookieMode {
+		h.clearTokenCookies(c)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Account deleted successfully", nil)
+}
+
+// GetSessions handles listing the authenticated user's active sessions.
+// @Summary List active sessions
+// @Description List the devices where the authenticated user is currently logged in.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.SessionResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	id, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(c.Request.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve sessions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession handles revoking one of the authenticated user's sessions.
+// @Summary Revoke a session
+// @Description Revoke a single session so its refresh token can no longer be used.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	id, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid session ID", err)
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), id, uint(sessionID)); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			utils.NotFoundResponse(c, "Session")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke session", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Session revoked successfully", nil)
+}
+
+// RevokeAllSessions handles revoking all of the authenticated user's sessions.
+// @Summary Log out everywhere
+// @Description Revoke every session of the authenticated user.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.APIResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Router /api/v1/auth/sessions [delete]
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	id, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.authService.RevokeAllSessions(c.Request.Context(), id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke sessions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "All sessions revoked successfully", gin.H{"revoked": revoked})
+}
+
+// IntrospectToken handles describing the caller's access token.
+// @Summary Introspect access token
+// @Description Return the claims, issue time and expiry of the access token from the Authorization header or cookie; expired or revoked tokens are described with active set to false.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.TokenIntrospectionResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Router /api/v1/auth/token/introspect [get]
+func (h *AuthHandler) IntrospectToken(c *gin.Context) {
+	accessToken, _ := c.Cookie(middleware.AccessTokenCookie)
+	if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		accessToken = bearer
+	}
+	if accessToken == "" {
+		utils.ErrorResponseWithCode(c, http.StatusUnauthorized, utils.CodeTokenMissing, "Authorization header is required", nil)
+		return
+	}
+
+	introspection, err := h.authService.Introspect(c.Request.Context(), accessToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, utils.CodeTokenInvalid, "Invalid token", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to introspect token", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Token introspected successfully", introspection)
+}
+
 // GetCurrentUser is an alias for GetProfile to maintain backward compatibility.
 func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	h.GetProfile(c)
@@ -197,3 +670,76 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
+
+// CurrentUserID reads the authenticated user's ID set by the JWT middleware,
+// responding with 401 when it is missing or malformed.
+func CurrentUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return 0, false
+	}
+
+	id, ok := userID.(uint)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user ID", nil)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// invalidCurrentPasswordResponse rejects a sensitive change confirmed with the wrong password
+func invalidCurrentPasswordResponse(c *gin.Context) {
+	utils.ErrorResponseWithCode(c, http.StatusForbidden, utils.CodeInvalidCurrentPassword, "Current password is incorrect", nil)
+}
+
+// fieldErrorResponse reports a validation failure detected by a service the same way as a
+// binding error. It returns false, without responding, when err is neither a *services.FieldError nor
+// services.FieldErrors.
+func fieldErrorResponse(c *gin.Context, err error) bool {
+	var fieldErrs services.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		details := make([]utils.ErrorDetail, len(fieldErrs))
+		for i, fieldErr := range fieldErrs {
+			details[i] = utils.ErrorDetail{Code: fieldErr.Code, Field: fieldErr.Field, Message: fieldErr.Message}
+		}
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", details)
+		return true
+	}
+
+	var fieldErr *services.FieldError
+	if !errors.As(err, &fieldErr) {
+		return false
+	}
+
+	utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", []utils.ErrorDetail{{
+		Code:    fieldErr.Code,
+		Field:   fieldErr.Field,
+		Message: fieldErr.Message,
+	}})
+	return true
+}
+
+// setTokenCookies moves the tokens from the response into HttpOnly cookies.
+// The refresh token cookie only outlives the browser session when the login asked to be remembered.
+func (h *AuthHandler) setTokenCookies(c *gin.Context, tokens *services.TokenResponse) {
+	refreshMaxAge := 0
+	if tokens.RememberMe {
+		refreshMaxAge = int(tokens.RefreshExpiresIn)
+	}
+
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(middleware.AccessTokenCookie, tokens.AccessToken, int(tokens.ExpiresIn), "/", "", true, true)
+	c.SetCookie(middleware.RefreshTokenCookie, tokens.RefreshToken, refreshMaxAge, "/", "", true, true)
+
+	tokens.AccessToken = ""
+	tokens.RefreshToken = ""
+}
+
+// clearTokenCookies expires the auth cookies
+func (h *AuthHandler) clearTokenCookies(c *gin.Context) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(middleware.AccessTokenCookie, "", -1, "/", "", true, true)
+	c.SetCookie(middleware.RefreshTokenCookie, "", -1, "/", "", true, true)
+}
@@ -0,0 +1,663 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"customable-corporate-site-api/internal/jwtutil"
+	"customable-corporate-site-api/internal/middleware"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"customable-corporate-site-api/internal/services"
+	"customable-corporate-site-api/internal/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+)
+
+const testSecret = "test_secret-key"
+
+func setupAuthHandler(t *testing.T, config AuthHandlerConfig) *gin.Engine {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Session{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	authService := services.NewAuthServiceWithConfig(postgres.NewUserRepository(db), services.AuthConfig{
+		JWTSecret: testSecret,
+		JWTExpiry: time.Hour,
+		Sessions:  postgres.NewSessionRepository(db),
+
+		LockoutAttempts: 3,
+	})
+	if _, err := authService.Register(context.Background(), &services.RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	jwtConfig := middleware.JWTAuthConfig{Secret: testSecret, Leeway: middleware.DefaultJWTLeeway}
+	if config.CookieMode {
+		jwtConfig.CookieName = middleware.AccessTokenCookie
+	}
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandlerWithConfig(authService, config)
+	router := gin.New()
+	router.POST("/auth/register", handler.Register)
+	router.POST("/auth/login", handler.Login)
+	router.POST("/auth/refresh", handler.RefreshToken)
+	router.POST("/auth/logout", handler.Logout)
+	router.GET("/auth/token/introspect", handler.IntrospectToken)
+	router.GET("/auth/profile", middleware.JWTAuthWithConfig(jwtConfig), handler.GetProfile)
+	router.PUT("/auth/profile", middleware.JWTAuthWithConfig(jwtConfig), handler.UpdateProfile)
+	router.PUT("/auth/password", middleware.JWTAuthWithConfig(jwtConfig), handler.ChangePassword)
+	router.DELETE("/auth/account", middleware.JWTAuthWithConfig(jwtConfig), handler.DeleteAccount)
+	return router
+}
+
+func serve(router *gin.Engine, method, target, body string, cookies []*http.Cookie, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeToken(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	var body struct {
+		Data struct {
+			Token map[string]interface{} `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response body %q: %v", w.Body.String(), err)
+	}
+	return body.Data.Token
+}
+
+func findCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+const loginBody = `{"email":"test@example.com","password":"password123"}`
+
+func TestAuthHandler_HeaderMode(t *testing.T) {
+	router := setupAuthHandler(t, AuthHandlerConfig{})
+
+	w := serve(router, http.MethodPost, "/auth/login", loginBody, nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Login() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("Login() set cookies in header mode")
+	}
+
+	token := decodeToken(t, w)
+	accessToken, _ := token["access_token"].(string)
+	if accessToken == "" {
+		t.Fatalf("Login() response has no access_token: %s", w.Body.String())
+	}
+
+	if w := serve(router, http.MethodGet, "/auth/profile", "", nil, "Bearer "+accessToken); w.Code != http.StatusOK {
+		t.Errorf("GetProfile() with header status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuthHandler_CookieMode(t *testing.T) {
+	router := setupAuthHandler(t, AuthHandlerConfig{CookieMode: true})
+
+	w := serve(router, http.MethodPost, "/auth/login", loginBody, nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Login() status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	token := decodeToken(t, w)
+	if _, ok := token["access_token"]; ok {
+		t.Errorf("Login() response contains access_token in cookie mode")
+	}
+	if _, ok := token["refresh_token"]; ok {
+		t.Errorf("Login() response contains refresh_token in cookie mode")
+	}
+
+	access := findCookie(w, middleware.AccessTokenCookie)
+	refresh := findCookie(w, middleware.RefreshTokenCookie)
+	if access == nil || refresh == nil {
+		t.Fatalf("Login() cookies = %v, want access and refresh token cookies", w.Result().Cookies())
+	}
+	if !access.HttpOnly || !access.Secure || access.SameSite != http.SameSiteStrictMode {
+		t.Errorf("Login() access cookie = %+v, want Secure, HttpOnly, SameSite=Strict", access)
+	}
+
+	// The access token cookie authenticates without an Authorization header
+	if w := serve(router, http.MethodGet, "/auth/profile", "", []*http.Cookie{access}, ""); w.Code != http.StatusOK {
+		t.Errorf("GetProfile() with cookie status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	// Refresh reads the refresh token from its cookie and rotates both cookies
+	w = serve(router, http.MethodPost, "/auth/refresh", "", []*http.Cookie{refresh}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("RefreshToken() status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	rotated := findCookie(w, middleware.RefreshTokenCookie)
+	if rotated == nil || rotated.Value == refresh.Value {
+		t.Fatalf("RefreshToken() did not rotate the refresh token cookie")
+	}
+
+	// Logout clears the cookies and ends the session
+	w = serve(router, http.MethodPost, "/auth/logout", "", []*http.Cookie{rotated}, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Logout() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if cleared := findCookie(w, middleware.AccessTokenCookie); cleared == nil || cleared.MaxAge >= 0 {
+		t.Errorf("Logout() access cookie = %+v, want it expired", cleared)
+	}
+	if w := serve(router, http.MethodPost, "/auth/refresh", "", []*http.Cookie{rotated}, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("RefreshToken() after logout status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuthHandler_CookieMode_RememberMe(t *testing.T) {
+	router := setupAuthHandler(t, AuthHandlerConfig{CookieMode: true})
+
+	tests := []struct {
+		name       string
+		body       string
+		persistent bool
+	}{
+		{"Session cookie", loginBody, false},
+		{"Persistent cookie", `{"email":"test@example.com","password":"password123","remember_me":true}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, "/auth/login", tt.body, nil, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Login() status = %d, want %d", w.Code, http.StatusOK)
+			}
+
+			refresh := findCookie(w, middleware.RefreshTokenCookie)
+			if refresh == nil {
+				t.Fatalf("Login() did not set the refresh token cookie")
+			}
+			if persistent := refresh.MaxAge > 0; persistent != tt.persistent {
+				t.Errorf("Login() refresh cookie Max-Age = %d, want persistent %v", refresh.MaxAge, tt.persistent)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Reauthentication(t *testing.T) {
+	router := setupAuthHandler(t, AuthHandlerConfig{})
+
+	accessToken, _ := decodeToken(t, serve(router, http.MethodPost, "/auth/login", loginBody, nil, ""))["access_token"].(string)
+	bearer := "Bearer " + accessToken
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"Change password with wrong password", http.MethodPut, "/auth/password", `{"current_password":"wrong","new_password":"new-password"}`, http.StatusForbidden, "INVALID_CURRENT_PASSWORD"},
+		{"Change password too short", http.MethodPut, "/auth/password", `{"current_password":"password123","new_password":"short"}`, http.StatusBadRequest, ""},
+		{"Change password to the same one", http.MethodPut, "/auth/password", `{"current_password":"password123","new_password":"password123"}`, http.StatusBadRequest, ""},
+		{"Delete account with wrong password", http.MethodDelete, "/auth/account", `{"current_password":"wrong"}`, http.StatusForbidden, "INVALID_CURRENT_PASSWORD"},
+		{"Delete account", http.MethodDelete, "/auth/account", `{"current_password":"password123"}`, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.target, tt.body, nil, bearer)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantCode != "" {
+				var body struct {
+					Code string `json:"code"`
+				}
+				json.Unmarshal(w.Body.Bytes(), &body)
+				if body.Code != tt.wantCode {
+					t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+				}
+			}
+		})
+	}
+}
+
+// The middleware and AuthService.ValidateToken share jwtutil, so they must reject the same tokens
+func TestTokenValidation_MiddlewareMatchesService(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	authService := services.NewAuthService(postgres.NewUserRepository(db), testSecret, time.Hour)
+	if _, err := authService.Register(context.Background(), &services.RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+	login, err := authService.Login(context.Background(), &services.LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to login test user: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/protected", middleware.JWTAuthWithConfig(middleware.JWTAuthConfig{Secret: testSecret, Leeway: jwtutil.DefaultLeeway}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	now := time.Now()
+	sign := func(method jwt.SigningMethod, key interface{}, subject string, expiresAt time.Time) string {
+		claims := &jwtutil.Claims{
+			UserID: login.User.ID,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(expiresAt),
+				IssuedAt:  jwt.NewNumericDate(now.Add(-2 * time.Hour)),
+				Subject:   subject,
+			},
+		}
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign test token: %v", err)
+		}
+		return signed
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"Issued access token", login.Token.AccessToken, true},
+		{"Refresh token", login.Token.RefreshToken, false},
+		{"Expired", sign(jwt.SigningMethodHS256, []byte(testSecret), jwtutil.SubjectAccess, now.Add(-time.Hour)), false},
+		{"Wrong secret", sign(jwt.SigningMethodHS256, []byte("another-secret"), jwtutil.SubjectAccess, now.Add(time.Hour)), false},
+		{"Unsigned", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwtutil.SubjectAccess, now.Add(time.Hour)), false},
+		{"RS256", sign(jwt.SigningMethodRS256, rsaKey, jwtutil.SubjectAccess, now.Add(time.Hour)), false},
+		{"Malformed", "not-a-token", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authService.ValidateToken(context.Background(), tt.token)
+			if (err == nil) != tt.valid {
+				t.Errorf("ValidateToken() error = %v, want valid %v", err, tt.valid)
+			}
+
+			w := serve(router, http.MethodGet, "/protected", "", nil, "Bearer "+tt.token)
+			if (w.Code == http.StatusOK) != tt.valid {
+				t.Errorf("JWTAuth status = %d, want valid %v", w.Code, tt.valid)
+			}
+		})
+	}
+}
+
+// linkMailer keeps the token of the last confirmation link sent
+type linkMailer struct {
+	token string
+}
+
+func (m *linkMailer) Send(ctx context.Context, to, subject, body string) error {
+	if match := regexp.MustCompile(`\?token=(\S+)`).FindStringSubmatch(body); match != nil {
+		m.token = match[1]
+	}
+	return nil
+}
+
+func TestAuthHandler_EmailChange(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	mailer := &linkMailer{}
+	authService := services.NewAuthServiceWithConfig(postgres.NewUserRepository(db), services.AuthConfig{
+		JWTSecret:      testSecret,
+		JWTExpiry:      time.Hour,
+		Mailer:         mailer,
+		EmailChangeURL: "https://example.com/confirm",
+	})
+	for _, email := range []string{"test@example.com", "taken@example.com"} {
+		if _, err := authService.Register(context.Background(), &services.RegisterRequest{
+			Email:     email,
+			Password:  "password123",
+			FirstName: "John",
+			LastName:  "Doe",
+		}); err != nil {
+			t.Fatalf("Failed to register test user: %v", err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandler(authService)
+	router := gin.New()
+	router.POST("/auth/login", handler.Login)
+	router.GET("/auth/confirm-email", handler.ConfirmEmailChange)
+	router.POST("/auth/change-email", middleware.JWTAuth(testSecret), handler.RequestEmailChange)
+
+	accessToken, _ := decodeToken(t, serve(router, http.MethodPost, "/auth/login", loginBody, nil, ""))["access_token"].(string)
+	bearer := "Bearer " + accessToken
+
+	steps := []struct {
+		name       string
+		method     string
+		target     func() string
+		body       string
+		wantStatus int
+	}{
+		{"Without password", http.MethodPost, func() string { return "/auth/change-email" }, `{"email":"new@example.com"}`, http.StatusBadRequest},
+		{"Wrong password", http.MethodPost, func() string { return "/auth/change-email" }, `{"email":"new@example.com","current_password":"wrong"}`, http.StatusForbidden},
+		{"Email taken", http.MethodPost, func() string { return "/auth/change-email" }, `{"email":"taken@example.com","current_password":"password123"}`, http.StatusConflict},
+		{"Same email", http.MethodPost, func() string { return "/auth/change-email" }, `{"email":"test@example.com","current_password":"password123"}`, http.StatusBadRequest},
+		{"Request", http.MethodPost, func() string { return "/auth/change-email" }, `{"email":"new@example.com","current_password":"password123"}`, http.StatusAccepted},
+		{"Missing token", http.MethodGet, func() string { return "/auth/confirm-email" }, "", http.StatusBadRequest},
+		{"Invalid token", http.MethodGet, func() string { return "/auth/confirm-email?token=not-a-token" }, "", http.StatusBadRequest},
+		{"Confirm", http.MethodGet, func() string { return "/auth/confirm-email?token=" + mailer.token }, "", http.StatusOK},
+		{"Confirm again", http.MethodGet, func() string { return "/auth/confirm-email?token=" + mailer.token }, "", http.StatusBadRequest},
+	}
+
+	for _, step := range steps {
+		w := serve(router, step.method, step.target(), step.body, nil, bearer)
+		if w.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d (%s)", step.name, w.Code, step.wantStatus, w.Body.String())
+		}
+	}
+
+	w := serve(router, http.MethodPost, "/auth/login", `{"email":"new@example.com","password":"password123"}`, nil, "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Login() with the confirmed email status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAuthHandler_VerifyEmail(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	mailer := &linkMailer{}
+	authService := services.NewAuthServiceWithConfig(postgres.NewUserRepository(db), services.AuthConfig{
+		JWTSecret:                testSecret,
+		JWTExpiry:                time.Hour,
+		Mailer:                   mailer,
+		EmailVerifyURL:           "https://example.com/verify",
+		RequireEmailVerification: true,
+	})
+	if _, err := authService.Register(context.Background(), &services.RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandler(authService)
+	router := gin.New()
+	router.POST("/auth/login", handler.Login)
+	router.GET("/auth/verify-email", handler.VerifyEmail)
+	router.POST("/auth/resend-verification", handler.ResendVerification)
+
+	steps := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{"Login before verification", http.MethodPost, "/auth/login", loginBody, http.StatusForbidden},
+		{"Resend without email", http.MethodPost, "/auth/resend-verification", `{}`, http.StatusBadRequest},
+		{"Resend", http.MethodPost, "/auth/resend-verification", `{"email":"test@example.com"}`, http.StatusAccepted},
+		{"Resend to unknown email", http.MethodPost, "/auth/resend-verification", `{"email":"nobody@example.com"}`, http.StatusAccepted},
+		{"Missing token", http.MethodGet, "/auth/verify-email", "", http.StatusBadRequest},
+		{"Invalid token", http.MethodGet, "/auth/verify-email?token=not-a-token", "", http.StatusBadRequest},
+		{"Verify", http.MethodGet, "/auth/verify-email?token=" + mailer.token, "", http.StatusOK},
+		{"Verify again", http.MethodGet, "/auth/verify-email?token=" + mailer.token, "", http.StatusOK},
+		{"Login after verification", http.MethodPost, "/auth/login", loginBody, http.StatusOK},
+	}
+
+	for _, step := range steps {
+		w := serve(router, step.method, step.target, step.body, nil, "")
+		if w.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d (%s)", step.name, w.Code, step.wantStatus, w.Body.String())
+		}
+	}
+}
+
+func TestAuthHandler_Login_Locked(t *testing.T) {
+	router := setupAuthHandler(t, AuthHandlerConfig{})
+
+	for i := 0; i < 3; i++ {
+		if w := serve(router, http.MethodPost, "/auth/login", `{"email":"test@example.com","password":"wrong-password"}`, nil, ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Login() failure %d status = %d, want %d", i+1, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	w := serve(router, http.MethodPost, "/auth/login", `{"email":"test@example.com","password":"password123"}`, nil, "")
+	if w.Code != http.StatusLocked {
+		t.Fatalf("Login() while locked status = %d, want %d", w.Code, http.StatusLocked)
+	}
+	if !strings.Contains(w.Body.String(), utils.CodeAccountLocked) {
+		t.Errorf("Login() while locked body = %s, want code %s", w.Body.String(), utils.CodeAccountLocked)
+	}
+}
+
+func TestAuthHandler_BindingErrors(t *testing.T) {
+	router := setupAuthHandler(t, AuthHandlerConfig{})
+
+	w := serve(router, http.MethodPost, "/auth/login", loginBody, nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Login() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	accessToken, _ := decodeToken(t, w)["access_token"].(string)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		header     string
+		wantErrors []utils.ErrorDetail
+	}{
+		{
+			name:   "Register without email",
+			method: http.MethodPost, target: "/auth/register",
+			body:       `{"password":"Correct7Horse","first_name":"Jane","last_name":"Doe"}`,
+			wantErrors: []utils.ErrorDetail{{Code: utils.CodeRequired, Field: "email"}},
+		},
+		{
+			name:   "Register with several invalid fields",
+			method: http.MethodPost, target: "/auth/register",
+			body: `{"email":"jane@example.com","password":"short","first_name":"J","last_name":"Doe"}`,
+			wantErrors: []utils.ErrorDetail{
+				{Code: utils.CodeTooShort, Field: "password"},
+				{Code: utils.CodeTooShort, Field: "first_name", Value: "J"},
+			},
+		},
+		{
+			name:   "Register with a whitespace-only first name",
+			method: http.MethodPost, target: "/auth/register",
+			body:       `{"email":"jane@example.com","password":"Correct7Horse","first_name":"   ","last_name":"Doe"}`,
+			wantErrors: []utils.ErrorDetail{{Code: utils.CodeRequired, Field: "first_name"}},
+		},
+		{
+			name:   "Login with an invalid email",
+			method: http.MethodPost, target: "/auth/login",
+			body:       `{"email":"not-an-email","password":"password123"}`,
+			wantErrors: []utils.ErrorDetail{{Code: utils.CodeInvalidEmail, Field: "email", Value: "not-an-email"}},
+		},
+		{
+			name:   "Login with a malformed body",
+			method: http.MethodPost, target: "/auth/login",
+			body:       `{"email":`,
+			wantErrors: []utils.ErrorDetail{{Code: utils.CodeMalformedBody}},
+		},
+		{
+			name:   "Update profile with a long last name",
+			method: http.MethodPut, target: "/auth/profile",
+			body:       `{"first_name":"Jane","last_name":"` + strings.Repeat("x", 51) + `"}`,
+			header:     "Bearer " + accessToken,
+			wantErrors: []utils.ErrorDetail{{Code: utils.CodeTooLong, Field: "last_name", Value: strings.Repeat("x", 51)}},
+		},
+		{
+			name:   "Update profile with a padded initial",
+			method: http.MethodPut, target: "/auth/profile",
+			body:       `{"first_name":" J ","last_name":"Doe"}`,
+			header:     "Bearer " + accessToken,
+			wantErrors: []utils.ErrorDetail{{Code: utils.CodeTooShort, Field: "first_name"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.target, tt.body, nil, tt.header)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, http.StatusBadRequest, w.Body.String())
+			}
+
+			var body struct {
+				Data utils.ValidationError `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response body %q: %v", w.Body.String(), err)
+			}
+			if len(body.Data.Error) != len(tt.wantErrors) {
+				t.Fatalf("errors = %+v, want %+v", body.Data.Error, tt.wantErrors)
+			}
+			for i, want := range tt.wantErrors {
+				got := body.Data.Error[i]
+				if got.Code != want.Code || got.Field != want.Field || got.Value != want.Value {
+					t.Errorf("errors[%d] = %+v, want %+v", i, got, want)
+				}
+				if got.Message == "" {
+					t.Errorf("errors[%d] has no message", i)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHandler_IntrospectToken(t *testing.T) {
+	router := setupAuthHandler(t, AuthHandlerConfig{})
+
+	w := serve(router, http.MethodPost, "/auth/login", loginBody, nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Login() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	token := decodeToken(t, w)
+	accessToken, _ := token["access_token"].(string)
+	refreshToken, _ := token["refresh_token"].(string)
+
+	now := time.Now()
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwtutil.Claims{
+		UserID: 1,
+		Email:  "test@example.com",
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(-time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now.Add(-2 * time.Hour)),
+			Subject:   jwtutil.SubjectAccess,
+		},
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign test token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantActive bool
+	}{
+		{"Active token", "Bearer " + accessToken, http.StatusOK, true},
+		{"Expired token", "Bearer " + expired, http.StatusOK, false},
+		{"Refresh token", "Bearer " + refreshToken, http.StatusUnauthorized, false},
+		{"Malformed token", "Bearer not-a-token", http.StatusUnauthorized, false},
+		{"Missing token", "", http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/auth/token/introspect", "", nil, tt.header)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("IntrospectToken() status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data services.TokenIntrospectionResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response body %q: %v", w.Body.String(), err)
+			}
+			got := body.Data
+			if got.Active != tt.wantActive {
+				t.Errorf("IntrospectToken() active = %v, want %v", got.Active, tt.wantActive)
+			}
+			if got.UserID != 1 || got.Email != "test@example.com" {
+				t.Errorf("IntrospectToken() user = %d %s, want 1 test@example.com", got.UserID, got.Email)
+			}
+			if got.IssuedAt.IsZero() || !got.ExpiresAt.After(got.IssuedAt) {
+				t.Errorf("IntrospectToken() issued_at = %v, expires_at = %v", got.IssuedAt, got.ExpiresAt)
+			}
+			if (got.ExpiresIn > 0) != tt.wantActive {
+				t.Errorf("IntrospectToken() expires_in = %d, want active %v", got.ExpiresIn, tt.wantActive)
+			}
+			if strings.Contains(w.Body.String(), accessToken) {
+				t.Errorf("IntrospectToken() echoed the token: %s", w.Body.String())
+			}
+		})
+	}
+}
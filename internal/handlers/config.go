@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"customable-corporate-site-api/internal/config"
+	"customable-corporate-site-api/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler handles runtime configuration HTTP requests.
+type ConfigHandler struct {
+	live *config.Live
+}
+
+// NewConfigHandler creates a new instance of ConfigHandler.
+func NewConfigHandler(live *config.Live) *ConfigHandler {
+	return &ConfigHandler{live: live}
+}
+
+// ConfigReloadResponse describes the configuration in effect after a reload
+type ConfigReloadResponse struct {
+	// Summary lists the effective settings, without secrets
+	Summary string `json:"summary"`
+}
+
+// Reload re-reads the reloadable settings and puts them in effect.
+// @Summary Reload the configuration
+// @Description Re-read the .env file and the environment and apply the settings that can change without a restart: MAINTENANCE_MODE, CORS_ALLOWED_ORIGINS and the LOGIN_THROTTLE_* limits. They take effect from the next request on. Every other setting, such as the database connection, needs a restart. A configuration that fails validation is rejected and the current one stays in effect.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ConfigReloadResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Failure 422 {object} services.ErrorResponse
+// @Router /api/v1/admin/reload-config [post]
+func (h *ConfigHandler) Reload(c *gin.Context) {
+	reloaded, err := h.live.Reload()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, "Configuration was not reloaded", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Configuration reloaded successfully", ConfigReloadResponse{Summary: reloaded.Summary()})
+}
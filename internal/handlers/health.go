@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/database/migrations"
+	"customable-corporate-site-api/internal/health"
+	"customable-corporate-site-api/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// healthPingTimeout bounds the database ping of the health check
+const healthPingTimeout = 2 * time.Second
+
+// HealthHandler handles health and readiness check HTTP requests.
+type HealthHandler struct {
+	registry *health.Registry
+	db       *gorm.DB
+}
+
+// NewHealthHandler creates a new instance of HealthHandler. The health check pings db; it may
+// be nil when there is no database to check.
+func NewHealthHandler(registry *health.Registry, db *gorm.DB) *HealthHandler {
+	return &HealthHandler{registry: registry, db: db}
+}
+
+// Health reports whether the API can reach its database.
+// @Summary Check health
+// @Description Ping the database and report healthy with its latency and the number of applied migrations, or unhealthy with 503 when it cannot be reached.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /api/v1/health [get]
+func (h *HealthHandler) Health(c *gin.Context) {
+	if h.db == nil {
+		utils.HealthCheckResponse(c, "healthy", nil)
+		return
+	}
+
+	details := gin.H{}
+	sqlDB, err := h.db.DB()
+	if err == nil {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), healthPingTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err = sqlDB.PingContext(ctx)
+		details["database_latency_ms"] = float64(time.Since(start)) / float64(time.Millisecond)
+	}
+	if err != nil {
+		details["database"] = "unreachable"
+		details["error"] = err.Error()
+		utils.HealthCheckResponse(c, "unhealthy", details)
+		return
+	}
+	details["database"] = "connected"
+
+	// The server may run without the migration tool having created its table
+	var applied int64
+	if err := h.db.WithContext(c.Request.Context()).Model(&migrations.Migration{}).Count(&applied).Error; err == nil {
+		details["migrations"] = applied
+	}
+
+	utils.HealthCheckResponse(c, "healthy", details)
+}
+
+// Ready reports whether the API and its dependencies can serve requests.
+// @Summary Check readiness
+// @Description Check every registered dependency and report each one's status and latency. The overall status is the worst of them: down when a critical dependency fails, answered with 503, and degraded when only a non-critical one does, still answered with 200.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /api/v1/health/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == health.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/database/migrations"
+	"customable-corporate-site-api/internal/health"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name       string
+		database   func(ctx context.Context) error
+		mail       func(ctx context.Context) error
+		wantCode   int
+		wantStatus string
+	}{
+		{"Healthy", up, up, http.StatusOK, health.StatusUp},
+		{"Mail down", up, down, http.StatusOK, health.StatusDegraded},
+		{"Database down", down, up, http.StatusServiceUnavailable, health.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := health.NewRegistry(0)
+			registry.Register(health.NewChecker("database", tt.database), true)
+			registry.Register(health.NewChecker("mail", tt.mail), false)
+
+			router := gin.New()
+			router.GET("/health/ready", NewHealthHandler(registry, nil).Ready)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			var report health.Report
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if report.Status != tt.wantStatus || len(report.Checks) != 2 {
+				t.Errorf("report = %+v, want status %q with both checks", report, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHealthHandler_Health(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&migrations.Migration{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	db.Create(&migrations.Migration{Version: "001", Description: "create users table", ExecutedAt: time.Now()})
+
+	router := gin.New()
+	router.GET("/health", NewHealthHandler(health.NewRegistry(0), db).Health)
+	check := func() (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var body struct {
+			Status  string                 `json:"status"`
+			Details map[string]interface{} `json:"details"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode response %q: %v", w.Body.String(), err)
+		}
+		body.Details["status"] = body.Status
+		return w.Code, body.Details
+	}
+
+	code, details := check()
+	if code != http.StatusOK || details["status"] != "healthy" {
+		t.Fatalf("health = %d %v, want 200 healthy", code, details)
+	}
+	if _, ok := details["database_latency_ms"]; !ok || details["migrations"] != float64(1) {
+		t.Errorf("details = %v, want the database latency and 1 migration", details)
+	}
+
+	// A closed handle cannot be pinged
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+
+	code, details = check()
+	if code != http.StatusServiceUnavailable || details["status"] != "unhealthy" {
+		t.Errorf("health with a closed database = %d %v, want 503 unhealthy", code, details)
+	}
+}
@@ -0,0 +1,688 @@
+package handlers
+
+import (
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/services"
+	"customable-corporate-site-api/internal/utils"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler handles admin user management HTTP requests.
+type UserHandler struct {
+	userService *services.UserService
+}
+
+// userResponseFields are the fields of a user that clients may select with ?fields=
+var userResponseFields = utils.FieldsOf(models.UserResponse{})
+
+// userPageConfig sets the sort fields of user lists; they are newest first by default
+var userPageConfig = utils.PageConfig{
+	SortFields:   []string{"created_at", "updated_at", "email", "first_name", "last_name"},
+	DefaultSort:  "created_at",
+	DefaultOrder: utils.OrderDesc,
+}
+
+// deletedUserPageConfig sets the sort fields of the deleted user list; it is most recently
+// deleted first by default
+var deletedUserPageConfig = utils.PageConfig{
+	SortFields:   append([]string{"deleted_at"}, userPageConfig.SortFields...),
+	DefaultSort:  "deleted_at",
+	DefaultOrder: utils.OrderDesc,
+}
+
+// userExportBatchSize is how many users ExportUsers loads at a time
+const userExportBatchSize = 500
+
+// userCSVHeader is the header row of user exports
+var userCSVHeader = []string{"id", "email", "first_name", "last_name", "role", "is_active", "created_at"}
+
+// NewUserHandler creates a new instance of UserHandler.
+func NewUserHandler(userService *services.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// ListUsers handles listing users as admin.
+// @Summary List users
+// @Description Get a page of users, newest first unless sorted otherwise, optionally only those with a role. With cursor, pages by ID instead: pass an empty cursor for the first page, then the next_cursor of each response; cursor pages cannot be combined with role.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param role query string false "Role" Enums(admin, editor, user)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param sort query string false "Sort field" Enums(created_at, updated_at, email, first_name, last_name)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param cursor query string false "Cursor of the page, selecting cursor pagination"
+// @Param limit query int false "Cursor page size" default(10)
+// @Param fields query string false "Comma-separated fields to return, e.g. id,email,role"
+// @Success 200 {object} utils.PaginationResponse{data=[]models.UserResponse}
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	if _, ok := c.GetQuery("cursor"); ok {
+		h.listUsersAfter(c)
+		return
+	}
+
+	page, ok := utils.BindSortedPageRequest(c, userPageConfig)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userService.ListUsers(c.Request.Context(), c.Query("role"), page.Offset(), page.PageSize, page.OrderClause())
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve users", err)
+		return
+	}
+
+	h.respondUsers(c, users, total, page)
+}
+
+// listUsersAfter serves the cursor mode of ListUsers
+func (h *UserHandler) listUsersAfter(c *gin.Context) {
+	if c.Query("role") != "" {
+		utils.BadRequestResponse(c, "role cannot be combined with cursor", nil)
+		return
+	}
+
+	req, ok := utils.BindCursorRequest(c, userPageConfig)
+	if !ok {
+		return
+	}
+
+	users, next, err := h.userService.ListUsersAfter(c.Request.Context(), req.Cursor, req.Limit)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve users", err)
+		return
+	}
+
+	data, ok := utils.SelectFields(c, users, userResponseFields)
+	if !ok {
+		return
+	}
+	utils.RespondCursor(c, data, next, req)
+}
+
+// SearchUsers handles searching users by name or email as admin.
+// @Summary Search users
+// @Description Get a page of the users whose first name, last name or email contains the query, ignoring case.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search query"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param sort query string false "Sort field" Enums(created_at, updated_at, email, first_name, last_name)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param fields query string false "Comma-separated fields to return, e.g. id,email,role"
+// @Success 200 {object} utils.PaginationResponse{data=[]models.UserResponse}
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/users/search [get]
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	page, ok := utils.BindSortedPageRequest(c, userPageConfig)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userService.SearchUsers(c.Request.Context(), c.Query("q"), page.Offset(), page.PageSize, page.OrderClause())
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to search users", err)
+		return
+	}
+
+	h.respondUsers(c, users, total, page)
+}
+
+// GetUser handles fetching a single user as admin.
+// @Summary Get a user
+// @Description Get the details of a user by ID.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,email,role"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/v1/users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUser(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			utils.NotFoundResponse(c, "User")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve user", err)
+		return
+	}
+
+	data, ok := utils.SelectFields(c, user, userResponseFields)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User retrieved successfully", data)
+}
+
+// ListActiveUsers handles listing active users as admin.
+// @Summary List active users
+// @Description Get a page of active users, newest first unless sorted otherwise.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param sort query string false "Sort field" Enums(created_at, updated_at, email, first_name, last_name)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param fields query string false "Comma-separated fields to return, e.g. id,email,role"
+// @Success 200 {object} utils.PaginationResponse{data=[]models.UserResponse}
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/users/active [get]
+func (h *UserHandler) ListActiveUsers(c *gin.Context) {
+	page, ok := utils.BindSortedPageRequest(c, userPageConfig)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userService.ListActiveUsers(c.Request.Context(), page.Offset(), page.PageSize, page.OrderClause())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve users", err)
+		return
+	}
+
+	h.respondUsers(c, users, total, page)
+}
+
+// ListUsersByRole handles listing the users with a role as admin.
+// @Summary List users by role
+// @Description Get a page of the users with a role, newest first unless sorted otherwise.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role" Enums(admin, editor, user)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param sort query string false "Sort field" Enums(created_at, updated_at, email, first_name, last_name)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param fields query string false "Comma-separated fields to return, e.g. id,email,role"
+// @Success 200 {object} utils.PaginationResponse{data=[]models.UserResponse}
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/users/role/{role} [get]
+func (h *UserHandler) ListUsersByRole(c *gin.Context) {
+	page, ok := utils.BindSortedPageRequest(c, userPageConfig)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userService.ListUsersByRole(c.Request.Context(), c.Param("role"), page.Offset(), page.PageSize, page.OrderClause())
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve users", err)
+		return
+	}
+
+	h.respondUsers(c, users, total, page)
+}
+
+// UpdateUser handles updating another user's details as admin.
+// @Summary Update a user
+// @Description Replace the email, name, role and active status of a user. Admins cannot change their own role or deactivate their own account.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param adminUpdateUserRequest body services.AdminUpdateUserRequest true "Update User Request"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Router /api/v1/users/{id} [put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	actorID, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	targetID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.AdminUpdateUserRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	// Record the client on the audit log
+	req.IPAddress = c.ClientIP()
+
+	user, err := h.userService.UpdateUser(c.Request.Context(), actorID, targetID, &req)
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			utils.NotFoundResponse(c, "User")
+		case errors.Is(err, services.ErrEmailTaken):
+			utils.ConflictResponse(c, "Email is already in use", err)
+		case errors.Is(err, services.ErrCannotChangeOwnRole), errors.Is(err, services.ErrCannotDeactivateSelf):
+			utils.ForbiddenResponse(c, err.Error())
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to update user", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User updated successfully", user)
+}
+
+// UpdateUserRole handles changing a user's role as admin.
+// @Summary Change a user's role
+// @Description Change the role of a user and invalidate the tokens issued with the old one. Admins cannot change their own role.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param updateUserRoleRequest body services.UpdateUserRoleRequest true "Update User Role Request"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/v1/users/{id}/role [patch]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	actorID, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	targetID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.UpdateUserRoleRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	// Record the client on the audit log
+	req.IPAddress = c.ClientIP()
+
+	user, err := h.userService.UpdateUserRole(c.Request.Context(), actorID, targetID, &req)
+	if err != nil {
+		if fieldErrorResponse(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			utils.NotFoundResponse(c, "User")
+		case errors.Is(err, services.ErrCannotChangeOwnRole):
+			utils.ForbiddenResponse(c, err.Error())
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to update user", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User role updated successfully", user)
+}
+
+// UpdateUserStatus handles activating or deactivating a user as admin.
+// @Summary Change a user's active status
+// @Description Activate or deactivate a user. Deactivating a user invalidates their tokens. Admins cannot deactivate their own account.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param updateUserStatusRequest body services.UpdateUserStatusRequest true "Update User Status Request"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/v1/users/{id}/status [patch]
+func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
+	actorID, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	targetID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req services.UpdateUserStatusRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	// Record the client on the audit log
+	req.IPAddress = c.ClientIP()
+
+	user, err := h.userService.UpdateUserStatus(c.Request.Context(), actorID, targetID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			utils.NotFoundResponse(c, "User")
+		case errors.Is(err, services.ErrCannotDeactivateSelf):
+			utils.ForbiddenResponse(c, err.Error())
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to update user", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User status updated successfully", user)
+}
+
+// RevokeTokens handles logging a user out everywhere as admin.
+// @Summary Revoke a user's tokens
+// @Description End all sessions of a user and invalidate every access and refresh token issued to them, e.g. after their account was compromised.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} services.RevokeTokensResult
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/v1/users/{id}/revoke-tokens [post]
+func (h *UserHandler) RevokeTokens(c *gin.Context) {
+	actorID, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	targetID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.userService.RevokeTokens(c.Request.Context(), actorID, targetID, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			utils.NotFoundResponse(c, "User")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to revoke tokens", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tokens revoked successfully", result)
+}
+
+// ListDeletedUsers handles listing soft-deleted users as admin.
+// @Summary List deleted users
+// @Description Get a page of the users that were deleted but not yet purged, most recently deleted first unless sorted otherwise. They can be restored.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param sort query string false "Sort field" Enums(deleted_at, created_at, updated_at, email, first_name, last_name)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param fields query string false "Comma-separated fields to return, e.g. id,email,role"
+// @Success 200 {object} utils.PaginationResponse{data=[]models.UserResponse}
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/users/deleted [get]
+func (h *UserHandler) ListDeletedUsers(c *gin.Context) {
+	page, ok := utils.BindSortedPageRequest(c, deletedUserPageConfig)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userService.ListDeletedUsers(c.Request.Context(), page.Offset(), page.PageSize, page.OrderClause())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve users", err)
+		return
+	}
+
+	h.respondUsers(c, users, total, page)
+}
+
+// RestoreUser handles undoing the deletion of a user as admin.
+// @Summary Restore a deleted user
+// @Description Restore a user that was deleted but not yet purged. The user can log in again with their previous credentials.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/v1/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	actorID, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	targetID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.RestoreUser(c.Request.Context(), actorID, targetID, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			utils.NotFoundResponse(c, "User")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to restore user", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User restored successfully", user)
+}
+
+// BulkDeleteUsers handles deleting several users as admin, in two steps.
+// @Summary Delete users in bulk
+// @Description Soft-delete several users. The first request, without confirm, deletes nothing and returns a short-lived confirmation token; repeat it with the same ids and the token in confirm to delete. The acting admin and the last admin are skipped.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bulkDeleteUsersRequest body services.BulkDeleteUsersRequest true "Bulk Delete Users Request"
+// @Success 200 {array} services.BulkDeleteResult
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 409 {object} services.ErrorResponse
+// @Router /api/v1/users/bulk [delete]
+func (h *UserHandler) BulkDeleteUsers(c *gin.Context) {
+	actorID, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.BulkDeleteUsersRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	// First step: issue the confirmation token without deleting anything
+	if req.Confirm == "" {
+		confirmation, err := h.userService.RequestBulkDelete(c.Request.Context(), actorID, req.IDs)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to issue confirmation token", err)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "Repeat the request with the confirm token to delete these users", confirmation)
+		return
+	}
+
+	// Record the client on the audit log
+	req.IPAddress = c.ClientIP()
+
+	results, err := h.userService.BulkDeleteUsers(c.Request.Context(), actorID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidConfirmation) {
+			utils.ConflictResponse(c, "Confirmation token is invalid or expired", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to delete users", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Users deleted", results)
+}
+
+// BulkUpdateUserStatus handles activating or deactivating several users as admin.
+// @Summary Update the status of users in bulk
+// @Description Activate or deactivate several users at once. Deactivated users are logged out everywhere. The acting admin cannot deactivate themselves. The response counts the users whose status changed.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bulkUpdateUserStatusRequest body services.BulkUpdateUserStatusRequest true "Bulk Update User Status Request"
+// @Success 200 {object} services.BulkUpdateUserStatusResult
+// @Failure 400 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/users/bulk/status [patch]
+func (h *UserHandler) BulkUpdateUserStatus(c *gin.Context) {
+	actorID, ok := CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req services.BulkUpdateUserStatusRequest
+
+	// Bind and validate request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", utils.ValidationErrorDetails(err))
+		return
+	}
+
+	// Record the client on the audit log
+	req.IPAddress = c.ClientIP()
+
+	result, err := h.userService.BulkUpdateUserStatus(c.Request.Context(), actorID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrCannotDeactivateSelf) {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update users", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User status updated", result)
+}
+
+// ExportUsers handles downloading every user as CSV as admin.
+// @Summary Export users
+// @Description Download every user, in ID order, as CSV. Users are read in batches and streamed, so the export works on any number of users.
+// @Tags Users
+// @Produce text/csv
+// @Security BearerAuth
+// @Success 200 {string} string "CSV with the columns id, email, first_name, last_name, role, is_active, created_at"
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 403 {object} services.ErrorResponse
+// @Router /api/v1/users/export [get]
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// The first batch is loaded before any output, so a failure can still be answered 500
+	users, next, err := h.userService.ListUsersAfter(ctx, 0, userExportBatchSize)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to export users", err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="users.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(userCSVHeader)
+	for {
+		for _, user := range users {
+			w.Write(userCSVRow(user))
+		}
+		w.Flush()
+		if next == 0 {
+			return
+		}
+
+		users, next, err = h.userService.ListUsersAfter(ctx, next, userExportBatchSize)
+		if err != nil {
+			// The status is sent already; stopping short leaves the download truncated
+			c.Error(err)
+			return
+		}
+	}
+}
+
+// userCSVRow formats a user for export, escaping text that spreadsheets would run as a formula
+func userCSVRow(user *models.UserResponse) []string {
+	return escapeCSVFormulas([]string{
+		strconv.FormatUint(uint64(user.ID), 10),
+		user.Email,
+		user.FirstName,
+		user.LastName,
+		user.Role,
+		strconv.FormatBool(user.IsActive),
+		user.CreatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// respondUsers sends a page of users, trimmed to the fields selected with ?fields=
+func (h *UserHandler) respondUsers(c *gin.Context, users []*models.UserResponse, total int64, page utils.PageRequest) {
+	data, ok := utils.SelectFields(c, users, userResponseFields)
+	if !ok {
+		return
+	}
+
+	utils.RespondPaged(c, data, total, page)
+}
+
+// userIDParam parses the :id path parameter, responding with 400 when it is not a valid ID.
+func userIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,597 @@
+package handlers
+
+import (
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"customable-corporate-site-api/internal/services"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupUserHandler routes requests as the given admin, standing in for the JWT middleware
+func setupUserHandler(t *testing.T) (*gin.Engine, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	for _, user := range []*models.User{
+		{Email: "admin@example.com", Password: "password123", FirstName: "Ada", LastName: "Admin", Role: models.RoleAdmin},
+		{Email: "user@example.com", Password: "password123", FirstName: "John", LastName: "Doe", Role: models.RoleUser},
+	} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	handler := NewUserHandler(services.NewUserService(postgres.NewUserRepository(db), postgres.NewAuditLogRepository(db)))
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		c.Set("user_role", models.RoleAdmin)
+	})
+	router.GET("/users", handler.ListUsers)
+	router.GET("/users/search", handler.SearchUsers)
+	router.GET("/users/active", handler.ListActiveUsers)
+	router.GET("/users/role/:role", handler.ListUsersByRole)
+	router.GET("/users/deleted", handler.ListDeletedUsers)
+	router.GET("/users/export", handler.ExportUsers)
+	router.GET("/users/:id", handler.GetUser)
+	router.PUT("/users/:id", handler.UpdateUser)
+	router.PATCH("/users/:id/role", handler.UpdateUserRole)
+	router.PATCH("/users/:id/status", handler.UpdateUserStatus)
+	router.DELETE("/users/bulk", handler.BulkDeleteUsers)
+	router.PATCH("/users/bulk/status", handler.BulkUpdateUserStatus)
+	router.POST("/users/:id/restore", handler.RestoreUser)
+	return router, db
+}
+
+func TestUserHandler_UpdateUser(t *testing.T) {
+	router, db := setupUserHandler(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Update user",
+			target:     "/users/2",
+			body:       `{"email":"john@example.com","first_name":"John","last_name":"Doe","role":"editor","is_active":false}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Invalid role",
+			target:     "/users/2",
+			body:       `{"email":"john@example.com","first_name":"John","last_name":"Doe","role":"superuser","is_active":true}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Missing is_active",
+			target:     "/users/2",
+			body:       `{"email":"john@example.com","first_name":"John","last_name":"Doe","role":"user"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Email taken",
+			target:     "/users/2",
+			body:       `{"email":"admin@example.com","first_name":"John","last_name":"Doe","role":"user","is_active":true}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Own admin role",
+			target:     "/users/1",
+			body:       `{"email":"admin@example.com","first_name":"Ada","last_name":"Admin","role":"user","is_active":true}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Own account deactivated",
+			target:     "/users/1",
+			body:       `{"email":"admin@example.com","first_name":"Ada","last_name":"Admin","role":"admin","is_active":false}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Not found",
+			target:     "/users/99",
+			body:       `{"email":"ghost@example.com","first_name":"John","last_name":"Doe","role":"user","is_active":true}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Malformed id",
+			target:     "/users/abc",
+			body:       `{"email":"john@example.com","first_name":"John","last_name":"Doe","role":"user","is_active":true}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPut, tt.target, tt.body, nil, "")
+			if w.Code != tt.wantStatus {
+				t.Errorf("UpdateUser() status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	var user models.User
+	db.First(&user, 2)
+	if user.Role != models.RoleEditor || user.IsActive {
+		t.Errorf("UpdateUser() stored user = %+v, want inactive editor", user)
+	}
+}
+
+func TestUserHandler_UpdateUserRoleAndStatus(t *testing.T) {
+	router, db := setupUserHandler(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{"Change role", "/users/2/role", `{"role":"editor"}`, http.StatusOK},
+		{"Unknown role", "/users/2/role", `{"role":"superuser"}`, http.StatusBadRequest},
+		{"Role in the wrong case", "/users/2/role", `{"role":"Admin"}`, http.StatusBadRequest},
+		{"Missing role", "/users/2/role", `{}`, http.StatusBadRequest},
+		{"Own role", "/users/1/role", `{"role":"user"}`, http.StatusForbidden},
+		{"Own role unchanged", "/users/1/role", `{"role":"admin"}`, http.StatusOK},
+		{"Role of unknown user", "/users/99/role", `{"role":"user"}`, http.StatusNotFound},
+		{"Deactivate", "/users/2/status", `{"is_active":false}`, http.StatusOK},
+		{"Missing status", "/users/2/status", `{}`, http.StatusBadRequest},
+		{"Deactivate own account", "/users/1/status", `{"is_active":false}`, http.StatusForbidden},
+		{"Status of unknown user", "/users/99/status", `{"is_active":true}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPatch, tt.target, tt.body, nil, "")
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	var admin, user models.User
+	db.First(&admin, 1)
+	db.First(&user, 2)
+	if admin.Role != models.RoleAdmin || !admin.IsActive {
+		t.Errorf("admin = %+v, want an active admin", admin)
+	}
+	if user.Role != models.RoleEditor || user.IsActive || user.TokenVersion != 2 {
+		t.Errorf("user = role %s, active %v, token version %d, want an inactive editor with token version 2", user.Role, user.IsActive, user.TokenVersion)
+	}
+
+	var audits int64
+	db.Model(&models.AuditLog{}).Where("target_id = ?", 2).Count(&audits)
+	if audits != 2 {
+		t.Errorf("audit entries = %d, want 2", audits)
+	}
+}
+
+func TestUserHandler_RestoreUser(t *testing.T) {
+	router, db := setupUserHandler(t)
+	if err := db.Delete(&models.User{}, 2).Error; err != nil {
+		t.Fatalf("Failed to delete test user: %v", err)
+	}
+
+	w := serve(router, http.MethodGet, "/users/deleted", "", nil, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "user@example.com") || strings.Contains(w.Body.String(), "admin@example.com") {
+		t.Fatalf("GET /users/deleted = %d %s, want only the deleted user", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"Deleted user", "/users/2/restore", http.StatusOK},
+		{"Already restored", "/users/2/restore", http.StatusNotFound},
+		{"Active user", "/users/1/restore", http.StatusNotFound},
+		{"Unknown user", "/users/99/restore", http.StatusNotFound},
+		{"Invalid ID", "/users/abc/restore", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, tt.target, "", nil, "")
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	if w := serve(router, http.MethodGet, "/users/2", "", nil, ""); w.Code != http.StatusOK {
+		t.Errorf("GET /users/2 after restore status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var audits int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND target_id = ?", models.AuditActionUserRestored, 2).Count(&audits)
+	if audits != 1 {
+		t.Errorf("audit entries = %d, want 1", audits)
+	}
+}
+
+func TestUserHandler_GetUser(t *testing.T) {
+	router, _ := setupUserHandler(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantEmail  string
+	}{
+		{"Existing user", "/users/2", http.StatusOK, "user@example.com"},
+		{"Not found", "/users/99", http.StatusNotFound, ""},
+		{"Malformed id", "/users/abc", http.StatusBadRequest, ""},
+		{"Negative id", "/users/-1", http.StatusBadRequest, ""},
+		{"Zero id", "/users/0", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target, "", nil, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetUser() status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantEmail != "" {
+				var body struct {
+					Data models.UserResponse `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("Failed to decode response body: %v", err)
+				}
+				if body.Data.Email != tt.wantEmail {
+					t.Errorf("GetUser() email = %q, want %q", body.Data.Email, tt.wantEmail)
+				}
+			}
+		})
+	}
+}
+
+func TestUserHandler_GetUser_Fields(t *testing.T) {
+	router, _ := setupUserHandler(t)
+
+	w := serve(router, http.MethodGet, "/users/2?fields=id,email,role,password", "", nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetUser() status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if len(body.Data) != 3 || body.Data["email"] != "user@example.com" || body.Data["role"] != models.RoleUser {
+		t.Errorf("GetUser() data = %v, want only id, email and role", body.Data)
+	}
+}
+
+func TestUserHandler_ListUsers(t *testing.T) {
+	router, db := setupUserHandler(t)
+	for _, user := range []*models.User{
+		{Email: "editor@example.com", Password: "password123", FirstName: "Eve", LastName: "Editor", Role: models.RoleEditor},
+		{Email: "jane@example.com", Password: "password123", FirstName: "Jane", LastName: "Doe", Role: models.RoleUser},
+		{Email: "inactive@example.com", Password: "password123", FirstName: "Ian", LastName: "Inactive", Role: models.RoleUser},
+	} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+	}
+	if err := db.Model(&models.User{}).Where("email = ?", "inactive@example.com").Update("is_active", false).Error; err != nil {
+		t.Fatalf("Failed to deactivate test user: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantTotal  int
+		wantCount  int
+	}{
+		{"Active users", "/users/active", http.StatusOK, 4, 4},
+		{"Active users, second page", "/users/active?page=2&page_size=3", http.StatusOK, 4, 1},
+		{"Users by role", "/users/role/user", http.StatusOK, 3, 3},
+		{"Users by role, first page", "/users/role/user?page_size=2", http.StatusOK, 3, 2},
+		{"Users by role, page past the end", "/users/role/editor?page=2", http.StatusOK, 1, 0},
+		{"Unknown role", "/users/role/superuser", http.StatusBadRequest, 0, 0},
+		{"Role in the wrong case", "/users/role/Admin", http.StatusBadRequest, 0, 0},
+		{"All users", "/users", http.StatusOK, 5, 5},
+		{"All users, second page", "/users?page=2&page_size=2", http.StatusOK, 5, 2},
+		{"All users with a role", "/users?role=user", http.StatusOK, 3, 3},
+		{"All users with an unknown role", "/users?role=superuser", http.StatusBadRequest, 0, 0},
+		{"Search by name", "/users/search?q=doe", http.StatusOK, 2, 2},
+		{"Search ignores case", "/users/search?q=EDITOR@", http.StatusOK, 1, 1},
+		{"Search, first page", "/users/search?q=example.com&page_size=2", http.StatusOK, 5, 2},
+		{"Search without matches", "/users/search?q=nobody", http.StatusOK, 0, 0},
+		{"Search without a query", "/users/search?q=+", http.StatusBadRequest, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target, "", nil, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data       []models.UserResponse `json:"data"`
+				Pagination struct {
+					TotalItems int `json:"total_items"`
+				} `json:"pagination"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response body: %v", err)
+			}
+			if body.Pagination.TotalItems != tt.wantTotal || len(body.Data) != tt.wantCount {
+				t.Errorf("got %d users of %d, want %d of %d", len(body.Data), body.Pagination.TotalItems, tt.wantCount, tt.wantTotal)
+			}
+			for _, user := range body.Data {
+				if !user.IsActive && strings.HasSuffix(tt.target, "active") {
+					t.Errorf("active users include inactive user %s", user.Email)
+				}
+			}
+		})
+	}
+}
+
+func TestUserHandler_ListUsers_Cursor(t *testing.T) {
+	router, db := setupUserHandler(t)
+	if err := db.Create(&models.User{Email: "jane@example.com", Password: "password123", FirstName: "Jane", LastName: "Doe", Role: models.RoleUser}).Error; err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	type page struct {
+		Data   []models.UserResponse `json:"data"`
+		Cursor struct {
+			NextCursor uint `json:"next_cursor"`
+		} `json:"cursor"`
+	}
+	get := func(target string) page {
+		t.Helper()
+		w := serve(router, http.MethodGet, target, "", nil, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want %d (%s)", target, w.Code, http.StatusOK, w.Body.String())
+		}
+		var body page
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode response body: %v", err)
+		}
+		return body
+	}
+
+	first := get("/users?cursor=&limit=2")
+	if len(first.Data) != 2 || first.Data[0].ID != 1 || first.Cursor.NextCursor != 2 {
+		t.Fatalf("first page = %d users, next cursor %d, want users 1 and 2, next cursor 2", len(first.Data), first.Cursor.NextCursor)
+	}
+	second := get("/users?cursor=2&limit=2")
+	if len(second.Data) != 1 || second.Data[0].ID != 3 || second.Cursor.NextCursor != 0 {
+		t.Errorf("second page = %d users, next cursor %d, want user 3 and no next cursor", len(second.Data), second.Cursor.NextCursor)
+	}
+
+	for _, target := range []string{"/users?cursor=abc", "/users?cursor=-1", "/users?cursor=&role=user"} {
+		if w := serve(router, http.MethodGet, target, "", nil, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want %d", target, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestUserHandler_ListUsers_Sort(t *testing.T) {
+	router, _ := setupUserHandler(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantEmails []string
+	}{
+		{"Newest first by default", "/users/active", http.StatusOK, []string{"user@example.com", "admin@example.com"}},
+		{"By email", "/users/active?sort=email&order=asc", http.StatusOK, []string{"admin@example.com", "user@example.com"}},
+		{"By email, descending", "/users/active?sort=email", http.StatusOK, []string{"user@example.com", "admin@example.com"}},
+		{"Disallowed field", "/users/active?sort=password", http.StatusBadRequest, nil},
+		{"Disallowed field by role", "/users/role/user?sort=role", http.StatusBadRequest, nil},
+		{"Invalid order", "/users/active?order=random", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target, "", nil, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data []models.UserResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response body: %v", err)
+			}
+			var emails []string
+			for _, user := range body.Data {
+				emails = append(emails, user.Email)
+			}
+			if strings.Join(emails, ",") != strings.Join(tt.wantEmails, ",") {
+				t.Errorf("emails = %v, want %v", emails, tt.wantEmails)
+			}
+		})
+	}
+}
+
+func TestUserHandler_BulkUpdateUserStatus(t *testing.T) {
+	router, db := setupUserHandler(t)
+	if err := db.Create(&models.User{Email: "jane@example.com", Password: "password123", FirstName: "Jane", LastName: "Doe", Role: models.RoleUser}).Error; err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantUpdated int64
+	}{
+		{"Empty ID list", `{"ids":[],"is_active":false}`, http.StatusBadRequest, 0},
+		{"Invalid ID", `{"ids":[0],"is_active":false}`, http.StatusBadRequest, 0},
+		{"Missing is_active", `{"ids":[2]}`, http.StatusBadRequest, 0},
+		{"Deactivate self", `{"ids":[1,2],"is_active":false}`, http.StatusForbidden, 0},
+		{"Deactivate users", `{"ids":[2,3,99],"is_active":false}`, http.StatusOK, 2},
+		{"Already inactive", `{"ids":[2,2],"is_active":false}`, http.StatusOK, 0},
+		{"Activate users including self", `{"ids":[1,2],"is_active":true}`, http.StatusOK, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPatch, "/users/bulk/status", tt.body, nil, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data services.BulkUpdateUserStatusResult `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response body: %v", err)
+			}
+			if body.Data.Updated != tt.wantUpdated {
+				t.Errorf("updated = %d, want %d", body.Data.Updated, tt.wantUpdated)
+			}
+		})
+	}
+
+	var users []models.User
+	db.Order("id").Find(&users)
+	if !users[0].IsActive || !users[1].IsActive || users[2].IsActive {
+		t.Errorf("active = %v, %v, %v, want true, true, false", users[0].IsActive, users[1].IsActive, users[2].IsActive)
+	}
+	if users[0].TokenVersion != 0 || users[2].TokenVersion != 1 {
+		t.Errorf("token versions = %d and %d, want the admin's untouched and the deactivated user's incremented", users[0].TokenVersion, users[2].TokenVersion)
+	}
+
+	var audits int64
+	db.Model(&models.AuditLog{}).Where("action = ?", models.AuditActionUsersStatusUpdated).Count(&audits)
+	if audits != 3 {
+		t.Errorf("audit entries = %d, want 3", audits)
+	}
+}
+
+func TestUserHandler_ExportUsers(t *testing.T) {
+	router, db := setupUserHandler(t)
+	for _, user := range []*models.User{
+		{Email: "jane@example.com", Password: "password123", FirstName: "=Jane", LastName: "Doe", Role: models.RoleEditor},
+		{Email: "gone@example.com", Password: "password123", FirstName: "Gone", LastName: "User", Role: models.RoleUser},
+	} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+	}
+	if err := db.Delete(&models.User{}, 4).Error; err != nil {
+		t.Fatalf("Failed to delete test user: %v", err)
+	}
+
+	w := serve(router, http.MethodGet, "/users/export", "", nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("ExportUsers() status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("ExportUsers() Content-Type = %q, want text/csv", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") {
+		t.Errorf("ExportUsers() Content-Disposition = %q, want an attachment", cd)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(rows) == 0 || strings.Join(rows[0], ",") != "id,email,first_name,last_name,role,is_active,created_at" {
+		t.Fatalf("ExportUsers() header = %v, want the user columns", rows)
+	}
+
+	var seeded int64
+	db.Model(&models.User{}).Count(&seeded)
+	if int64(len(rows)-1) != seeded {
+		t.Fatalf("ExportUsers() data rows = %d, want %d", len(rows)-1, seeded)
+	}
+	if rows[3][1] != "jane@example.com" || rows[3][2] != "'=Jane" || rows[3][4] != models.RoleEditor || rows[3][5] != "true" {
+		t.Errorf("ExportUsers() row = %v, want jane with the formula escaped", rows[3])
+	}
+
+	var user models.User
+	db.First(&user, 1)
+	if strings.Contains(w.Body.String(), user.Password) {
+		t.Errorf("ExportUsers() body contains the password hash")
+	}
+}
+
+func TestUserHandler_BulkDeleteUsers(t *testing.T) {
+	router, db := setupUserHandler(t)
+
+	// Invalid requests are rejected before any token is issued
+	for _, body := range []string{`{"ids":[]}`, `{"ids":[0]}`, `{}`} {
+		if w := serve(router, http.MethodDelete, "/users/bulk", body, nil, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("BulkDeleteUsers(%s) status = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	// The first step only issues a confirmation token
+	w := serve(router, http.MethodDelete, "/users/bulk", `{"ids":[1,2]}`, nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("BulkDeleteUsers() status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var confirmation struct {
+		Data services.BulkDeleteConfirmation `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &confirmation); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if confirmation.Data.Confirm == "" {
+		t.Fatalf("BulkDeleteUsers() confirm is empty, want a token")
+	}
+
+	var count int64
+	db.Model(&models.User{}).Count(&count)
+	if count != 2 {
+		t.Fatalf("BulkDeleteUsers() without confirm left %d users, want 2", count)
+	}
+
+	// A token for other users is refused
+	if w := serve(router, http.MethodDelete, "/users/bulk", `{"ids":[2],"confirm":"`+confirmation.Data.Confirm+`"}`, nil, ""); w.Code != http.StatusConflict {
+		t.Errorf("BulkDeleteUsers() with mismatched ids status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	// Confirming deletes the other user and skips the acting admin
+	w = serve(router, http.MethodDelete, "/users/bulk", `{"ids":[1,2],"confirm":"`+confirmation.Data.Confirm+`"}`, nil, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("BulkDeleteUsers() confirmed status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var results struct {
+		Data []services.BulkDeleteResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if len(results.Data) != 2 || results.Data[0].Status != services.BulkDeleteSkipped || results.Data[1].Status != services.BulkDeleteDeleted {
+		t.Errorf("BulkDeleteUsers() results = %+v, want admin skipped and user deleted", results.Data)
+	}
+}
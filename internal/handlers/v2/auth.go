@@ -0,0 +1,85 @@
+// Package v2 holds the HTTP handlers of API v2. They call the same services as v1 and
+// differ only in the shape of their responses: success bodies are returned without the
+// v1 envelope, and resources use the v2 representations defined here.
+package v2
+
+import (
+	"customable-corporate-site-api/internal/handlers"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/services"
+	"customable-corporate-site-api/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler handles the v2 authentication-related HTTP requests.
+type AuthHandler struct {
+	authService *services.AuthService
+}
+
+// NewAuthHandler creates a new instance of the v2 AuthHandler.
+func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+// Name is the v2 representation of a user's name
+type Name struct {
+	First string `json:"first"`
+	Last  string `json:"last"`
+	Full  string `json:"full"`
+}
+
+// ProfileResponse is the v2 representation of the authenticated user's profile
+type ProfileResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Name      Name      `json:"name"`
+	Role      string    `json:"role"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// newProfileResponse converts the service representation of a user to its v2 shape
+func newProfileResponse(user *models.UserResponse) ProfileResponse {
+	return ProfileResponse{
+		ID:    user.ID,
+		Email: user.Email,
+		Name: Name{
+			First: user.FirstName,
+			Last:  user.LastName,
+			Full:  user.FullName,
+		},
+		Role:      user.Role,
+		IsActive:  user.IsActive,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// GetProfile handles fetching the authenticated user's profile.
+// @Summary Get user profile
+// @Description Retrieve the profile of the authenticated user, without the response envelope.
+// @Tags Auth v2
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} v2.ProfileResponse
+// @Failure 401 {object} services.ErrorResponse
+// @Failure 404 {object} services.ErrorResponse
+// @Router /api/v2/auth/profile [get]
+func (h *AuthHandler) GetProfile(c *gin.Context) {
+	id, ok := handlers.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.authService.GetProfile(c.Request.Context(), id)
+	if err != nil {
+		utils.NotFoundResponse(c, "User")
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(profile))
+}
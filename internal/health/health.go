@@ -0,0 +1,167 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Dependency statuses, from best to worst
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// defaultTimeout bounds each check so a hanging dependency cannot stall the report
+const defaultTimeout = 2 * time.Second
+
+// HealthChecker checks that one dependency is reachable and working
+type HealthChecker interface {
+	// Name identifies the dependency in the report
+	Name() string
+	// Check returns nil when the dependency is healthy
+	Check(ctx context.Context) error
+}
+
+// Result is the outcome of checking one dependency
+type Result struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of checking every registered dependency. Status is the worst status
+// among the checks: a failing critical dependency is down, a failing non-critical one degraded.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Registry holds the dependencies checked by the readiness endpoint
+type Registry struct {
+	timeout  time.Duration
+	mu       sync.RWMutex
+	checkers []registration
+}
+
+type registration struct {
+	checker  HealthChecker
+	critical bool
+}
+
+// NewRegistry creates an empty registry; each check is bounded by timeout (default 2s)
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Registry{timeout: timeout}
+}
+
+// Register adds a dependency. The service cannot serve requests without a critical dependency;
+// a non-critical one only degrades it.
+func (r *Registry) Register(checker HealthChecker, critical bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, registration{checker: checker, critical: critical})
+}
+
+// Check runs every check concurrently and reports each dependency's status and latency
+func (r *Registry) Check(ctx context.Context) *Report {
+	r.mu.RLock()
+	checkers := append([]registration(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checkers))
+	var wg sync.WaitGroup
+	for i, reg := range checkers {
+		wg.Add(1)
+		go func(i int, reg registration) {
+			defer wg.Done()
+			results[i] = r.run(ctx, reg)
+		}(i, reg)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusUp, Checks: make(map[string]Result, len(checkers))}
+	for i, reg := range checkers {
+		report.Checks[reg.checker.Name()] = results[i]
+		report.Status = worst(report.Status, results[i].Status)
+	}
+	return report
+}
+
+// run checks one dependency within the registry's timeout
+func (r *Registry) run(ctx context.Context, reg registration) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := reg.checker.Check(ctx)
+	result := Result{
+		Status:    StatusUp,
+		Critical:  reg.critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDegraded
+		if reg.critical {
+			result.Status = StatusDown
+		}
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// worst returns the worse of two statuses
+func worst(a, b string) string {
+	rank := map[string]int{StatusUp: 0, StatusDegraded: 1, StatusDown: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// funcChecker adapts a function to HealthChecker
+type funcChecker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (f funcChecker) Name() string                    { return f.name }
+func (f funcChecker) Check(ctx context.Context) error { return f.check(ctx) }
+
+// NewChecker creates a HealthChecker named name that runs check
+func NewChecker(name string, check func(ctx context.Context) error) HealthChecker {
+	return funcChecker{name: name, check: check}
+}
+
+// Pinger is implemented by *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Database checks that the database answers a ping
+func Database(db Pinger) HealthChecker {
+	return NewChecker("database", db.PingContext)
+}
+
+// TCP checks that a TCP connection to address can be opened, for dependencies such as an
+// SMTP server that have no cheaper probe
+func TCP(name, address string) HealthChecker {
+	return NewChecker(name, func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return errors.New("connection timed out")
+			}
+			return err
+		}
+		return conn.Close()
+	})
+}
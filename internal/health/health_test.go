@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// mockChecker is a dependency that fails with err when set, after delay
+type mockChecker struct {
+	name  string
+	err   error
+	delay time.Duration
+}
+
+func (m mockChecker) Name() string { return m.name }
+
+func (m mockChecker) Check(ctx context.Context) error {
+	select {
+	case <-time.After(m.delay):
+		return m.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestRegistry_Check(t *testing.T) {
+	failure := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		critical   mockChecker
+		optional   mockChecker
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{"All up", mockChecker{name: "database"}, mockChecker{name: "mail"}, StatusUp, map[string]string{"database": StatusUp, "mail": StatusUp}},
+		{"Non-critical down", mockChecker{name: "database"}, mockChecker{name: "mail", err: failure}, StatusDegraded, map[string]string{"database": StatusUp, "mail": StatusDegraded}},
+		{"Critical down", mockChecker{name: "database", err: failure}, mockChecker{name: "mail"}, StatusDown, map[string]string{"database": StatusDown, "mail": StatusUp}},
+		{"Both down", mockChecker{name: "database", err: failure}, mockChecker{name: "mail", err: failure}, StatusDown, map[string]string{"database": StatusDown, "mail": StatusDegraded}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(0)
+			registry.Register(tt.critical, true)
+			registry.Register(tt.optional, false)
+
+			report := registry.Check(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("Check() status = %q, want %q", report.Status, tt.wantStatus)
+			}
+			for name, want := range tt.wantChecks {
+				result, ok := report.Checks[name]
+				if !ok {
+					t.Fatalf("Check() has no result for %q", name)
+				}
+				if result.Status != want {
+					t.Errorf("Check() %s status = %q, want %q", name, result.Status, want)
+				}
+				if (result.Status == StatusUp) != (result.Error == "") {
+					t.Errorf("Check() %s error = %q with status %q", name, result.Error, result.Status)
+				}
+			}
+			if !report.Checks["database"].Critical || report.Checks["mail"].Critical {
+				t.Errorf("Check() critical flags = %+v, want only database critical", report.Checks)
+			}
+		})
+	}
+}
+
+func TestRegistry_Check_Empty(t *testing.T) {
+	report := NewRegistry(0).Check(context.Background())
+	if report.Status != StatusUp || len(report.Checks) != 0 {
+		t.Errorf("Check() = %+v, want up with no checks", report)
+	}
+}
+
+func TestRegistry_Check_Timeout(t *testing.T) {
+	registry := NewRegistry(20 * time.Millisecond)
+	registry.Register(mockChecker{name: "database", delay: time.Minute}, true)
+	registry.Register(mockChecker{name: "mail", delay: 10 * time.Millisecond}, false)
+
+	start := time.Now()
+	report := registry.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Check() took %v, want it bounded by the timeout", elapsed)
+	}
+	if report.Status != StatusDown {
+		t.Errorf("Check() status = %q, want %q", report.Status, StatusDown)
+	}
+	if result := report.Checks["database"]; result.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Check() database error = %q, want %q", result.Error, context.DeadlineExceeded.Error())
+	}
+	// Checks run concurrently, so the slow check does not hold up the others
+	if result := report.Checks["mail"]; result.Status != StatusUp || result.LatencyMS < 10 {
+		t.Errorf("Check() mail = %+v, want up with a latency of at least 10ms", result)
+	}
+}
+
+func TestTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+
+	if err := TCP("mail", address).Check(context.Background()); err != nil {
+		t.Errorf("Check() with a listener error = %v, want nil", err)
+	}
+
+	listener.Close()
+	if err := TCP("mail", address).Check(context.Background()); err == nil {
+		t.Error("Check() without a listener error = nil, want the dial failure")
+	}
+}
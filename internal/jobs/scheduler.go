@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a task run by the Scheduler every Interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs background jobs on fixed intervals until it is stopped
+type Scheduler struct {
+	jobs   []Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler without any jobs
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job; jobs added after Start are not run
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs every job in its own goroutine, first after one interval has passed.
+// Runs of the same job never overlap, and a failed run is logged and retried on the next tick.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			log.Printf("Job %s has no interval, not scheduling it", job.Name)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := job.Run(ctx); err != nil {
+						log.Printf("Job %s failed: %v", job.Name, err)
+					}
+				}
+			}
+		}(job)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
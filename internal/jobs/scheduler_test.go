@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	var runs, failures atomic.Int32
+
+	scheduler := NewScheduler()
+	scheduler.Add(Job{
+		Name:     "count",
+		Interval: 5 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	scheduler.Add(Job{
+		Name:     "fail",
+		Interval: 5 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			failures.Add(1)
+			return errors.New("boom")
+		},
+	})
+	scheduler.Start(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for (runs.Load() < 3 || failures.Load() < 3) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	scheduler.Stop()
+
+	if runs.Load() < 3 {
+		t.Errorf("job ran %d times, want at least 3", runs.Load())
+	}
+	if failures.Load() < 3 {
+		t.Errorf("failing job ran %d times, want it retried on every tick", failures.Load())
+	}
+
+	// No job runs once Stop has returned
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Errorf("job ran %d times after Stop()", runs.Load()-stopped)
+	}
+}
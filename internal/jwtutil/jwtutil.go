@@ -0,0 +1,130 @@
+package jwtutil
+
+import (
+	"crypto/rsa"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Token subjects distinguishing access, refresh, email change and email verification tokens
+const (
+	SubjectAccess      = "access_token"
+	SubjectRefresh     = "refresh_token"
+	SubjectEmailChange = "email_change"
+	SubjectEmailVerify = "email_verify"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking time-based claims
+const DefaultLeeway = 30 * time.Second
+
+// Errors returned by Parse, so callers can report why a token was rejected
+var (
+	ErrMalformed        = errors.New("token is malformed")
+	ErrSignatureInvalid = errors.New("token signature is invalid")
+	ErrExpired          = errors.New("token has expired")
+	ErrNotYetValid      = errors.New("token is not valid yet")
+	ErrInvalid          = errors.New("token is invalid")
+)
+
+// Claims are the claims of every token issued by the API
+type Claims struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	// TokenVersion is the user's token version when the token was issued; access and refresh
+	// tokens from an older version are rejected
+	TokenVersion uint `json:"ver,omitempty"`
+	// RememberMe marks refresh tokens issued with the longer remember_me lifetime
+	RememberMe bool `json:"remember_me,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Keys holds the keys tokens are verified with
+type Keys struct {
+	// Secret verifies HMAC tokens without a kid header
+	Secret string
+	// ByID maps key IDs to secrets for HMAC tokens carrying a kid header, so tokens
+	// signed with a retired key remain valid until they expire
+	ByID map[string]string
+	// PublicKey, when set, switches verification to RS256: only RSA-signed tokens are
+	// accepted and Secret and ByID are ignored
+	PublicKey *rsa.PublicKey
+}
+
+// Keyfunc returns the key for verifying the token. Tokens must be signed with the
+// configured algorithm family, so an HMAC token is never checked against the RSA public
+// key (or the reverse) and "none" is always refused.
+func (k Keys) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if k.PublicKey != nil {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return k.PublicKey, nil
+	}
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return []byte(k.Secret), nil
+	}
+
+	secret, ok := k.ByID[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	return []byte(secret), nil
+}
+
+// Parse verifies the token with keyfunc and checks its time-based claims with DefaultLeeway
+func Parse(tokenString string, keyfunc jwt.Keyfunc) (*Claims, error) {
+	return ParseWithLeeway(tokenString, keyfunc, DefaultLeeway)
+}
+
+// ParseWithLeeway verifies the token with keyfunc and checks the exp, nbf and iat claims,
+// tolerating leeway of clock skew. The subject is left for the caller to check.
+func ParseWithLeeway(tokenString string, keyfunc jwt.Keyfunc, leeway time.Duration) (*Claims, error) {
+	return parse(tokenString, keyfunc, leeway, true)
+}
+
+// ParseIgnoringExpiry verifies the token with keyfunc and checks the nbf and iat claims like
+// Parse, but accepts expired tokens. It is meant for reporting on a token, never for
+// authenticating with it.
+func ParseIgnoringExpiry(tokenString string, keyfunc jwt.Keyfunc) (*Claims, error) {
+	return parse(tokenString, keyfunc, DefaultLeeway, false)
+}
+
+func parse(tokenString string, keyfunc jwt.Keyfunc, leeway time.Duration, checkExpiry bool) (*Claims, error) {
+	// Time-based claims are checked below so the leeway can be applied
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+
+	claims := &Claims{}
+	token, err := parser.ParseWithClaims(tokenString, claims, keyfunc)
+	if err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrTokenMalformed):
+			return nil, ErrMalformed
+		case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+			return nil, ErrSignatureInvalid
+		default:
+			return nil, ErrInvalid
+		}
+	}
+	if !token.Valid {
+		return nil, ErrInvalid
+	}
+
+	now := time.Now()
+	if checkExpiry && !claims.VerifyExpiresAt(now.Add(-leeway), true) {
+		return nil, ErrExpired
+	}
+	if !claims.VerifyNotBefore(now.Add(leeway), false) || !claims.VerifyIssuedAt(now.Add(leeway), false) {
+		return nil, ErrNotYetValid
+	}
+
+	return claims, nil
+}
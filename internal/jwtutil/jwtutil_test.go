@@ -0,0 +1,127 @@
+package jwtutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testSecret = "test_secret-key"
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, issuedAt, expiresAt time.Time) string {
+	claims := &Claims{
+		UserID: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			Subject:   SubjectAccess,
+		},
+	}
+	signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign test token: %v", err)
+	}
+	return signed
+}
+
+func TestParse(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		keys    Keys
+		token   string
+		wantErr error
+	}{
+		{"Valid HS256", Keys{Secret: testSecret}, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now, now.Add(time.Hour)), nil},
+		{"Valid RS256", Keys{PublicKey: &rsaKey.PublicKey}, signToken(t, jwt.SigningMethodRS256, rsaKey, now, now.Add(time.Hour)), nil},
+		{"Expired within leeway", Keys{Secret: testSecret}, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now.Add(-time.Hour), now.Add(-10*time.Second)), nil},
+		{"Expired", Keys{Secret: testSecret}, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now.Add(-2*time.Hour), now.Add(-time.Hour)), ErrExpired},
+		{"Issued in the future", Keys{Secret: testSecret}, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now.Add(time.Hour), now.Add(2*time.Hour)), ErrNotYetValid},
+		{"Wrong secret", Keys{Secret: testSecret}, signToken(t, jwt.SigningMethodHS256, []byte("another-secret"), now, now.Add(time.Hour)), ErrSignatureInvalid},
+		{"Malformed", Keys{Secret: testSecret}, "not-a-token", ErrMalformed},
+		{"Unsigned", Keys{Secret: testSecret}, signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, now, now.Add(time.Hour)), ErrInvalid},
+		{"RS256 with HMAC keys", Keys{Secret: testSecret}, signToken(t, jwt.SigningMethodRS256, rsaKey, now, now.Add(time.Hour)), ErrInvalid},
+		{"HS256 with RSA keys", Keys{PublicKey: &rsaKey.PublicKey}, signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now, now.Add(time.Hour)), ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := Parse(tt.token, tt.keys.Keyfunc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && claims.UserID != 1 {
+				t.Errorf("Parse() user_id = %d, want 1", claims.UserID)
+			}
+		})
+	}
+}
+
+func TestKeys_Keyfunc_KeyID(t *testing.T) {
+	keys := Keys{Secret: testSecret, ByID: map[string]string{"v1": "first-secret"}}
+
+	tests := []struct {
+		name    string
+		kid     string
+		wantKey string
+		wantErr bool
+	}{
+		{"No kid", "", testSecret, false},
+		{"Known kid", "v1", "first-secret", false},
+		{"Unknown kid", "v2", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.New(jwt.SigningMethodHS256)
+			if tt.kid != "" {
+				token.Header["kid"] = tt.kid
+			}
+
+			key, err := keys.Keyfunc(token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Keyfunc() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(key.([]byte)) != tt.wantKey {
+				t.Errorf("Keyfunc() = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestParseIgnoringExpiry(t *testing.T) {
+	now := time.Now()
+	keys := Keys{Secret: testSecret}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"Valid", signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now, now.Add(time.Hour)), nil},
+		{"Expired", signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now.Add(-2*time.Hour), now.Add(-time.Hour)), nil},
+		{"Issued in the future", signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now.Add(time.Hour), now.Add(2*time.Hour)), ErrNotYetValid},
+		{"Wrong secret", signToken(t, jwt.SigningMethodHS256, []byte("another-secret"), now.Add(-2*time.Hour), now.Add(-time.Hour)), ErrSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseIgnoringExpiry(tt.token, keys.Keyfunc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseIgnoringExpiry() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && claims.UserID != 1 {
+				t.Errorf("ParseIgnoringExpiry() user_id = %d, want 1", claims.UserID)
+			}
+		})
+	}
+}
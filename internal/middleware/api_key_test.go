@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIKeyAuthWithConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     APIKeyAuthConfig
+		key        string
+		wantStatus int
+	}{
+		{"Valid key", APIKeyAuthConfig{Keys: []string{"first-key", "second-key"}}, "second-key", http.StatusOK},
+		{"Invalid key", APIKeyAuthConfig{Keys: []string{"first-key"}}, "wrong-key", http.StatusUnauthorized},
+		{"Prefix of a key", APIKeyAuthConfig{Keys: []string{"first-key"}}, "first", http.StatusUnauthorized},
+		{"Missing key", APIKeyAuthConfig{Keys: []string{"first-key"}}, "", http.StatusUnauthorized},
+		{"No keys configured", APIKeyAuthConfig{}, "first-key", http.StatusUnauthorized},
+		{"Role below the required one", APIKeyAuthConfig{Keys: []string{"first-key"}, Role: "editor"}, "first-key", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin", APIKeyAuthWithConfig(tt.config), RequireAdmin(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,85 +1,204 @@
 package middleware
 
 import (
+	"context"
+	"crypto/rsa"
+	"customable-corporate-site-api/internal/jwtutil"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-// JWTClaims represents the structure of JWT claims
-type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	jwt.RegisteredClaims
+// DefaultJWTLeeway is the clock skew tolerated when checking time-based claims
+const DefaultJWTLeeway = jwtutil.DefaultLeeway
+
+// Cookie names used when tokens are delivered as HttpOnly cookies
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+)
+
+// JWTAuthConfig holds the configuration for the JWT authentication middleware
+type JWTAuthConfig struct {
+	Secret string
+	// Keys maps key IDs to secrets for tokens carrying a kid header, so tokens signed
+	// with a retired key remain valid until they expire
+	Keys map[string]string
+	// PublicKey, when set, switches verification to RS256: only RSA-signed tokens are
+	// accepted and Secret and Keys are ignored
+	PublicKey *rsa.PublicKey
+	// Leeway is the clock skew tolerated between the issuer and this service when
+	// checking the exp, nbf and iat claims
+	Leeway time.Duration
+	// CookieName, when set, is the cookie the access token is read from if the
+	// request has no Authorization header
+	CookieName string
+	// TokenVersions, when set, is asked for the user's current token version on every
+	// request; access tokens carrying another version have been revoked and are rejected
+	TokenVersions TokenVersionSource
+	// Blacklist, when set, is asked on every request whether the access token was revoked
+	// by logging out
+	Blacklist RevokedTokenSource
+}
+
+// TokenVersionSource looks up the current token version of a user
+type TokenVersionSource interface {
+	TokenVersion(ctx context.Context, userID uint) (uint, error)
+}
+
+// RevokedTokenSource reports whether a token was revoked, by its jti
+type RevokedTokenSource interface {
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+// keys returns the keys tokens are verified with
+func (config JWTAuthConfig) keys() jwtutil.Keys {
+	return jwtutil.Keys{Secret: config.Secret, ByID: config.Keys, PublicKey: config.PublicKey}
+}
+
+// tokenError describes why a token was rejected
+type tokenError struct {
+	code    string
+	message string
 }
 
 // JWTAuth middleware validates JWT tokens and extracts user information
 func JWTAuth(secret string) gin.HandlerFunc {
+	return JWTAuthWithConfig(JWTAuthConfig{
+		Secret: secret,
+		Leeway: DefaultJWTLeeway,
+	})
+}
+
+// JWTAuth With Config creates a JWT authentication middleware with custom configuration
+func JWTAuthWithConfig(config JWTAuthConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get the token from the Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required", "message": "Unauthorized"})
+		claims, tokenErr := authenticate(c, config)
+		if tokenErr != nil {
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, tokenErr.code, tokenErr.message, nil)
 			c.Abort()
 			return
 		}
 
-		// Check bearer format
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || strings.ToLower(tokenParts[0]) != "bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be 'Bearer <token>'", "message": "Unauthorized"})
-			c.Abort()
-			return
-		}
+		setUser(c, claims)
+		c.Next()
+	}
+}
 
-		tokenString := tokenParts[1]
+// authenticate reads the access token from the request and validates it: the signature and
+// time-based claims, then the token version and blacklist when configured
+func authenticate(c *gin.Context, config JWTAuthConfig) (*jwtutil.Claims, *tokenError) {
+	// Get the token from the Authorization header, or the cookie when configured
+	tokenString, tokenErr := extractToken(c, config)
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
 
-		// Parse and validate the token
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(secret), nil
-		})
+	// Parse and validate the token
+	claims, tokenErr := parseAccessToken(tokenString, config)
+	if tokenErr == nil {
+		tokenErr = checkTokenVersion(c.Request.Context(), claims, config)
+	}
+	if tokenErr == nil {
+		tokenErr = checkBlacklist(c.Request.Context(), claims, config)
+	}
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+	return claims, nil
+}
 
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "message": "Token validation failed: " + err.Error()})
-			c.Abort()
-			return
-		}
+// setUser sets the user information of a validated token in the context
+func setUser(c *gin.Context, claims *jwtutil.Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_role", claims.Role)
+	c.Set("jwt_claims", claims)
+}
 
-		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "message": "Please login again to obtain a new token"})
-			c.Abort()
-			return
+// extractToken reads the bearer token from the Authorization header. The header takes
+// precedence; the configured cookie is only consulted when the header is absent.
+func extractToken(c *gin.Context, config JWTAuthConfig) (string, *tokenError) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		if config.CookieName != "" {
+			if cookie, err := c.Cookie(config.CookieName); err == nil && cookie != "" {
+				return cookie, nil
+			}
 		}
+		return "", &tokenError{utils.CodeTokenMissing, "Authorization header is required"}
+	}
 
-		// Extract claims and set user information in context
-		claims, ok := token.Claims.(*JWTClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims", "message": "Token format is not recognized"})
-			c.Abort()
-			return
-		}
+	// Check bearer format
+	tokenParts := strings.Split(authHeader, " ")
+	if len(tokenParts) != 2 || strings.ToLower(tokenParts[0]) != "bearer" {
+		return "", &tokenError{utils.CodeTokenMalformed, "Authorization header format must be 'Bearer <token>'"}
+	}
+	return tokenParts[1], nil
+}
 
-		// Verify this is an access token
-		if claims.Subject != "access_token" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token type", "message": "Only access tokens are allowed"})
-			c.Abort()
-			return
+// parseAccessToken verifies the token signature, checks the time-based claims with the
+// configured leeway and ensures the token is an access token
+func parseAccessToken(tokenString string, config JWTAuthConfig) (*jwtutil.Claims, *tokenError) {
+	claims, err := jwtutil.ParseWithLeeway(tokenString, config.keys().Keyfunc, config.Leeway)
+	if err != nil {
+		switch {
+		case errors.Is(err, jwtutil.ErrMalformed):
+			return nil, &tokenError{utils.CodeTokenMalformed, "Token is malformed"}
+		case errors.Is(err, jwtutil.ErrSignatureInvalid):
+			return nil, &tokenError{utils.CodeTokenSignatureInvalid, "Token signature is invalid"}
+		case errors.Is(err, jwtutil.ErrExpired):
+			return nil, &tokenError{utils.CodeTokenExpired, "Token has expired, please refresh your token"}
+		case errors.Is(err, jwtutil.ErrNotYetValid):
+			return nil, &tokenError{utils.CodeTokenNotYetValid, "Token is not valid yet"}
+		default:
+			return nil, &tokenError{utils.CodeTokenInvalid, "Please login again to obtain a new token"}
 		}
+	}
 
-		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
-		c.Set("jwt_claims", claims)
+	// Verify this is an access token
+	if claims.Subject != jwtutil.SubjectAccess {
+		return nil, &tokenError{utils.CodeTokenInvalid, "Only access tokens are allowed"}
+	}
 
-		c.Next()
+	return claims, nil
+}
+
+// checkTokenVersion rejects tokens issued before the user's tokens were last revoked, and
+// tokens of users that no longer exist
+func checkTokenVersion(ctx context.Context, claims *jwtutil.Claims, config JWTAuthConfig) *tokenError {
+	if config.TokenVersions == nil {
+		return nil
+	}
+
+	version, err := config.TokenVersions.TokenVersion(ctx, claims.UserID)
+	if err != nil {
+		return &tokenError{utils.CodeTokenInvalid, "Please login again to obtain a new token"}
 	}
+	if claims.TokenVersion != version {
+		return &tokenError{utils.CodeTokenRevoked, "Token has been revoked, please login again"}
+	}
+	return nil
+}
+
+// checkBlacklist rejects tokens revoked by logging out
+func checkBlacklist(ctx context.Context, claims *jwtutil.Claims, config JWTAuthConfig) *tokenError {
+	if config.Blacklist == nil || claims.ID == "" {
+		return nil
+	}
+
+	revoked, err := config.Blacklist.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return &tokenError{utils.CodeTokenInvalid, "Please login again to obtain a new token"}
+	}
+	if revoked {
+		return &tokenError{utils.CodeTokenRevoked, "Token has been revoked, please login again"}
+	}
+	return nil
 }
 
 // RequireRoles middleware checks if the user has one of the required roles
@@ -136,45 +255,24 @@ func hasRequiredRole(userRole, requiredRole string) bool {
 
 // OptionalAuth middleware allows optional authentication
 func OptionalAuth(secret string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			// No token provided, proceed without authentication
-			c.Next()
-			return
-		}
-
-		// Check Bearer format
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || strings.ToLower(tokenParts[0]) != "bearer" {
-			c.Next()
-			return
-		}
-
-		tokenString := tokenParts[1]
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.Next()
-			return
-		}
+	return OptionalAuthWithConfig(JWTAuthConfig{
+		Secret: secret,
+		Leeway: DefaultJWTLeeway,
+	})
+}
 
-		claims, ok := token.Claims.(*JWTClaims)
-		if !ok || claims.Subject != "access_token" {
+// OptionalAuthWithConfig authenticates requests carrying a token that JWTAuthWithConfig would
+// accept with the same configuration. Requests without one, or with a rejected one, proceed
+// anonymously.
+func OptionalAuthWithConfig(config JWTAuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, tokenErr := authenticate(c, config)
+		if tokenErr != nil {
 			c.Next()
 			return
 		}
 
-		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
-		c.Set("jwt_claims", claims)
+		setUser(c, claims)
 		c.Set("authenticated", true)
 
 		c.Next()
@@ -0,0 +1,471 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"customable-corporate-site-api/internal/jwtutil"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testSecret = "test_secret-key"
+
+func signTestToken(t *testing.T, secret, subject string, issuedAt, expiresAt time.Time) string {
+	claims := &jwtutil.Claims{
+		UserID: 1,
+		Email:  "test@example.com",
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(issuedAt),
+			Subject:   subject,
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Failed to sign test token: %v", err)
+	}
+	return token
+}
+
+func setupAuthRouter(config JWTAuthConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/protected", JWTAuthWithConfig(config), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestJWTAuthWithConfig(t *testing.T) {
+	router := setupAuthRouter(JWTAuthConfig{Secret: testSecret, Leeway: 30 * time.Second})
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "Valid token",
+			header:     "Bearer " + signTestToken(t, testSecret, "access_token", now, now.Add(time.Hour)),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Expired token",
+			header:     "Bearer " + signTestToken(t, testSecret, "access_token", now.Add(-2*time.Hour), now.Add(-time.Hour)),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "TOKEN_EXPIRED",
+		},
+		{
+			name:       "Expired token within leeway",
+			header:     "Bearer " + signTestToken(t, testSecret, "access_token", now.Add(-time.Hour), now.Add(-10*time.Second)),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Token issued in the future",
+			header:     "Bearer " + signTestToken(t, testSecret, "access_token", now.Add(time.Hour), now.Add(2*time.Hour)),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "TOKEN_NOT_YET_VALID",
+		},
+		{
+			name:       "Invalid signature",
+			header:     "Bearer " + signTestToken(t, "another-secret", "access_token", now, now.Add(time.Hour)),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "TOKEN_SIGNATURE_INVALID",
+		},
+		{
+			name:       "Malformed token",
+			header:     "Bearer not-a-token",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "TOKEN_MALFORMED",
+		},
+		{
+			name:       "Refresh token",
+			header:     "Bearer " + signTestToken(t, testSecret, "refresh_token", now, now.Add(time.Hour)),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "TOKEN_INVALID",
+		},
+		{
+			name:       "Missing header",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "TOKEN_MISSING",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("JWTAuthWithConfig() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+
+			var body struct {
+				Success bool   `json:"success"`
+				Code    string `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response body: %v", err)
+			}
+			if body.Success {
+				t.Errorf("JWTAuthWithConfig() success = true, want false")
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("JWTAuthWithConfig() code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestJWTAuthWithConfig_KeyRotation(t *testing.T) {
+	router := setupAuthRouter(JWTAuthConfig{
+		Secret: testSecret,
+		Keys:   map[string]string{"v1": "first-secret", "v2": "second-secret"},
+		Leeway: DefaultJWTLeeway,
+	})
+	now := time.Now()
+
+	signWithKid := func(kid, secret string) string {
+		claims := &jwtutil.Claims{
+			UserID: 1,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(now),
+				Subject:   "access_token",
+			},
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("Failed to sign test token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"Active key", signWithKid("v2", "second-secret"), http.StatusOK},
+		{"Retired key", signWithKid("v1", "first-secret"), http.StatusOK},
+		{"Unknown key", signWithKid("v3", "third-secret"), http.StatusUnauthorized},
+		{"Kid with wrong secret", signWithKid("v1", "second-secret"), http.StatusUnauthorized},
+		{"No kid uses default secret", signTestToken(t, testSecret, "access_token", now, now.Add(time.Hour)), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("JWTAuthWithConfig() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestJWTAuthWithConfig_Cookie(t *testing.T) {
+	now := time.Now()
+	valid := signTestToken(t, testSecret, "access_token", now, now.Add(time.Hour))
+	invalid := signTestToken(t, "another-secret", "access_token", now, now.Add(time.Hour))
+
+	tests := []struct {
+		name       string
+		cookieName string
+		header     string
+		cookie     string
+		wantStatus int
+	}{
+		{"Header", AccessTokenCookie, "Bearer " + valid, "", http.StatusOK},
+		{"Cookie", AccessTokenCookie, "", valid, http.StatusOK},
+		{"Header takes precedence over cookie", AccessTokenCookie, "Bearer " + invalid, valid, http.StatusUnauthorized},
+		{"Invalid cookie", AccessTokenCookie, "", invalid, http.StatusUnauthorized},
+		{"Cookie ignored when not configured", "", "", valid, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupAuthRouter(JWTAuthConfig{Secret: testSecret, Leeway: DefaultJWTLeeway, CookieName: tt.cookieName})
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("JWTAuthWithConfig() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestJWTAuthWithConfig_RS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to encode RSA public key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	router := setupAuthRouter(JWTAuthConfig{Secret: testSecret, PublicKey: &privateKey.PublicKey, Leeway: DefaultJWTLeeway})
+	now := time.Now()
+
+	sign := func(method jwt.SigningMethod, key interface{}) string {
+		claims := &jwtutil.Claims{
+			UserID: 1,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(now),
+				Subject:   "access_token",
+			},
+		}
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign test token: %v", err)
+		}
+		return signed
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"RS256 token", sign(jwt.SigningMethodRS256, privateKey), http.StatusOK},
+		{"RS256 token from another key", sign(jwt.SigningMethodRS256, otherKey), http.StatusUnauthorized},
+		{"HS256 token with the shared secret", signTestToken(t, testSecret, "access_token", now, now.Add(time.Hour)), http.StatusUnauthorized},
+		// Algorithm confusion: an HMAC token keyed with the public key must not verify
+		{"HS256 token keyed with the public key", sign(jwt.SigningMethodHS256, publicPEM), http.StatusUnauthorized},
+		{"Unsigned token", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("JWTAuthWithConfig() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// tokenVersions serves token versions by user ID; unknown users are an error
+type tokenVersions map[uint]uint
+
+func (v tokenVersions) TokenVersion(ctx context.Context, userID uint) (uint, error) {
+	version, ok := v[userID]
+	if !ok {
+		return 0, errors.New("user not found")
+	}
+	return version, nil
+}
+
+func TestJWTAuthWithConfig_TokenVersion(t *testing.T) {
+	now := time.Now()
+	token := signTestToken(t, testSecret, jwtutil.SubjectAccess, now, now.Add(time.Hour))
+
+	tests := []struct {
+		name       string
+		versions   tokenVersions
+		wantStatus int
+		wantCode   string
+	}{
+		{"Current version", tokenVersions{1: 0}, http.StatusOK, ""},
+		{"Revoked", tokenVersions{1: 1}, http.StatusUnauthorized, "TOKEN_REVOKED"},
+		{"User gone", tokenVersions{}, http.StatusUnauthorized, "TOKEN_INVALID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupAuthRouter(JWTAuthConfig{Secret: testSecret, TokenVersions: tt.versions})
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("JWTAuthWithConfig() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body struct {
+				Code string `json:"code"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body.Code != tt.wantCode {
+				t.Errorf("JWTAuthWithConfig() code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// revokedTokens reports the listed token IDs as revoked, failing with err when set
+type revokedTokens struct {
+	ids map[string]bool
+	err error
+}
+
+func (r revokedTokens) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return r.ids[tokenID], r.err
+}
+
+func TestJWTAuthWithConfig_Blacklist(t *testing.T) {
+	now := time.Now()
+	claims := &jwtutil.Claims{
+		UserID: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   jwtutil.SubjectAccess,
+			ID:        "token-1",
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign test token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		blacklist  revokedTokens
+		wantStatus int
+		wantCode   string
+	}{
+		{"Not revoked", revokedTokens{ids: map[string]bool{"token-2": true}}, http.StatusOK, ""},
+		{"Revoked", revokedTokens{ids: map[string]bool{"token-1": true}}, http.StatusUnauthorized, "TOKEN_REVOKED"},
+		{"Lookup failure", revokedTokens{err: errors.New("database unavailable")}, http.StatusUnauthorized, "TOKEN_INVALID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupAuthRouter(JWTAuthConfig{Secret: testSecret, Blacklist: tt.blacklist})
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("JWTAuthWithConfig() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body struct {
+				Code string `json:"code"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body.Code != tt.wantCode {
+				t.Errorf("JWTAuthWithConfig() code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOptionalAuthWithConfig(t *testing.T) {
+	now := time.Now()
+	token := signTestToken(t, testSecret, jwtutil.SubjectAccess, now, now.Add(time.Hour))
+	// Only tokens with an ID can be blacklisted
+	claims := &jwtutil.Claims{
+		UserID: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   jwtutil.SubjectAccess,
+			ID:        "token-1",
+		},
+	}
+	tokenWithID, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign test token: %v", err)
+	}
+
+	tests := []struct {
+		name              string
+		config            JWTAuthConfig
+		header            string
+		wantAuthenticated bool
+	}{
+		{"No token", JWTAuthConfig{Secret: testSecret}, "", false},
+		{"Valid token", JWTAuthConfig{Secret: testSecret}, "Bearer " + token, true},
+		{"Malformed header", JWTAuthConfig{Secret: testSecret}, "Token " + token, false},
+		{"Wrong secret", JWTAuthConfig{Secret: "other-secret"}, "Bearer " + token, false},
+		{"Refresh token", JWTAuthConfig{Secret: testSecret}, "Bearer " + signTestToken(t, testSecret, jwtutil.SubjectRefresh, now, now.Add(time.Hour)), false},
+		{"Revoked version", JWTAuthConfig{Secret: testSecret, TokenVersions: tokenVersions{1: 1}}, "Bearer " + token, false},
+		{"Current version", JWTAuthConfig{Secret: testSecret, TokenVersions: tokenVersions{1: 0}}, "Bearer " + token, true},
+		{"Blacklisted", JWTAuthConfig{Secret: testSecret, Blacklist: revokedTokens{ids: map[string]bool{"token-1": true}}}, "Bearer " + tokenWithID, false},
+		{"Not blacklisted", JWTAuthConfig{Secret: testSecret, Blacklist: revokedTokens{ids: map[string]bool{"token-2": true}}}, "Bearer " + tokenWithID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/optional", OptionalAuthWithConfig(tt.config), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"authenticated": c.GetBool("authenticated"), "user_id": c.GetUint("user_id")})
+			})
+			req := httptest.NewRequest(http.MethodGet, "/optional", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("OptionalAuthWithConfig() status = %d, want %d", w.Code, http.StatusOK)
+			}
+			var body struct {
+				Authenticated bool `json:"authenticated"`
+				UserID        uint `json:"user_id"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body.Authenticated != tt.wantAuthenticated || (body.UserID == 1) != tt.wantAuthenticated {
+				t.Errorf("OptionalAuthWithConfig() authenticated = %v, user_id = %d, want authenticated %v", body.Authenticated, body.UserID, tt.wantAuthenticated)
+			}
+		})
+	}
+}
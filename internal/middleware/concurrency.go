@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/utils"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultConcurrencyRetryAfter is the Retry-After sent when a request is turned away
+const defaultConcurrencyRetryAfter = time.Second
+
+// ConcurrencyLimitConfig holds the concurrency limiter configuration. The limit is global,
+// complementing per-client rate limits with a ceiling that protects the database pool.
+type ConcurrencyLimitConfig struct {
+	// Max is the number of requests handled at once; 0 or less disables the limit
+	Max int
+	// QueueTimeout is how long a request waits for a free slot; 0 rejects it at once
+	QueueTimeout time.Duration
+	// RetryAfter is sent with 503 responses (default 1s)
+	RetryAfter time.Duration
+	// SkipPaths are neither limited nor counted, e.g. long-lived streams that would hold a
+	// slot for their whole lifetime
+	SkipPaths []string
+}
+
+// ConcurrencyLimiter caps the number of requests in flight with a semaphore
+type ConcurrencyLimiter struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+	retryAfter   time.Duration
+	skip         map[string]bool
+
+	inFlight atomic.Int64
+	rejected atomic.Int64
+}
+
+// ConcurrencyLimit limits the server to max requests in flight, rejecting others at once
+func ConcurrencyLimit(max int) gin.HandlerFunc {
+	return NewConcurrencyLimiter(ConcurrencyLimitConfig{Max: max}).Handler()
+}
+
+// NewConcurrencyLimiter creates a limiter; use Handler as middleware and InFlight for metrics
+func NewConcurrencyLimiter(config ConcurrencyLimitConfig) *ConcurrencyLimiter {
+	retryAfter := config.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultConcurrencyRetryAfter
+	}
+
+	limiter := &ConcurrencyLimiter{
+		queueTimeout: config.QueueTimeout,
+		retryAfter:   retryAfter,
+		skip:         make(map[string]bool, len(config.SkipPaths)),
+	}
+	for _, path := range config.SkipPaths {
+		limiter.skip[path] = true
+	}
+	if config.Max > 0 {
+		limiter.slots = make(chan struct{}, config.Max)
+	}
+	return limiter
+}
+
+// Handler returns the middleware. When every slot is taken, a request waits up to the queue
+// timeout for one to free up, or until its context ends, and is otherwise answered 503 with
+// a Retry-After header.
+func (l *ConcurrencyLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+		if l.slots == nil {
+			l.track(c)
+			return
+		}
+
+		if !l.acquire(c) {
+			l.rejected.Add(1)
+			c.Header("Retry-After", strconv.Itoa(int((l.retryAfter+time.Second-1)/time.Second)))
+			utils.ServiceUnavailableResponse(c, "Server is busy, please try again later")
+			c.Abort()
+			return
+		}
+		defer func() { <-l.slots }()
+
+		l.track(c)
+	}
+}
+
+// InFlight returns the number of requests being handled
+func (l *ConcurrencyLimiter) InFlight() int64 {
+	return l.inFlight.Load()
+}
+
+// Rejected returns the number of requests turned away since the limiter was created
+func (l *ConcurrencyLimiter) Rejected() int64 {
+	return l.rejected.Load()
+}
+
+// acquire takes a slot, waiting up to the queue timeout
+func (l *ConcurrencyLimiter) acquire(c *gin.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if l.queueTimeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
+
+// track counts the request as in flight while the rest of the chain runs
+func (l *ConcurrencyLimiter) track(c *gin.Context) {
+	l.inFlight.Add(1)
+	defer l.inFlight.Add(-1)
+	c.Next()
+}
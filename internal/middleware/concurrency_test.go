@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupConcurrencyLimit routes /slow to a handler that holds its slot until release is closed
+func setupConcurrencyLimit(limiter *ConcurrencyLimiter) (router *gin.Engine, started chan struct{}, release chan struct{}) {
+	gin.SetMode(gin.TestMode)
+	started = make(chan struct{}, 10)
+	release = make(chan struct{})
+
+	router = gin.New()
+	router.Use(limiter.Handler())
+	router.GET("/slow", func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	return router, started, release
+}
+
+func serveAsync(router *gin.Engine) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		done <- w
+	}()
+	return done
+}
+
+func TestConcurrencyLimit_RejectsWhenFull(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Max: 1, RetryAfter: 2 * time.Second})
+	router, started, release := setupConcurrencyLimit(limiter)
+
+	first := serveAsync(router)
+	<-started
+	if limiter.InFlight() != 1 {
+		t.Errorf("InFlight() = %d, want 1", limiter.InFlight())
+	}
+
+	w := <-serveAsync(router)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status over the limit = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+	if limiter.Rejected() != 1 {
+		t.Errorf("Rejected() = %d, want 1", limiter.Rejected())
+	}
+
+	close(release)
+	if w := <-first; w.Code != http.StatusOK {
+		t.Errorf("status within the limit = %d, want %d", w.Code, http.StatusOK)
+	}
+	if limiter.InFlight() != 0 {
+		t.Errorf("InFlight() after the requests = %d, want 0", limiter.InFlight())
+	}
+}
+
+func TestConcurrencyLimit_Queue(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Max: 1, QueueTimeout: 5 * time.Second})
+	router, started, release := setupConcurrencyLimit(limiter)
+
+	first := serveAsync(router)
+	<-started
+	second := serveAsync(router)
+
+	// The queued request gets the slot once the first one finishes
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	for _, done := range []<-chan *httptest.ResponseRecorder{first, second} {
+		if w := <-done; w.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+	if limiter.Rejected() != 0 {
+		t.Errorf("Rejected() = %d, want 0", limiter.Rejected())
+	}
+}
+
+func TestConcurrencyLimit_QueueTimeout(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Max: 1, QueueTimeout: 20 * time.Millisecond})
+	router, started, release := setupConcurrencyLimit(limiter)
+	defer close(release)
+
+	serveAsync(router)
+	<-started
+
+	if w := <-serveAsync(router); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status after the queue timeout = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestConcurrencyLimit_Disabled(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{})
+	router, started, release := setupConcurrencyLimit(limiter)
+
+	first, second := serveAsync(router), serveAsync(router)
+	<-started
+	<-started
+	if limiter.InFlight() != 2 {
+		t.Errorf("InFlight() = %d, want 2", limiter.InFlight())
+	}
+
+	close(release)
+	<-first
+	<-second
+}
+
+func TestConcurrencyLimit_SkipPaths(t *testing.T) {
+	limiter := NewConcurrencyLimiter(ConcurrencyLimitConfig{Max: 1, SkipPaths: []string{"/slow"}})
+	router, started, release := setupConcurrencyLimit(limiter)
+
+	first, second := serveAsync(router), serveAsync(router)
+	<-started
+	<-started
+	if limiter.InFlight() != 0 {
+		t.Errorf("InFlight() = %d, want 0", limiter.InFlight())
+	}
+
+	close(release)
+	if w := <-first; w.Code != http.StatusOK {
+		t.Errorf("first status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := <-second; w.Code != http.StatusOK {
+		t.Errorf("second status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/utils"
+	"mime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireJSON middleware rejects request bodies that are not application/json
+func RequireJSON() gin.HandlerFunc {
+	return RequireContentType("application/json")
+}
+
+// RequireContentType middleware rejects request bodies whose Content-Type is not one of the allowed media types.
+// Requests without a body (e.g. GET or DELETE) are passed through untouched.
+func RequireContentType(allowedTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasBody(c) {
+			c.Next()
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err != nil || !containsMediaType(allowedTypes, mediaType) {
+			utils.UnsupportedMediaTypeResponse(c, "Content-Type must be "+strings.Join(allowedTypes, " or "))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// hasBody reports whether the request carries a non-empty body
+func hasBody(c *gin.Context) bool {
+	if c.Request.Body == nil {
+		return false
+	}
+	// ContentLength is -1 when unknown (e.g. chunked encoding), which still counts as a body
+	return c.Request.ContentLength != 0
+}
+
+func containsMediaType(allowedTypes []string, mediaType string) bool {
+	for _, allowed := range allowedTypes {
+		if strings.EqualFold(allowed, mediaType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupContentTypeRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler)
+	router.POST("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestRequireJSON(t *testing.T) {
+	router := setupContentTypeRouter(RequireJSON())
+
+	tests := []struct {
+		name        string
+		method      string
+		body        string
+		contentType string
+		wantStatus  int
+	}{
+		{
+			name:        "JSON body",
+			method:      http.MethodPost,
+			body:        `{"email":"test@example.com"}`,
+			contentType: "application/json",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "JSON body with charset",
+			method:      http.MethodPost,
+			body:        `{"email":"test@example.com"}`,
+			contentType: "application/json; charset=utf-8",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "Plain text body",
+			method:      http.MethodPost,
+			body:        "email=test@example.com",
+			contentType: "text/plain",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "Form encoded body",
+			method:      http.MethodPost,
+			body:        "email=test@example.com",
+			contentType: "application/x-www-form-urlencoded",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "Missing content type",
+			method:     http.MethodPost,
+			body:       `{"email":"test@example.com"}`,
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "Empty body",
+			method:     http.MethodPost,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "GET without body",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/test", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("RequireJSON() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnsupportedMediaType && !strings.Contains(w.Body.String(), `"success":false`) {
+				t.Errorf("RequireJSON() body = %s, want standard error envelope", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireContentType_Multipart(t *testing.T) {
+	router := setupContentTypeRouter(RequireContentType("multipart/form-data"))
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("--boundary--"))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("RequireContentType() status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -1,22 +1,33 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
+// defaultAllowedMethods is the single source of truth for the methods advertised on preflight
+var defaultAllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
 // CORS middleware to handle Cross-Origin Resource Sharing
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
 
 		// Set CORS headers
-		c.Header("Access-Control-Allow-Origin", getAllowedOrigin(origin))
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		allowedOrigin := getAllowedOrigin(origin)
+		c.Header("Access-Control-Allow-Origin", allowedOrigin)
+		c.Header("Access-Control-Allow-Methods", joinStrings(defaultAllowedMethods, ""))
 		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Requested-With, Authorization")
 		c.Header("Access-Control-Expose-Headers", "Content-Length, X-CSRF-Token, X-Requested-With, Authorization")
-		c.Header("Access-Control-Allow-Credentials", "true")
+		// Credentials are only valid alongside an explicit origin
+		if allowedOrigin != "*" {
+			c.Header("Vary", "Origin")
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
 		c.Header("Access-Control-Max-Age", "86400") // 24 hours
 
 		// Handle preflight requests
@@ -29,8 +40,43 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
-// CORS With Config creates a CORS middleware with custom configuration
+// ErrCORSWildcardWithCredentials is returned when credentials are allowed for any origin,
+// which browsers reject
+var ErrCORSWildcardWithCredentials = errors.New("CORS AllowCredentials cannot be combined with a wildcard origin; list the allowed origins explicitly")
+
+// Validate checks the configuration for combinations that browsers reject
+func (config CORSConfig) Validate() error {
+	if !config.AllowCredentials {
+		return nil
+	}
+	if len(config.AllowedOrigins) == 0 {
+		return ErrCORSWildcardWithCredentials
+	}
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			return ErrCORSWildcardWithCredentials
+		}
+	}
+	return nil
+}
+
+// MustValidateCORSConfig returns the configuration, panicking if it is invalid.
+// Use it at boot so a misconfigured server fails to start.
+func MustValidateCORSConfig(config CORSConfig) CORSConfig {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	return config
+}
+
+// CORS With Config creates a CORS middleware with custom configuration.
+// When credentials are allowed, wildcard origins are refused: only origins listed
+// explicitly get an Access-Control-Allow-Origin header.
 func CORSWithConfig(config CORSConfig) gin.HandlerFunc {
+	if err := config.Validate(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
 
@@ -41,14 +87,19 @@ func CORSWithConfig(config CORSConfig) gin.HandlerFunc {
 		}
 
 		// Set CORS headers
-		c.Header("Access-Control-Allow-Origin", allowedOrigin)
-		c.Header("Access-Control-Allow-Methods", joinStrings(config.AllowedMethods, "GET, POST, PUT, DELETE, OPTIONS"))
+		if allowedOrigin != "*" {
+			c.Header("Vary", "Origin")
+		}
+		if allowedOrigin != "*" || !config.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", allowedOrigin)
+		}
+		c.Header("Access-Control-Allow-Methods", joinStrings(config.AllowedMethods, joinStrings(defaultAllowedMethods, "")))
 		c.Header("Access-Control-Allow-Headers", joinStrings(config.AllowedHeaders, "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Requested-With, Authorization"))
 		c.Header("Access-Control-Expose-Headers", joinStrings(config.ExposedHeaders, "Content-Length, X-CSRF-Token, X-Requested-With, Authorization"))
 		c.Header("Access-Control-Allow-Credentials", boolToString(config.AllowCredentials))
 
 		if config.MaxAge > 0 {
-			c.Header("Access-Control-Max-Age", string(rune(config.MaxAge)))
+			c.Header("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
 		}
 
 		// Handle preflight requests
@@ -75,10 +126,10 @@ type CORSConfig struct {
 func DefaultCORSConfig() CORSConfig {
 	return CORSConfig{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   append([]string(nil), defaultAllowedMethods...),
 		AllowedHeaders:   []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "X-Requested-With", "Authorization"},
 		ExposedHeaders:   []string{"Content-Length", "X-CSRF-Token", "X-Requested-With", "Authorization"},
-		AllowCredentials: true,
+		AllowCredentials: false, // credentials require an explicit origin list
 		MaxAge:           86400,
 	}
 }
@@ -94,7 +145,7 @@ func DevelopmentCORSConfig() CORSConfig {
 			"http://127.0.0.1:3001",
 			"http://127.0.0.1:8080",
 		},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   append([]string(nil), defaultAllowedMethods...),
 		AllowedHeaders:   []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "X-Requested-With", "Authorization"},
 		ExposedHeaders:   []string{"Content-Length", "X-CSRF-Token", "X-Requested-With", "Authorization"},
 		AllowCredentials: true,
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CORSConfig
+		wantErr bool
+	}{
+		{"Wildcard without credentials", CORSConfig{AllowedOrigins: []string{"*"}}, false},
+		{"Explicit origins with credentials", CORSConfig{AllowedOrigins: []string{"https://example.com"}, AllowCredentials: true}, false},
+		{"Wildcard with credentials", CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, true},
+		{"Wildcard among origins with credentials", CORSConfig{AllowedOrigins: []string{"https://example.com", "*"}, AllowCredentials: true}, true},
+		{"No origins with credentials", CORSConfig{AllowCredentials: true}, true},
+		{"Default config", DefaultCORSConfig(), false},
+		{"Development config", DevelopmentCORSConfig(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMustValidateCORSConfig(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustValidateCORSConfig() did not panic for wildcard origin with credentials")
+		}
+	}()
+
+	MustValidateCORSConfig(CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+}
+
+func TestCORSWithConfig_Credentials(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          CORSConfig
+		origin          string
+		wantAllowOrigin string
+	}{
+		{
+			name:            "Wildcard without credentials",
+			config:          CORSConfig{AllowedOrigins: []string{"*"}},
+			origin:          "https://example.com",
+			wantAllowOrigin: "*",
+		},
+		{
+			name:            "Listed origin with credentials",
+			config:          CORSConfig{AllowedOrigins: []string{"https://example.com"}, AllowCredentials: true},
+			origin:          "https://example.com",
+			wantAllowOrigin: "https://example.com",
+		},
+		{
+			name:            "Wildcard refused with credentials",
+			config:          CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			origin:          "https://example.com",
+			wantAllowOrigin: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(CORSWithConfig(tt.config))
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantAllowOrigin)
+			}
+		})
+	}
+}
+
+func TestCORS_PreflightAllowsPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+	}{
+		{"CORS", CORS()},
+		{"CORSWithConfig default", CORSWithConfig(DefaultCORSConfig())},
+		{"CORSWithConfig without methods", CORSWithConfig(CORSConfig{AllowedOrigins: []string{"*"}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(tt.handler)
+			router.PATCH("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodOptions, "/test", nil)
+			req.Header.Set("Origin", "https://example.com")
+			req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Errorf("preflight status = %d, want %d", w.Code, http.StatusNoContent)
+			}
+			if methods := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(methods, http.MethodPatch) {
+				t.Errorf("Access-Control-Allow-Methods = %q, want it to contain PATCH", methods)
+			}
+		})
+	}
+}
+
+func TestCORSWithConfig_MaxAge(t *testing.T) {
+	tests := []struct {
+		name   string
+		maxAge int
+		want   string
+	}{
+		{"One day", 86400, "86400"},
+		{"Ten minutes", 600, "600"},
+		{"Unset", 0, ""},
+		{"Negative", -1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(CORSWithConfig(CORSConfig{AllowedOrigins: []string{"https://example.com"}, MaxAge: tt.maxAge}))
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodOptions, "/test", nil)
+			req.Header.Set("Origin", "https://example.com")
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Access-Control-Max-Age"); got != tt.want {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.want)
+			}
+			if _, ok := w.Header()["Access-Control-Max-Age"]; ok != (tt.want != "") {
+				t.Errorf("Access-Control-Max-Age present = %v, want %v", ok, tt.want != "")
+			}
+		})
+	}
+}
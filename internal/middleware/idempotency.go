@@ -0,0 +1,225 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"customable-corporate-site-api/internal/store"
+	"customable-corporate-site-api/internal/utils"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader is set to "true" on responses replayed from the store
+	IdempotentReplayHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL is how long a cached response can be replayed
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// DefaultIdempotencyLockTTL is how long a key stays reserved for a request that never
+	// completes, e.g. because the instance handling it crashed
+	DefaultIdempotencyLockTTL = time.Minute
+)
+
+// IdempotencyConfig holds the idempotency middleware configuration
+type IdempotencyConfig struct {
+	Store store.Store
+	TTL   time.Duration
+	// LockTTL bounds how long a key is reserved while its first request is in flight
+	// (default DefaultIdempotencyLockTTL)
+	LockTTL time.Duration
+}
+
+// idempotentResponse is the cached first response for an idempotency key. While the first
+// request is in flight only RequestHash is set and InProgress is true.
+type idempotentResponse struct {
+	RequestHash string      `json:"request_hash"`
+	InProgress  bool        `json:"in_progress,omitempty"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// unreplayedHeaders are response headers that describe the original request rather than the
+// response, and are not replayed
+var unreplayedHeaders = []string{"Content-Length", "Date", "X-Request-ID", IdempotentReplayHeader}
+
+// responseRecorder captures the response body while still writing it to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency middleware replays the first response of a POST request carrying an Idempotency-Key header.
+// It is opt-in: attach it only to the routes that should honour the header.
+func Idempotency(s store.Store) gin.HandlerFunc {
+	return IdempotencyWithConfig(IdempotencyConfig{Store: s, TTL: DefaultIdempotencyTTL})
+}
+
+// IdempotencyWithConfig creates the idempotency middleware with custom configuration.
+// Responses are cached per (key, route, user) and replayed with their headers; reusing a key
+// with a different body returns 409. The key is reserved atomically before the handler runs,
+// so a duplicate arriving while the first request is still in flight is answered 409 instead
+// of running the handler twice.
+func IdempotencyWithConfig(config IdempotencyConfig) gin.HandlerFunc {
+	if config.TTL <= 0 {
+		config.TTL = DefaultIdempotencyTTL
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = DefaultIdempotencyLockTTL
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		body, err := readBody(c)
+		if err != nil {
+			utils.BadRequestResponse(c, "Failed to read request body", err)
+			c.Abort()
+			return
+		}
+		requestHash := hashRequestBody(body)
+		storeKey := idempotencyStoreKey(c, key)
+
+		reserved, err := reserveIdempotencyKey(config.Store, storeKey, requestHash, config.LockTTL)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to reserve idempotency key", err)
+			c.Abort()
+			return
+		}
+		if !reserved {
+			replayIdempotentResponse(c, config.Store, storeKey, requestHash)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Server errors are not cached so that the client can retry them
+		if recorder.Status() >= http.StatusInternalServerError {
+			if err := config.Store.Delete(storeKey); err != nil {
+				c.Error(fmt.Errorf("failed to release idempotency key: %w", err))
+			}
+			return
+		}
+
+		header := recorder.Header().Clone()
+		for _, name := range unreplayedHeaders {
+			header.Del(name)
+		}
+		data, err := json.Marshal(idempotentResponse{
+			RequestHash: requestHash,
+			Status:      recorder.Status(),
+			Header:      header,
+			Body:        recorder.body.Bytes(),
+		})
+		if err == nil {
+			err = config.Store.Set(storeKey, data, config.TTL)
+		}
+		if err != nil {
+			c.Error(fmt.Errorf("failed to store idempotent response: %w", err))
+		}
+	}
+}
+
+// reserveIdempotencyKey marks key as in progress unless it is already reserved or cached
+func reserveIdempotencyKey(s store.Store, key, requestHash string, ttl time.Duration) (bool, error) {
+	marker, err := json.Marshal(idempotentResponse{RequestHash: requestHash, InProgress: true})
+	if err != nil {
+		return false, err
+	}
+	return s.SetNX(key, marker, ttl)
+}
+
+// replayIdempotentResponse answers a request whose key is already taken: with the cached
+// response, or with 409 when the body differs or the first request has not completed
+func replayIdempotentResponse(c *gin.Context, s store.Store, key, requestHash string) {
+	cached, err := lookupIdempotentResponse(s, key)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to read idempotency key", err)
+		return
+	}
+
+	switch {
+	case cached != nil && cached.RequestHash != requestHash:
+		utils.ConflictResponse(c, "Idempotency-Key has already been used with a different request body", nil)
+	case cached == nil || cached.InProgress:
+		// A missing entry means the first request failed and released the key just now
+		utils.ConflictResponse(c, "A request with this Idempotency-Key is still being processed", nil)
+	default:
+		for name, values := range cached.Header {
+			c.Writer.Header()[name] = values
+		}
+		c.Header(IdempotentReplayHeader, "true")
+		c.Data(cached.Status, cached.Header.Get("Content-Type"), cached.Body)
+	}
+}
+
+// readBody reads the request body and restores it for the handlers that follow
+func readBody(c *gin.Context) ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func hashRequestBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotencyStoreKey scopes the client key to the route and the authenticated user (if any)
+func idempotencyStoreKey(c *gin.Context, key string) string {
+	user := "anonymous"
+	if userID, exists := c.Get("user_id"); exists {
+		user = fmt.Sprint(userID)
+	}
+	return fmt.Sprintf("idempotency:%s:%s:%s", user, c.FullPath(), key)
+}
+
+func lookupIdempotentResponse(s store.Store, key string) (*idempotentResponse, error) {
+	data, err := s.Get(key)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cached idempotentResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/store"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupIdempotencyRouter(calls *int, status int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/contact", Idempotency(store.NewMemoryStore()), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func postWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls, http.StatusCreated)
+
+	first := postWithKey(router, "key-1", `{"name":"test"}`)
+	second := postWithKey(router, "key-1", `{"name":"test"}`)
+
+	if calls != 1 {
+		t.Errorf("Idempotency() handler calls = %d, want 1", calls)
+	}
+	if second.Code != http.StatusCreated {
+		t.Errorf("Idempotency() replay status = %d, want %d", second.Code, http.StatusCreated)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("Idempotency() replay body = %s, want %s", second.Body.String(), first.Body.String())
+	}
+	if first.Header().Get(IdempotentReplayHeader) != "" {
+		t.Errorf("Idempotency() first response marked as replayed")
+	}
+	if second.Header().Get(IdempotentReplayHeader) != "true" {
+		t.Errorf("Idempotency() %s = %q, want %q", IdempotentReplayHeader, second.Header().Get(IdempotentReplayHeader), "true")
+	}
+}
+
+func TestIdempotency_ConflictingBody(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls, http.StatusCreated)
+
+	postWithKey(router, "key-1", `{"name":"test"}`)
+	w := postWithKey(router, "key-1", `{"name":"other"}`)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Idempotency() status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if calls != 1 {
+		t.Errorf("Idempotency() handler calls = %d, want 1", calls)
+	}
+}
+
+func TestIdempotency_Passthrough(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []string
+		status int
+	}{
+		{"Without key", []string{"", ""}, http.StatusCreated},
+		{"Different keys", []string{"key-1", "key-2"}, http.StatusCreated},
+		{"Server errors are not cached", []string{"key-1", "key-1"}, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			router := setupIdempotencyRouter(&calls, tt.status)
+
+			for _, key := range tt.keys {
+				postWithKey(router, key, `{"name":"test"}`)
+			}
+
+			if calls != len(tt.keys) {
+				t.Errorf("Idempotency() handler calls = %d, want %d", calls, len(tt.keys))
+			}
+		})
+	}
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calls := 0
+
+	router := gin.New()
+	router.POST("/contact", Idempotency(store.NewMemoryStore()), func(c *gin.Context) {
+		calls++
+		close(started)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postWithKey(router, "key-1", `{"name":"test"}`)
+	}()
+	<-started
+
+	duplicate := postWithKey(router, "key-1", `{"name":"test"}`)
+	close(release)
+	first := <-done
+
+	if duplicate.Code != http.StatusConflict {
+		t.Errorf("Idempotency() in-flight duplicate status = %d, want %d", duplicate.Code, http.StatusConflict)
+	}
+	if first.Code != http.StatusCreated {
+		t.Errorf("Idempotency() first status = %d, want %d", first.Code, http.StatusCreated)
+	}
+	if calls != 1 {
+		t.Errorf("Idempotency() handler calls = %d, want 1", calls)
+	}
+
+	if replay := postWithKey(router, "key-1", `{"name":"test"}`); replay.Code != http.StatusCreated {
+		t.Errorf("Idempotency() replay after completion status = %d, want %d", replay.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotency_ReplaysHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/contact", Idempotency(store.NewMemoryStore()), func(c *gin.Context) {
+		c.Header("X-Request-ID", "first-request")
+		c.Header("Location", "/contacts/1")
+		c.SetCookie("session", "abc", 3600, "/", "", true, true)
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	postWithKey(router, "key-1", `{"name":"test"}`)
+	replay := postWithKey(router, "key-1", `{"name":"test"}`)
+
+	if got := replay.Header().Get("Location"); got != "/contacts/1" {
+		t.Errorf("Idempotency() replayed Location = %q, want %q", got, "/contacts/1")
+	}
+	if got := replay.Header().Get("Set-Cookie"); !strings.HasPrefix(got, "session=abc") {
+		t.Errorf("Idempotency() replayed Set-Cookie = %q, want the session cookie", got)
+	}
+	if got := replay.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("Idempotency() replayed Content-Type = %q, want application/json", got)
+	}
+	if got := replay.Header().Get("X-Request-ID"); got != "" {
+		t.Errorf("Idempotency() replayed X-Request-ID = %q, want it left out", got)
+	}
+}
@@ -1,10 +1,15 @@
 package middleware
 
 import (
+	"customable-corporate-site-api/internal/tracing"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Logger returns a gin.HanlderFunc that logs requests and responses
@@ -65,11 +70,13 @@ func StructuredLogger() gin.HandlerFunc {
 		param.Path = path
 
 		// Custom structured log
-		logStructuredRequest(param)
+		logStructuredRequest(os.Stdout, param)
 	}
 }
 
-// RequestIDMiddleware adds a unique request ID to each request
+// RequestIDMiddleware adds a unique request ID to each request. It also continues the
+// caller's W3C trace from the traceparent header, or starts a new trace, so logs can be
+// correlated across services.
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.Request.Header.Get("X-Request-ID")
@@ -82,12 +89,28 @@ func RequestIDMiddleware() gin.HandlerFunc {
 		c.Set("request_id", requestID)
 		c.Header("X-Request-ID", requestID)
 
+		// This request is a new span in the caller's trace; the request context carries it
+		// to outbound calls (see tracing.Inject)
+		trace := tracing.New()
+		if parent, ok := tracing.Parse(c.Request.Header.Get(tracing.Header)); ok {
+			trace = parent.Child()
+		}
+		ctx := tracing.WithRequestID(c.Request.Context(), requestID)
+		c.Request = c.Request.WithContext(tracing.NewContext(ctx, trace))
+		c.Set("trace_id", trace.TraceID)
+		c.Header(tracing.Header, trace.String())
+
 		c.Next()
 	}
 }
 
 // LoggingWithConfig returns logger middleware with custom configuration
 func LoggingWithConfig(config LoggerConfig) gin.HandlerFunc {
+	out := config.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
@@ -129,19 +152,72 @@ func LoggingWithConfig(config LoggerConfig) gin.HandlerFunc {
 		}
 		param.Path = path
 
-		if config.CustomFormatter != nil {
-			fmt.Print(config.CustomFormatter(param))
-		} else {
-			logStructuredRequest(param)
+		switch {
+		case config.CustomFormatter != nil:
+			fmt.Fprint(out, config.CustomFormatter(param))
+		case config.Format == LogFormatJSON || config.JSON:
+			logJSONRequest(out, param, c.GetString("request_id"), c.GetString("trace_id"))
+		default:
+			logStructuredRequest(out, param)
 		}
 	}
 }
 
+// Access log formats of LoggerConfig.Format
+const (
+	// LogFormatText writes a human-readable line per request
+	LogFormatText = "text"
+	// LogFormatJSON writes one JSON object per line, for log aggregators
+	LogFormatJSON = "json"
+)
+
 // LoggingConfig represents configuration for the logging middleware
 type LoggerConfig struct {
 	SkipPaths       []string
 	MinLatency      time.Duration
 	CustomFormatter func(param gin.LogFormatterParams) string
+	// Format is LogFormatText (the default) or LogFormatJSON
+	Format string
+	// JSON is the same as Format: LogFormatJSON
+	//
+	// Deprecated: set Format instead.
+	JSON bool
+	// Output receives the log lines; defaults to stdout
+	Output io.Writer
+}
+
+// RotatingFileConfig configures a size-rotated log file
+type RotatingFileConfig struct {
+	Path       string
+	MaxSizeMB  int // rotate once the file reaches this size
+	MaxBackups int // rotated files to keep; 0 keeps all
+	MaxAgeDays int // days to keep rotated files; 0 keeps them regardless of age
+}
+
+// NewRotatingFileWriter returns a writer that appends to the configured file and rotates it by size
+func NewRotatingFileWriter(config RotatingFileConfig) io.WriteCloser {
+	return &lumberjack.Logger{
+		Filename:   config.Path,
+		MaxSize:    config.MaxSizeMB,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     config.MaxAgeDays,
+		Compress:   true,
+	}
+}
+
+// accessLogEntry is the JSON shape of an access log line
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	LatencyMS float64   `json:"latency_ms"`
+	ClientIP  string    `json:"client_ip"`
+	Bytes     int       `json:"bytes"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
 }
 
 // Helper functions for colors and logging
@@ -178,11 +254,28 @@ func getMethodColor(method string) string {
 	}
 }
 
-func logStructuredRequest(param gin.LogFormatterParams) {
+func logJSONRequest(out io.Writer, param gin.LogFormatterParams, requestID, traceID string) {
+	entry := accessLogEntry{
+		Time:      param.TimeStamp,
+		Method:    param.Method,
+		Path:      param.Path,
+		Status:    param.StatusCode,
+		LatencyMS: float64(param.Latency) / float64(time.Millisecond),
+		ClientIP:  param.ClientIP,
+		Bytes:     param.BodySize,
+		UserAgent: param.Request.UserAgent(),
+		RequestID: requestID,
+		TraceID:   traceID,
+		Error:     param.ErrorMessage,
+	}
+	json.NewEncoder(out).Encode(entry)
+}
+
+func logStructuredRequest(out io.Writer, param gin.LogFormatterParams) {
 	statusEmoji := getStatusEmoji(param.StatusCode)
 	methodEmoji := getMethodEmoji(param.Method)
 
-	fmt.Printf("%s %s %s | %s | %v | %s | %d bytes | %s\n",
+	fmt.Fprintf(out, "%s %s %s | %s | %v | %s | %d bytes | %s\n",
 		statusEmoji,
 		methodEmoji,
 		param.Method,
@@ -195,7 +288,7 @@ func logStructuredRequest(param gin.LogFormatterParams) {
 
 	// Log error if present
 	if param.ErrorMessage != "" {
-		fmt.Printf("   ❌ Error: %s\n", param.ErrorMessage)
+		fmt.Fprintf(out, "   ❌ Error: %s\n", param.ErrorMessage)
 	}
 }
 
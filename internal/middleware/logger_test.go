@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"bytes"
+	"customable-corporate-site-api/internal/tracing"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func serveLogged(config LoggerConfig, target string) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), LoggingWithConfig(config))
+	router.GET("/*path", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("X-Request-ID", "test-request-id")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestLoggingWithConfig_Output(t *testing.T) {
+	var out bytes.Buffer
+	serveLogged(LoggerConfig{Output: &out}, "/users?page=2")
+
+	if !strings.Contains(out.String(), "/users?page=2") {
+		t.Errorf("LoggingWithConfig() output = %q, want it to contain the request path", out.String())
+	}
+}
+
+func TestLoggingWithConfig_JSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		config LoggerConfig
+	}{
+		{"Format", LoggerConfig{Format: LogFormatJSON}},
+		{"Deprecated JSON flag", LoggerConfig{JSON: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			tt.config.Output = &out
+			serveLogged(tt.config, "/users")
+
+			var entry accessLogEntry
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to decode JSON log line %q: %v", out.String(), err)
+			}
+			if entry.Method != http.MethodGet || entry.Path != "/users" || entry.Status != http.StatusOK {
+				t.Errorf("LoggingWithConfig() entry = %+v, want GET /users 200", entry)
+			}
+			if entry.RequestID != "test-request-id" {
+				t.Errorf("LoggingWithConfig() request_id = %q, want %q", entry.RequestID, "test-request-id")
+			}
+		})
+	}
+}
+
+func TestLoggingWithConfig_JSONStdout(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	serveLogged(LoggerConfig{Format: LogFormatJSON}, "/users?page=2")
+	writer.Close()
+	os.Stdout = stdout
+
+	var out bytes.Buffer
+	if _, err := out.ReadFrom(reader); err != nil {
+		t.Fatalf("Failed to read stdout: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("stdout = %q, want one JSON object per request", out.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Failed to decode JSON log line %q: %v", lines[0], err)
+	}
+	for _, key := range []string{"time", "method", "path", "status", "latency_ms", "client_ip", "request_id", "bytes"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("log line %q has no %q key", lines[0], key)
+		}
+	}
+	if entry["path"] != "/users?page=2" || entry["request_id"] != "test-request-id" {
+		t.Errorf("path = %v, request_id = %v, want /users?page=2 and test-request-id", entry["path"], entry["request_id"])
+	}
+}
+
+func TestLoggingWithConfig_SkipPaths(t *testing.T) {
+	var out bytes.Buffer
+	serveLogged(LoggerConfig{Output: &out, SkipPaths: []string{"/health"}}, "/health")
+
+	if out.Len() != 0 {
+		t.Errorf("LoggingWithConfig() logged skipped path: %q", out.String())
+	}
+}
+
+func TestNewRotatingFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	writer := NewRotatingFileWriter(RotatingFileConfig{Path: path, MaxSizeMB: 1})
+	defer writer.Close()
+
+	serveLogged(LoggerConfig{Output: writer, Format: LogFormatJSON}, "/users")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"path":"/users"`) {
+		t.Errorf("log file = %q, want the access log entry", data)
+	}
+}
+
+func TestRequestIDMiddleware_Traceparent(t *testing.T) {
+	const inbound = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name        string
+		traceparent string
+		wantTraceID string
+	}{
+		{"Inbound traceparent is continued", inbound, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"Missing traceparent starts a trace", "", ""},
+		{"Malformed traceparent starts a trace", "00-not-a-trace-01", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			var out bytes.Buffer
+			var outbound, contextRequestID string
+			router := gin.New()
+			router.Use(RequestIDMiddleware(), LoggingWithConfig(LoggerConfig{Output: &out, Format: LogFormatJSON}))
+			router.GET("/test", func(c *gin.Context) {
+				// An outbound call made while handling the request carries the trace
+				req := httptest.NewRequest(http.MethodPost, "https://hooks.example.com", nil).WithContext(c.Request.Context())
+				tracing.Inject(req)
+				outbound = req.Header.Get(tracing.Header)
+				contextRequestID = tracing.RequestIDFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.traceparent != "" {
+				req.Header.Set(tracing.Header, tt.traceparent)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			echoed, ok := tracing.Parse(w.Header().Get(tracing.Header))
+			if !ok {
+				t.Fatalf("traceparent response header = %q, want a valid traceparent", w.Header().Get(tracing.Header))
+			}
+			if tt.wantTraceID != "" && echoed.TraceID != tt.wantTraceID {
+				t.Errorf("traceparent trace id = %q, want %q", echoed.TraceID, tt.wantTraceID)
+			}
+			if tt.traceparent == inbound && (echoed.Flags != "01" || echoed.String() == inbound) {
+				t.Errorf("traceparent = %q, want a new span in the inbound sampled trace", echoed)
+			}
+			if outbound != echoed.String() {
+				t.Errorf("outbound traceparent = %q, want %q", outbound, echoed)
+			}
+			if w.Header().Get("X-Request-ID") == "" {
+				t.Errorf("X-Request-ID response header is empty")
+			}
+			if contextRequestID != w.Header().Get("X-Request-ID") {
+				t.Errorf("request context request id = %q, want %q", contextRequestID, w.Header().Get("X-Request-ID"))
+			}
+
+			var entry accessLogEntry
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to decode JSON log line %q: %v", out.String(), err)
+			}
+			if entry.TraceID != echoed.TraceID {
+				t.Errorf("access log trace_id = %q, want %q", entry.TraceID, echoed.TraceID)
+			}
+		})
+	}
+}
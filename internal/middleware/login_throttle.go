@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/store"
+	"customable-corporate-site-api/internal/utils"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginThrottleConfig holds the login throttle configuration. Attempts are counted
+// separately per email (password spraying one account) and per client IP (one source
+// trying many accounts), each over its own fixed window. A limit of zero disables that counter.
+type LoginThrottleConfig struct {
+	Store store.Store
+
+	EmailLimit  int
+	EmailWindow time.Duration
+	IPLimit     int
+	IPWindow    time.Duration
+}
+
+// DefaultLoginThrottleConfig returns the default login throttle configuration
+func DefaultLoginThrottleConfig(s store.Store) LoginThrottleConfig {
+	return LoginThrottleConfig{
+		Store:       s,
+		EmailLimit:  5,
+		EmailWindow: 15 * time.Minute,
+		IPLimit:     20,
+		IPWindow:    15 * time.Minute,
+	}
+}
+
+// LoginThrottle limits login attempts per email and per client IP with the default limits
+func LoginThrottle(s store.Store) gin.HandlerFunc {
+	return LoginThrottleWithConfig(DefaultLoginThrottleConfig(s))
+}
+
+// LoginThrottleWithConfig creates the login throttle middleware with custom configuration.
+// It responds with 429 once either counter exceeds its limit; a successful login resets
+// the counter of its email. Every response carries X-RateLimit-Limit, X-RateLimit-Remaining
+// and X-RateLimit-Reset (seconds until the window ends) for whichever counter has the
+// fewest attempts left.
+func LoginThrottleWithConfig(config LoginThrottleConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ipKey := "login:ip:" + c.ClientIP()
+		ipLimit, err := countAttempt(config.Store, ipKey, config.IPLimit, config.IPWindow)
+		if err != nil || ipLimit.exceeded() {
+			throttled(c, err, ipLimit)
+			return
+		}
+
+		email := loginEmail(c)
+		emailKey := "login:email:" + email
+		var emailLimit *rateLimit
+		if email != "" {
+			emailLimit, err = countAttempt(config.Store, emailKey, config.EmailLimit, config.EmailWindow)
+			if err != nil || emailLimit.exceeded() {
+				throttled(c, err, emailLimit)
+				return
+			}
+		}
+		setRateLimitHeaders(c, ipLimit, emailLimit)
+
+		c.Next()
+
+		if email != "" && c.Writer.Status() == http.StatusOK {
+			if err := config.Store.Delete(emailKey); err != nil {
+				c.Error(fmt.Errorf("failed to reset login throttle: %w", err))
+			}
+		}
+	}
+}
+
+// rateLimit is the state of a throttle counter after counting an attempt
+type rateLimit struct {
+	limit int
+	count int64
+	reset time.Duration
+}
+
+func (r *rateLimit) exceeded() bool {
+	return r != nil && r.count > int64(r.limit)
+}
+
+func (r *rateLimit) remaining() int64 {
+	if r.count >= int64(r.limit) {
+		return 0
+	}
+	return int64(r.limit) - r.count
+}
+
+// countAttempt counts an attempt against key. It returns nil when the limit is disabled.
+func countAttempt(s store.Store, key string, limit int, window time.Duration) (*rateLimit, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	count, err := s.Incr(key, window)
+	if err != nil {
+		return nil, err
+	}
+
+	// The counter may have expired since the increment; the window then starts over
+	reset, err := s.TTL(key)
+	if err != nil || reset <= 0 {
+		reset = window
+	}
+	return &rateLimit{limit: limit, count: count, reset: reset}, nil
+}
+
+// setRateLimitHeaders reports the counter with the fewest attempts left, ignoring disabled ones
+func setRateLimitHeaders(c *gin.Context, limits ...*rateLimit) {
+	var tightest *rateLimit
+	for _, limit := range limits {
+		if limit != nil && (tightest == nil || limit.remaining() < tightest.remaining()) {
+			tightest = limit
+		}
+	}
+	if tightest == nil {
+		return
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(tightest.limit))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(tightest.remaining(), 10))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(int64(math.Ceil(tightest.reset.Seconds())), 10))
+}
+
+// throttled rejects the request over limit, or fails it when the counters could not be updated
+func throttled(c *gin.Context, err error, limit *rateLimit) {
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to check login attempts", err)
+	} else {
+		setRateLimitHeaders(c, limit)
+		c.Header("Retry-After", c.Writer.Header().Get("X-RateLimit-Reset"))
+		utils.TooManyRequestsResponse(c, "Too many login attempts, please try again later")
+	}
+	c.Abort()
+}
+
+// loginEmail returns the normalized email of the login request body, or "" when there is none
+func loginEmail(c *gin.Context) string {
+	body, err := readBody(c)
+	if err != nil {
+		return ""
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(body, &req) != nil {
+		return ""
+	}
+	return utils.NormalizeEmail(req.Email)
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/store"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupLoginThrottle routes logins to a handler that accepts only the password "secret"
+func setupLoginThrottle(config LoginThrottleConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/login", LoginThrottleWithConfig(config), func(c *gin.Context) {
+		var req struct {
+			Password string `json:"password"`
+		}
+		c.ShouldBindJSON(&req)
+		if req.Password != "secret" {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func attemptLogin(router *gin.Engine, ip, email, password string) int {
+	body := `{"email":"` + email + `","password":"` + password + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestLoginThrottle_PerEmail(t *testing.T) {
+	router := setupLoginThrottle(LoginThrottleConfig{
+		Store:       store.NewMemoryStore(),
+		EmailLimit:  3,
+		EmailWindow: time.Minute,
+	})
+
+	// Attempts from different IPs still count against the same email, whatever its case
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		if code := attemptLogin(router, ip, "Victim@Example.com ", "wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+	if code := attemptLogin(router, "10.0.0.4", "victim@example.com", "secret"); code != http.StatusTooManyRequests {
+		t.Errorf("attempt over email limit status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	// Other emails are unaffected
+	if code := attemptLogin(router, "10.0.0.4", "other@example.com", "secret"); code != http.StatusOK {
+		t.Errorf("other email status = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestLoginThrottle_SuccessResetsEmail(t *testing.T) {
+	router := setupLoginThrottle(LoginThrottleConfig{
+		Store:       store.NewMemoryStore(),
+		EmailLimit:  2,
+		EmailWindow: time.Minute,
+	})
+
+	attemptLogin(router, "10.0.0.1", "user@example.com", "wrong")
+	if code := attemptLogin(router, "10.0.0.1", "user@example.com", "secret"); code != http.StatusOK {
+		t.Fatalf("login status = %d, want %d", code, http.StatusOK)
+	}
+
+	// The successful login reset the counter, so two more attempts are allowed
+	for i := 0; i < 2; i++ {
+		if code := attemptLogin(router, "10.0.0.1", "user@example.com", "wrong"); code != http.StatusUnauthorized {
+			t.Errorf("attempt %d after reset status = %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestLoginThrottle_PerIP(t *testing.T) {
+	router := setupLoginThrottle(LoginThrottleConfig{
+		Store:    store.NewMemoryStore(),
+		IPLimit:  3,
+		IPWindow: time.Minute,
+	})
+
+	// One source trying many emails
+	for i, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if code := attemptLogin(router, "10.0.0.1", email, "wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d status = %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+	if code := attemptLogin(router, "10.0.0.1", "d@example.com", "secret"); code != http.StatusTooManyRequests {
+		t.Errorf("attempt over IP limit status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	// Other IPs are unaffected
+	if code := attemptLogin(router, "10.0.0.2", "d@example.com", "secret"); code != http.StatusOK {
+		t.Errorf("other IP status = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestLoginThrottle_RateLimitHeaders(t *testing.T) {
+	router := setupLoginThrottle(LoginThrottleConfig{
+		Store:       store.NewMemoryStore(),
+		EmailLimit:  2,
+		EmailWindow: 200 * time.Millisecond,
+		IPLimit:     100,
+		IPWindow:    time.Minute,
+	})
+
+	attempt := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"user@example.com","password":"wrong"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = "10.0.0.1:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The email counter has fewer attempts left than the IP counter, so it is reported
+	tests := []struct {
+		wantStatus    int
+		wantRemaining string
+		wantReset     string
+	}{
+		{http.StatusUnauthorized, "1", "1"},
+		{http.StatusUnauthorized, "0", "1"},
+		{http.StatusTooManyRequests, "0", "1"},
+	}
+	for i, tt := range tests {
+		w := attempt()
+		if w.Code != tt.wantStatus {
+			t.Fatalf("attempt %d status = %d, want %d", i+1, w.Code, tt.wantStatus)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("attempt %d X-RateLimit-Limit = %q, want %q", i+1, got, "2")
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+			t.Errorf("attempt %d X-RateLimit-Remaining = %q, want %q", i+1, got, tt.wantRemaining)
+		}
+		if got := w.Header().Get("X-RateLimit-Reset"); got != tt.wantReset {
+			t.Errorf("attempt %d X-RateLimit-Reset = %q, want %q", i+1, got, tt.wantReset)
+		}
+	}
+	if got := attempt().Header().Get("Retry-After"); got != "1" {
+		t.Errorf("throttled Retry-After = %q, want %q", got, "1")
+	}
+
+	// Once the window ends the budget starts over
+	time.Sleep(250 * time.Millisecond)
+	w := attempt()
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("attempt after window status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "1" {
+		t.Errorf("attempt after window X-RateLimit-Remaining = %q, want %q", got, "1")
+	}
+}
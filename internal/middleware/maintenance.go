@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMaintenanceRetryAfter is the Retry-After sent while in maintenance mode
+const defaultMaintenanceRetryAfter = 5 * time.Minute
+
+// MaintenanceConfig holds the maintenance mode configuration
+type MaintenanceConfig struct {
+	// Enabled answers 503 to every request whose path is not allowed
+	Enabled bool
+	// Allow lists path prefixes still served in maintenance mode, such as health checks and
+	// the admin routes that switch it off again
+	Allow []string
+	// RetryAfter is sent with 503 responses (default 5m)
+	RetryAfter time.Duration
+}
+
+// Maintenance answers 503 to every request while enabled
+func Maintenance(enabled bool) gin.HandlerFunc {
+	return MaintenanceWithConfig(MaintenanceConfig{Enabled: enabled})
+}
+
+// MaintenanceWithConfig creates the maintenance mode middleware with custom configuration.
+// Requests under an allowed path prefix are served as usual.
+func MaintenanceWithConfig(config MaintenanceConfig) gin.HandlerFunc {
+	retryAfter := config.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultMaintenanceRetryAfter
+	}
+
+	return func(c *gin.Context) {
+		if !config.Enabled {
+			c.Next()
+			return
+		}
+		for _, prefix := range config.Allow {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+		utils.ErrorResponseWithCode(c, http.StatusServiceUnavailable, utils.CodeMaintenance, "The API is down for maintenance, please try again later", nil)
+		c.Abort()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaintenanceWithConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		path     string
+		wantCode int
+	}{
+		{"Disabled", false, "/api/v1/users", http.StatusOK},
+		{"Enabled", true, "/api/v1/users", http.StatusServiceUnavailable},
+		{"Enabled on an allowed path", true, "/api/v1/health/ready", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(MaintenanceWithConfig(MaintenanceConfig{Enabled: tt.enabled, Allow: []string{"/api/v1/health"}}))
+			router.GET("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusServiceUnavailable {
+				if got := w.Header().Get("Retry-After"); got != "300" {
+					t.Errorf("Retry-After = %q, want %q", got, "300")
+				}
+				if !strings.Contains(w.Body.String(), "MAINTENANCE") {
+					t.Errorf("body = %s, want the MAINTENANCE code", w.Body.String())
+				}
+			}
+		})
+	}
+}
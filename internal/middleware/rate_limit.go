@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/utils"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitSweepInterval is how often buckets of idle clients are dropped
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitConfig holds the rate limit configuration. Each client IP gets a token bucket
+// holding Burst tokens that refills at RequestsPerSecond; a request takes one token.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate allowed per client; 0 or less disables the limit
+	RequestsPerSecond float64
+	// Burst is the number of requests a client may make at once (default: RequestsPerSecond
+	// rounded up)
+	Burst int
+	// SkipPaths are not limited, e.g. health checks polled by load balancers
+	SkipPaths []string
+}
+
+// DefaultRateLimitConfig returns the default rate limit configuration
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerSecond: 10,
+		Burst:             20,
+		SkipPaths:         []string{"/api/v1/health", "/api/v1/health/ready"},
+	}
+}
+
+// tokenBucket is the state of one client's bucket as of updated
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimitStatus is a client's bucket right after a request took, or failed to take, a token
+type rateLimitStatus struct {
+	allowed bool
+	// remaining is the number of whole tokens left
+	remaining int
+	// reset is how long until the bucket is full again
+	reset time.Duration
+	// retryAfter is how long until the next token is available, when the request was refused
+	retryAfter time.Duration
+}
+
+// rateLimiter keeps a token bucket per client
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// RateLimit limits the requests of each client IP with a token bucket. Every limited response
+// carries X-RateLimit-Limit (the burst), X-RateLimit-Remaining (the tokens left) and
+// X-RateLimit-Reset (seconds until the bucket is full again). Requests over the limit are
+// answered 429 with a Retry-After header telling when the next token is available.
+func RateLimit(config RateLimitConfig) gin.HandlerFunc {
+	if config.RequestsPerSecond <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return newRateLimiter(config).handler(config.SkipPaths)
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	burst := config.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(config.RequestsPerSecond))
+	}
+	return &rateLimiter{
+		rate:    config.RequestsPerSecond,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+func (l *rateLimiter) handler(skipPaths []string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		status := l.take(c.ClientIP())
+		c.Header("X-RateLimit-Limit", strconv.Itoa(int(l.burst)))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(status.remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(status.reset)))
+
+		if !status.allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(status.retryAfter)))
+			utils.TooManyRequestsResponse(c, "Too many requests, please try again later")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// take takes a token from the client's bucket and reports the bucket's state afterwards
+func (l *rateLimiter) take(client string) rateLimitStatus {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = l.refill(bucket, now)
+	bucket.updated = now
+
+	status := rateLimitStatus{allowed: bucket.tokens >= 1}
+	if status.allowed {
+		bucket.tokens--
+	} else {
+		status.retryAfter = l.durationFor(1 - bucket.tokens)
+	}
+	status.remaining = int(bucket.tokens)
+	status.reset = l.durationFor(l.burst - bucket.tokens)
+	return status
+}
+
+// durationFor returns how long the bucket takes to refill tokens
+func (l *rateLimiter) durationFor(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// ceilSeconds rounds d up to whole seconds for headers
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// refill returns the tokens in bucket at now
+func (l *rateLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	return math.Min(l.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate)
+}
+
+// sweep drops the buckets that have refilled completely, since a full bucket is the same as
+// none. The caller must hold l.mu.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for client, bucket := range l.buckets {
+		if l.refill(bucket, now) >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupRateLimit(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler)
+	router.GET("/*path", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func serveFrom(router *gin.Engine, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_Burst(t *testing.T) {
+	const burst = 5
+	router := setupRateLimit(RateLimit(RateLimitConfig{RequestsPerSecond: 0.5, Burst: burst}))
+
+	for i := 0; i < burst; i++ {
+		if w := serveFrom(router, "/users", "192.0.2.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+	}
+
+	w := serveFrom(router, "/users", "192.0.2.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request %d status = %d, want %d", burst+1, w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+	var body struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Success || body.Message == "" {
+		t.Errorf("body = %s, want the standard error response", w.Body.String())
+	}
+
+	// Other clients have their own bucket
+	if w := serveFrom(router, "/users", "192.0.2.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("other client status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimit_Refill(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(RateLimitConfig{RequestsPerSecond: 2, Burst: 1})
+	limiter.now = func() time.Time { return now }
+
+	if status := limiter.take("client"); !status.allowed {
+		t.Fatal("take() allowed = false, want the first request allowed")
+	}
+	status := limiter.take("client")
+	if status.allowed || status.retryAfter != 500*time.Millisecond {
+		t.Fatalf("take() = %+v, want refused with a retry after 500ms", status)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if status := limiter.take("client"); !status.allowed {
+		t.Error("take() after the refill allowed = false, want true")
+	}
+
+	// Buckets that have refilled are dropped
+	now = now.Add(rateLimitSweepInterval)
+	limiter.take("other")
+	if _, ok := limiter.buckets["client"]; ok {
+		t.Error("idle bucket was not dropped")
+	}
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	router := setupRateLimit(RateLimit(RateLimitConfig{RequestsPerSecond: 0.5, Burst: 3, SkipPaths: []string{"/health"}}))
+
+	tests := []struct {
+		name          string
+		path          string
+		wantStatus    int
+		wantRemaining string
+		wantReset     string
+	}{
+		{"First request", "/users", http.StatusOK, "2", "2"},
+		{"Second request", "/users", http.StatusOK, "1", "4"},
+		{"Last token", "/users", http.StatusOK, "0", "6"},
+		{"Over the limit", "/users", http.StatusTooManyRequests, "0", "6"},
+	}
+
+	for _, tt := range tests {
+		w := serveFrom(router, tt.path, "192.0.2.1:1234")
+		if w.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("%s: X-RateLimit-Limit = %q, want %q", tt.name, got, "3")
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+			t.Errorf("%s: X-RateLimit-Remaining = %q, want %q", tt.name, got, tt.wantRemaining)
+		}
+		if got := w.Header().Get("X-RateLimit-Reset"); got != tt.wantReset {
+			t.Errorf("%s: X-RateLimit-Reset = %q, want %q", tt.name, got, tt.wantReset)
+		}
+	}
+
+	// Skipped paths are not limited, so they carry no limit headers
+	if w := serveFrom(router, "/health", "192.0.2.1:1234"); w.Header().Get("X-RateLimit-Limit") != "" {
+		t.Errorf("skipped path X-RateLimit-Limit = %q, want none", w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestRateLimit_SkipPaths(t *testing.T) {
+	router := setupRateLimit(RateLimit(RateLimitConfig{RequestsPerSecond: 1, Burst: 1, SkipPaths: []string{"/health"}}))
+
+	for i := 0; i < 3; i++ {
+		if w := serveFrom(router, "/health", "192.0.2.1:1234"); w.Code != http.StatusOK {
+			t.Errorf("skipped path request %d status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	router := setupRateLimit(RateLimit(RateLimitConfig{}))
+
+	for i := 0; i < 100; i++ {
+		if w := serveFrom(router, "/users", "192.0.2.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryConfig holds the recovery middleware configuration
+type RecoveryConfig struct {
+	// ShowErrors puts the panic message in the response; leave it off in production
+	ShowErrors bool
+	// Output receives the panic and its stack; defaults to stderr
+	Output io.Writer
+}
+
+// Recovery recovers panics and answers 500 with the standard error response, without the
+// panic message
+func Recovery() gin.HandlerFunc {
+	return RecoveryWithConfig(RecoveryConfig{})
+}
+
+// RecoveryWithConfig creates the recovery middleware with custom configuration. The panic is
+// logged with its stack and the request ID, and the client gets the standard 500 response,
+// unless the handler had already started writing one. http.ErrAbortHandler is passed on so
+// the server aborts the response as usual.
+func RecoveryWithConfig(config RecoveryConfig) gin.HandlerFunc {
+	out := config.Output
+	if out == nil {
+		out = os.Stderr
+	}
+
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			fmt.Fprintf(out, "[RECOVERY] panic in %s %s (request %s): %v\n%s",
+				c.Request.Method, c.Request.URL.Path, c.GetString("request_id"), recovered, debug.Stack())
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			var err error
+			if config.ShowErrors {
+				err = fmt.Errorf("panic: %v", recovered)
+			}
+			utils.InternalServerErrorResponse(c, "Internal Server Error", err)
+			c.Abort()
+		}()
+
+		c.Next()
+	}
+}
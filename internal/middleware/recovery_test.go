@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryWithConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		showErrors bool
+	}{
+		{"Production", false},
+		{"Development", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			var out bytes.Buffer
+			router := gin.New()
+			router.Use(RecoveryWithConfig(RecoveryConfig{ShowErrors: tt.showErrors, Output: &out}), RequestIDMiddleware())
+			router.GET("/panic", func(c *gin.Context) {
+				panic("secret database password")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+			req.Header.Set("X-Request-ID", "test-request-id")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+			}
+			var body struct {
+				Success   bool   `json:"success"`
+				RequestID string `json:"request_id"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response %q: %v", w.Body.String(), err)
+			}
+			if body.Success {
+				t.Errorf("success = true, want false")
+			}
+			if leaked := strings.Contains(w.Body.String(), "secret database password"); leaked != tt.showErrors {
+				t.Errorf("panic message in response = %v, want %v", leaked, tt.showErrors)
+			}
+			if strings.Contains(w.Body.String(), "goroutine") {
+				t.Errorf("response %q contains the stack", w.Body.String())
+			}
+
+			if !strings.Contains(out.String(), "secret database password") || !strings.Contains(out.String(), "test-request-id") || !strings.Contains(out.String(), "goroutine") {
+				t.Errorf("log = %q, want the panic, request ID and stack", out.String())
+			}
+		})
+	}
+}
+
+func TestRecovery_AfterResponseStarted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RecoveryWithConfig(RecoveryConfig{Output: &bytes.Buffer{}}))
+	router.GET("/panic", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("too late")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("response = %d %q, want the response already written left alone", w.Code, w.Body.String())
+	}
+}
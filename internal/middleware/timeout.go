@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRequestTimeout is how long a request may run before its context is cancelled
+const DefaultRequestTimeout = 30 * time.Second
+
+// TimeoutConfig holds the timeout middleware configuration
+type TimeoutConfig struct {
+	// Timeout is how long a request may run; 0 or less disables the deadline
+	Timeout time.Duration
+	// SkipPaths run without a deadline, e.g. event streams and streamed exports that are
+	// expected to outlive it
+	SkipPaths []string
+}
+
+// Timeout middleware cancels the request context after d; 0 or less disables the deadline.
+// DefaultRequestTimeout suits most APIs.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return TimeoutWithConfig(TimeoutConfig{Timeout: d})
+}
+
+// TimeoutWithConfig creates the timeout middleware with custom configuration.
+// Handlers run synchronously with a deadline on c.Request.Context(), so database calls made with
+// that context are aborted once it passes. A request that hits the deadline before writing a
+// response gets a 504.
+func TimeoutWithConfig(config TimeoutConfig) gin.HandlerFunc {
+	if config.Timeout <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), config.Timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			utils.GatewayTimeoutResponse(c, "Request timed out")
+			c.Abort()
+		}
+	}
+}
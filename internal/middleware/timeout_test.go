@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TimeoutWithConfig(TimeoutConfig{Timeout: 20 * time.Millisecond, SkipPaths: []string{"/stream"}}))
+	router.GET("/slow", func(c *gin.Context) {
+		// Stands in for a query that is aborted when the request context is cancelled
+		<-c.Request.Context().Done()
+	})
+	router.GET("/sleepy", func(c *gin.Context) {
+		// Handlers that ignore the context are not interrupted, but still answered 504 when done
+		time.Sleep(40 * time.Millisecond)
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); ok {
+			t.Errorf("skipped path has a deadline")
+		}
+		time.Sleep(40 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); !ok {
+			t.Errorf("request context has no deadline")
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"Deadline exceeded", "/slow", http.StatusGatewayTimeout},
+		{"Deadline exceeded ignoring the context", "/sleepy", http.StatusGatewayTimeout},
+		{"Within deadline", "/fast", http.StatusOK},
+		{"Skipped path", "/stream", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTimeout_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Timeout(0))
+	router.GET("/test", func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); ok {
+			t.Errorf("request context has a deadline with the timeout disabled")
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/tracing"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by this package
+const tracerName = "customable-corporate-site-api/internal/middleware"
+
+// Tracing starts a server span for each request, named by method and route template and
+// continuing the caller's traceparent. The span's trace replaces the one set up by
+// RequestIDMiddleware, so the response header, access logs and outbound calls all carry it.
+// Only install it when a tracer provider is configured; without it there is no tracing overhead.
+func Tracing(provider trace.TracerProvider) gin.HandlerFunc {
+	tracer := provider.Tracer(tracerName)
+	propagator := propagation.TraceContext{}
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		spanContext := span.SpanContext()
+		traceparent := tracing.Traceparent{
+			TraceID: spanContext.TraceID().String(),
+			SpanID:  spanContext.SpanID().String(),
+			Flags:   spanContext.TraceFlags().String(),
+		}
+		c.Request = c.Request.WithContext(tracing.NewContext(ctx, traceparent))
+		c.Set("trace_id", traceparent.TraceID)
+		c.Header(tracing.Header, traceparent.String())
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if userID, exists := c.Get("user_id"); exists {
+			span.SetAttributes(attribute.String("enduser.id", fmt.Sprint(userID)))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
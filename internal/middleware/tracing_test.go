@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"customable-corporate-site-api/internal/tracing"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), Tracing(provider))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Set("user_id", uint(42))
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.Header.Set(tracing.Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Tracing() recorded %d spans, want 1", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "GET /users/:id" {
+		t.Errorf("span name = %q, want %q", span.Name, "GET /users/:id")
+	}
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("span trace id = %q, want the inbound trace", got)
+	}
+	if got := span.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("span parent id = %q, want the inbound parent", got)
+	}
+
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	if got := attributes["http.response.status_code"].AsInt64(); got != http.StatusOK {
+		t.Errorf("http.response.status_code = %d, want %d", got, http.StatusOK)
+	}
+	if got := attributes["enduser.id"].AsString(); got != "42" {
+		t.Errorf("enduser.id = %q, want %q", got, "42")
+	}
+
+	// The response header names the span, so callers can find it
+	traceparent, _ := tracing.Parse(w.Header().Get(tracing.Header))
+	if traceparent.SpanID != span.SpanContext.SpanID().String() {
+		t.Errorf("traceparent = %q, want span %s", w.Header().Get(tracing.Header), span.SpanContext.SpanID())
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Audit actions
+const (
+	AuditActionUserUpdated  = "user.updated"
+	AuditActionUserDeleted  = "user.deleted"
+	AuditActionUserRestored = "user.restored"
+	AuditActionUsersPurged  = "users.purged"
+	// AuditActionUsersStatusUpdated records a bulk status update; it has no single target
+	AuditActionUsersStatusUpdated = "users.status_updated"
+
+	AuditActionUserTokensRevoked = "user.tokens_revoked"
+)
+
+// AuditLog records an administrative action taken by a user
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ActorID    uint      `json:"actor_id" gorm:"not null;index"`
+	Action     string    `json:"action" gorm:"not null;index"`
+	TargetType string    `json:"target_type" gorm:"index:idx_audit_logs_target"`
+	TargetID   uint      `json:"target_id" gorm:"index:idx_audit_logs_target"`
+	Details    string    `json:"details"` // JSON describing the change
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// AuditLogEntry is an audit log entry as listed to admins, with the actor's email joined in.
+// ActorEmail is empty when the actor has since been purged.
+type AuditLogEntry struct {
+	AuditLog
+	ActorEmail string `json:"actor_email"`
+}
+
+// TableName sets the insert table name for this struct type
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
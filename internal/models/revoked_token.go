@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RevokedToken records a token revoked before it expired, by its jti. Entries are only needed
+// until ExpiresAt, after which the token is rejected anyway.
+type RevokedToken struct {
+	TokenID   string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName sets the insert table name for this struct type
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Session represents a refresh token issued to a user on a specific device
+type Session struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	TokenID    string     `json:"-" gorm:"uniqueIndex;not null"` // jti of the current refresh token
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	RememberMe bool       `json:"remember_me" gorm:"not null;default:false"` // login asked for the longer refresh lifetime
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" gorm:"index"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// IsActive checks if the session has neither been revoked nor expired
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// TableName sets the insert table name for this struct type
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// SessionResponse represents the session data returned in API responses
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	RememberMe bool      `json:"remember_me"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ToResponse converts Session model to SessionResponse, with timestamps in UTC
+func (s *Session) ToResponse() *SessionResponse {
+	return &SessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		RememberMe: s.RememberMe,
+		LastUsedAt: s.LastUsedAt.UTC(),
+		ExpiresAt:  s.ExpiresAt.UTC(),
+		CreatedAt:  s.CreatedAt.UTC(),
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,6 +19,19 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// PendingEmail replaces Email once the user confirms it; until then they log in with Email
+	PendingEmail string `json:"-"`
+	// TokenVersion is embedded in the user's tokens; incrementing it invalidates every token
+	// issued before
+	TokenVersion uint `json:"-" gorm:"not null;default:0"`
+	// FailedLoginAttempts counts consecutive failed logins; reaching the lockout threshold
+	// sets LockedUntil and starts the count over
+	FailedLoginAttempts int        `json:"-" gorm:"not null;default:0"`
+	LockedUntil         *time.Time `json:"-"`
+	// EmailVerified is set once the user opens the verification link sent at registration
+	EmailVerified   bool       `json:"email_verified" gorm:"not null;default:false"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
 }
 
 // User roles constants
@@ -27,11 +41,49 @@ const (
 	RoleUser   = "user"
 )
 
+// Roles lists every user role
+var Roles = []string{RoleAdmin, RoleEditor, RoleUser}
+
+// IsValidRole reports whether role is one of Roles
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordCost is the bcrypt cost used to hash passwords. Hashes made with another cost
+// are upgraded the next time their user logs in. Set it at startup with SetBcryptCost.
+var PasswordCost = bcrypt.DefaultCost
+
+// ErrInvalidBcryptCost is returned by SetBcryptCost for a cost bcrypt does not support
+var ErrInvalidBcryptCost = errors.New("bcrypt cost must be between 4 and 31")
+
+// SetBcryptCost sets the cost of the password hashes made from now on. Higher costs make
+// hashes slower to crack and logins slower; tests can use bcrypt.MinCost to run faster.
+func SetBcryptCost(cost int) error {
+	if err := ValidateBcryptCost(cost); err != nil {
+		return err
+	}
+	PasswordCost = cost
+	return nil
+}
+
+// ValidateBcryptCost returns ErrInvalidBcryptCost unless cost is within bcrypt's supported range
+func ValidateBcryptCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return ErrInvalidBcryptCost
+	}
+	return nil
+}
+
 // BeforeCreate hook to hash password before saving
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	// Hash the password if it's not empty
 	if u.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), PasswordCost)
 		if err != nil {
 			return err
 		}
@@ -46,28 +98,66 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	return nil
 }
 
-// BeforeUpdate hook to hash password if it has changed
+// BeforeUpdate hook to hash a new password, whether it comes with the saved user or is set
+// with Update("password", ...). Passwords that already are bcrypt hashes are kept, so saving
+// a loaded user does not hash its hash again.
 func (u *User) BeforeUpdate(tx *gorm.DB) (err error) {
-	// Only hash the password if it has been changed
-	if tx.Statement.Changed("Password") && u.Password != "" {
-		// Check if it's already hashed (bcrypt hashed passwords start with $2a$, $2b$, $2x$ or $2y$)
-		if len(u.Password) < 60 || (u.Password[:4] != "$2a$" && u.Password[:4] != "$2b$" && u.Password[:4] != "$2x$" && u.Password[:4] != "$2y$") {
-			hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
-			if err != nil {
-				return err
+	switch dest := tx.Statement.Dest.(type) {
+	case *User:
+		// Save passes the user itself; Updates may pass another one with the new values
+		return hashPlainPassword(&dest.Password)
+	case map[string]interface{}:
+		for _, key := range []string{"password", "Password"} {
+			if password, ok := dest[key].(string); ok {
+				if err := hashPlainPassword(&password); err != nil {
+					return err
+				}
+				dest[key] = password
 			}
-			u.Password = string(hashedPassword)
 		}
 	}
 	return nil
 }
 
+// hashPlainPassword replaces a plain text password with its hash at PasswordCost
+func hashPlainPassword(password *string) error {
+	// Check if it's already hashed (bcrypt hashed passwords start with $2a$, $2b$, $2x$ or $2y$)
+	p := *password
+	if p == "" || (len(p) >= 60 && (p[:4] == "$2a$" || p[:4] == "$2b$" || p[:4] == "$2x$" || p[:4] == "$2y$")) {
+		return nil
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(p), PasswordCost)
+	if err != nil {
+		return err
+	}
+	*password = string(hashedPassword)
+	return nil
+}
+
 // CheckPassword verifies the provided password against the stored hash
 func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
 
+// NeedsRehash reports whether the stored hash was made with a cost other than PasswordCost.
+// The cost is read from the hash itself, so the check is cheap.
+func (u *User) NeedsRehash() bool {
+	cost, err := bcrypt.Cost([]byte(u.Password))
+	return err != nil || cost != PasswordCost
+}
+
+// SetPassword replaces the stored hash with a hash of password at PasswordCost
+func (u *User) SetPassword(password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
+	if err != nil {
+		return err
+	}
+	u.Password = string(hashedPassword)
+	return nil
+}
+
 // GetFullName returns the user's full name
 func (u *User) GetFullName() string {
 	return u.FirstName + " " + u.LastName
@@ -88,11 +178,24 @@ func (u *User) IsUser() bool {
 	return u.Role == RoleUser
 }
 
+// IsLocked reports whether logins are refused at the given time after repeated failures
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
 // TableName sets the insert table name for this struct type
 func (User) TableName() string {
 	return "users"
 }
 
+// UserStats summarizes the user base for the admin dashboard
+type UserStats struct {
+	Total       int64            `json:"total"`
+	Active      int64            `json:"active"`
+	NewThisWeek int64            `json:"new_this_week"`
+	ByRole      map[string]int64 `json:"by_role"`
+}
+
 // UserResponse represents the user data returned in API responses
 type UserResponse struct {
 	ID        uint      `json:"id"`
@@ -104,9 +207,14 @@ type UserResponse struct {
 	IsActive  bool      `json:"is_active"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	EmailVerified bool `json:"email_verified"`
+
+	// PendingEmail is the new email awaiting confirmation, if a change was requested
+	PendingEmail string `json:"pending_email,omitempty"`
 }
 
-// ToResponse converts User model to UserResponse
+// ToResponse converts User model to UserResponse, with timestamps in UTC
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
 		ID:        u.ID,
@@ -116,7 +224,11 @@ func (u *User) ToResponse() *UserResponse {
 		FullName:  u.GetFullName(),
 		Role:      u.Role,
 		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		CreatedAt: u.CreatedAt.UTC(),
+		UpdatedAt: u.UpdatedAt.UTC(),
+
+		EmailVerified: u.EmailVerified,
+
+		PendingEmail: u.PendingEmail,
 	}
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/glebarez/sqlite"
@@ -49,6 +50,81 @@ func TestUserPasswordHashing(t *testing.T) {
 	}
 }
 
+func TestSetBcryptCost(t *testing.T) {
+	defer func(cost int) { PasswordCost = cost }(PasswordCost)
+
+	tests := []struct {
+		name    string
+		cost    int
+		wantErr error
+	}{
+		{"Minimum cost", bcrypt.MinCost, nil},
+		{"Custom cost", bcrypt.MinCost + 1, nil},
+		{"Below minimum", bcrypt.MinCost - 1, ErrInvalidBcryptCost},
+		{"Above maximum", bcrypt.MaxCost + 1, ErrInvalidBcryptCost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := PasswordCost
+			err := SetBcryptCost(tt.cost)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetBcryptCost(%d) error = %v, want %v", tt.cost, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if PasswordCost != before {
+					t.Errorf("PasswordCost = %d after a rejected cost, want %d", PasswordCost, before)
+				}
+				return
+			}
+
+			db := setupTestDB(t)
+			user := &User{Email: "test@example.com", Password: "password123", FirstName: "Test", LastName: "User"}
+			if err := db.Create(user).Error; err != nil {
+				t.Fatalf("Failed to create user in test database: %v", err)
+			}
+			if cost, err := bcrypt.Cost([]byte(user.Password)); err != nil || cost != tt.cost {
+				t.Errorf("bcrypt.Cost() of created hash = %d, %v, want %d", cost, err, tt.cost)
+			}
+
+			// Saving the hash again must not hash it twice
+			hash := user.Password
+			if err := db.Model(user).Update("first_name", "Changed").Error; err != nil {
+				t.Fatalf("Failed to update user: %v", err)
+			}
+			if err := db.Save(user).Error; err != nil {
+				t.Fatalf("Failed to save user: %v", err)
+			}
+			if user.Password != hash {
+				t.Errorf("Password hash changed on save without a new password")
+			}
+
+			// A new password is hashed at the configured cost too, however it is updated
+			for _, update := range []struct {
+				password string
+				apply    func(password string) error
+			}{
+				{"newpassword456", func(password string) error {
+					user.Password = password
+					return db.Save(user).Error
+				}},
+				{"newpassword789", func(password string) error {
+					return db.Model(user).Update("password", password).Error
+				}},
+			} {
+				if err := update.apply(update.password); err != nil {
+					t.Fatalf("Failed to update password: %v", err)
+				}
+				var found User
+				db.First(&found, user.ID)
+				if cost, err := bcrypt.Cost([]byte(found.Password)); err != nil || cost != tt.cost || !found.CheckPassword(update.password) {
+					t.Errorf("bcrypt.Cost() of updated hash = %d, %v, want %d matching the new password", cost, err, tt.cost)
+				}
+			}
+		})
+	}
+}
+
 func TestUserCheckPassword(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -0,0 +1,176 @@
+package preflight
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/config"
+	"customable-corporate-site-api/internal/database"
+	"customable-corporate-site-api/internal/middleware"
+	"customable-corporate-site-api/internal/models"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultJWTSecret is the placeholder secret config.Load falls back to
+const defaultJWTSecret = "your_jwt_secret_key"
+
+// defaultPingTimeout bounds the database connectivity check
+const defaultPingTimeout = 5 * time.Second
+
+// Pinger checks database connectivity. *sql.DB satisfies it; tests inject fakes.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Report lists the problems found by Check. Errors must stop the process; warnings are only logged.
+type Report struct {
+	Errors   []string
+	Warnings []string
+}
+
+func (r *Report) errorf(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) warnf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Err returns a single error listing every error in the report, or nil when there are none
+func (r *Report) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return fmt.Errorf("preflight check found %d problem(s):\n  - %s", len(r.Errors), strings.Join(r.Errors, "\n  - "))
+}
+
+// LogWarnings logs each warning in the report
+func (r *Report) LogWarnings() {
+	for _, warning := range r.Warnings {
+		log.Printf("Warning: %s", warning)
+	}
+}
+
+// Check validates the configuration and, when db is not nil, that the database is reachable.
+// Every check runs, so the report lists all problems at once.
+func Check(ctx context.Context, cfg *config.Config, db Pinger) *Report {
+	report := &Report{}
+
+	checkServer(report, cfg)
+	checkJWT(report, cfg)
+	checkCORS(report, cfg)
+	checkFeatures(report, cfg)
+	checkDatabase(ctx, report, cfg, db)
+
+	return report
+}
+
+func checkServer(report *Report, cfg *config.Config) {
+	if cfg.Server.Mode != "development" && cfg.Server.Mode != "production" {
+		report.errorf("invalid SERVER_MODE %q: must be 'development' or 'production'", cfg.Server.Mode)
+	}
+}
+
+func checkJWT(report *Report, cfg *config.Config) {
+	switch cfg.JWT.Algorithm {
+	case "HS256":
+		if cfg.JWT.Secret == defaultJWTSecret {
+			if cfg.Server.Mode == "production" {
+				report.errorf("JWT_SECRET must be set in production; the default secret is public")
+			} else {
+				report.warnf("using the default JWT secret; set JWT_SECRET before deploying")
+			}
+		}
+	case "RS256":
+		if cfg.JWT.PrivateKeyPath == "" {
+			report.errorf("JWT_PRIVATE_KEY_PATH is required when JWT_ALG is RS256")
+		} else if _, err := os.Stat(cfg.JWT.PrivateKeyPath); err != nil {
+			report.errorf("JWT_PRIVATE_KEY_PATH is not readable: %v", err)
+		}
+		if cfg.JWT.PublicKeyPath != "" {
+			if _, err := os.Stat(cfg.JWT.PublicKeyPath); err != nil {
+				report.errorf("JWT_PUBLIC_KEY_PATH is not readable: %v", err)
+			}
+		}
+	default:
+		report.errorf("invalid JWT_ALG %q: must be 'HS256' or 'RS256'", cfg.JWT.Algorithm)
+	}
+
+	if len(cfg.JWT.Keys) > 0 {
+		if _, ok := cfg.JWT.Keys[cfg.JWT.ActiveKeyID]; !ok {
+			report.errorf("invalid JWT_ACTIVE_KEY_ID %q: must be one of the key IDs in JWT_KEYS", cfg.JWT.ActiveKeyID)
+		}
+	} else if cfg.JWT.ActiveKeyID != "" {
+		report.errorf("JWT_ACTIVE_KEY_ID is set but JWT_KEYS is empty")
+	}
+}
+
+func checkCORS(report *Report, cfg *config.Config) {
+	corsConfig := middleware.CORSConfig{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+	}
+	if err := corsConfig.Validate(); err != nil {
+		report.errorf("invalid CORS configuration: %v", err)
+	}
+}
+
+// checkFeatures checks that optional features which are turned on have what they need
+func checkFeatures(report *Report, cfg *config.Config) {
+	switch cfg.Captcha.Provider {
+	case "":
+	case "recaptcha", "hcaptcha", "turnstile":
+		if cfg.Captcha.Secret == "" {
+			report.errorf("CAPTCHA_SECRET is required when CAPTCHA_PROVIDER is set")
+		}
+	default:
+		report.errorf("invalid CAPTCHA_PROVIDER %q: must be 'recaptcha', 'hcaptcha' or 'turnstile'", cfg.Captcha.Provider)
+	}
+
+	if cfg.Mail.Host == "" && cfg.Server.Mode == "production" {
+		report.warnf("SMTP_HOST is not set; emails such as email change confirmations are logged instead of sent")
+	}
+
+	if err := models.ValidateBcryptCost(cfg.Password.BcryptCost); err != nil {
+		report.errorf("invalid BCRYPT_COST %d: %v", cfg.Password.BcryptCost, err)
+	}
+	// Shorter passwords are refused by the request bindings anyway
+	if cfg.Password.MinLength < 6 {
+		report.errorf("invalid PASSWORD_MIN_LENGTH %d: must be at least 6", cfg.Password.MinLength)
+	}
+
+	if len(cfg.APIKeys.Keys) > 0 && !models.IsValidRole(cfg.APIKeys.Role) {
+		report.errorf("invalid API_KEY_ROLE %q: must be 'admin', 'editor' or 'user'", cfg.APIKeys.Role)
+	}
+
+	if cfg.Retention.PurgeDeletedUsers && cfg.Retention.DeletedUserMaxAge <= 0 {
+		report.errorf("DELETED_USER_RETENTION_DAYS must be at least 1 when PURGE_DELETED_USERS is enabled")
+	}
+}
+
+func checkDatabase(ctx context.Context, report *Report, cfg *config.Config, db Pinger) {
+	if cfg.Database.Host == "your_db_host" || cfg.Database.User == "your_user" || cfg.Database.DBName == "your_db_name" {
+		report.errorf("database configuration is incomplete: set DB_HOST, DB_USER and DB_NAME")
+	}
+	if cfg.Database.LogLevel != "" {
+		if _, err := database.ParseLogLevel(cfg.Database.LogLevel); err != nil {
+			report.errorf("invalid DB_LOG_LEVEL: %v", err)
+		}
+	}
+	if cfg.Database.SlowThreshold < 0 {
+		report.errorf("DB_SLOW_THRESHOLD must not be negative")
+	}
+
+	if db == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultPingTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		report.errorf("database %s:%s is unreachable: %v", cfg.Database.Host, cfg.Database.Port, err)
+	}
+}
@@ -0,0 +1,184 @@
+package preflight
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/config"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePinger answers pings with err
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) PingContext(ctx context.Context) error {
+	return p.err
+}
+
+// validConfig returns a configuration that passes every check
+func validConfig() *config.Config {
+	return &config.Config{
+		Server:   config.ServerConfig{Mode: "production"},
+		Database: config.DatabaseConfig{Host: "db.internal", Port: "5432", User: "api", DBName: "site"},
+		JWT:      config.JWTConfig{Secret: "a-long-random-secret", Algorithm: "HS256"},
+		CORS:     config.CORSConfig{AllowedOrigins: []string{"*"}},
+		Mail:     config.MailConfig{Host: "smtp.internal"},
+		Password: config.PasswordConfig{BcryptCost: 12, MinLength: 8},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		modify       func(cfg *config.Config)
+		pingErr      error
+		wantErrors   []string
+		wantWarnings int
+	}{
+		{
+			name:   "Valid configuration",
+			modify: func(cfg *config.Config) {},
+		},
+		{
+			name:       "Default secret in production",
+			modify:     func(cfg *config.Config) { cfg.JWT.Secret = defaultJWTSecret },
+			wantErrors: []string{"JWT_SECRET must be set in production"},
+		},
+		{
+			name: "Default secret in development",
+			modify: func(cfg *config.Config) {
+				cfg.Server.Mode = "development"
+				cfg.JWT.Secret = defaultJWTSecret
+			},
+			wantWarnings: 1,
+		},
+		{
+			name:       "Unreachable database",
+			modify:     func(cfg *config.Config) {},
+			pingErr:    errors.New("connection refused"),
+			wantErrors: []string{"database db.internal:5432 is unreachable: connection refused"},
+		},
+		{
+			name: "Every problem is reported",
+			modify: func(cfg *config.Config) {
+				cfg.Server.Mode = "staging"
+				cfg.JWT.Algorithm = "RS256"
+				cfg.CORS.AllowCredentials = true
+				cfg.Captcha.Provider = "recaptcha"
+				cfg.Retention.PurgeDeletedUsers = true
+			},
+			pingErr: errors.New("connection refused"),
+			wantErrors: []string{
+				"invalid SERVER_MODE",
+				"JWT_PRIVATE_KEY_PATH is required",
+				"invalid CORS configuration",
+				"CAPTCHA_SECRET is required",
+				"DELETED_USER_RETENTION_DAYS must be at least 1",
+				"is unreachable",
+			},
+		},
+		{
+			name: "Invalid API key role",
+			modify: func(cfg *config.Config) {
+				cfg.APIKeys.Keys = []string{"integration-key"}
+				cfg.APIKeys.Role = "superuser"
+			},
+			wantErrors: []string{"invalid API_KEY_ROLE"},
+		},
+		{
+			name:       "Bcrypt cost out of range",
+			modify:     func(cfg *config.Config) { cfg.Password.BcryptCost = 32 },
+			wantErrors: []string{"invalid BCRYPT_COST"},
+		},
+		{
+			name:       "Password minimum length below the binding minimum",
+			modify:     func(cfg *config.Config) { cfg.Password.MinLength = 4 },
+			wantErrors: []string{"invalid PASSWORD_MIN_LENGTH"},
+		},
+		{
+			name: "Missing key file",
+			modify: func(cfg *config.Config) {
+				cfg.JWT.Algorithm = "RS256"
+				cfg.JWT.PrivateKeyPath = "/nonexistent/private.pem"
+			},
+			wantErrors: []string{"JWT_PRIVATE_KEY_PATH is not readable"},
+		},
+		{
+			name: "Active key not in the key set",
+			modify: func(cfg *config.Config) {
+				cfg.JWT.Keys = map[string]string{"2024": "secret"}
+				cfg.JWT.ActiveKeyID = "2025"
+			},
+			wantErrors: []string{"invalid JWT_ACTIVE_KEY_ID"},
+		},
+		{
+			name:       "Placeholder database settings",
+			modify:     func(cfg *config.Config) { cfg.Database.User = "your_user" },
+			wantErrors: []string{"database configuration is incomplete"},
+		},
+		{
+			name:         "Emails logged in production",
+			modify:       func(cfg *config.Config) { cfg.Mail.Host = "" },
+			wantWarnings: 1,
+		},
+		{
+			name:       "Invalid database log level",
+			modify:     func(cfg *config.Config) { cfg.Database.LogLevel = "debug" },
+			wantErrors: []string{"invalid DB_LOG_LEVEL"},
+		},
+		{
+			name: "Retention enabled with a max age",
+			modify: func(cfg *config.Config) {
+				cfg.Retention.PurgeDeletedUsers = true
+				cfg.Retention.DeletedUserMaxAge = 30 * 24 * time.Hour
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			report := Check(context.Background(), cfg, fakePinger{err: tt.pingErr})
+			if len(report.Errors) != len(tt.wantErrors) {
+				t.Fatalf("Check() errors = %q, want %d errors", report.Errors, len(tt.wantErrors))
+			}
+			for i, want := range tt.wantErrors {
+				if !strings.Contains(report.Errors[i], want) {
+					t.Errorf("Check() error %d = %q, want it to contain %q", i, report.Errors[i], want)
+				}
+			}
+			if len(report.Warnings) != tt.wantWarnings {
+				t.Errorf("Check() warnings = %q, want %d warnings", report.Warnings, tt.wantWarnings)
+			}
+
+			if err := report.Err(); (err != nil) != (len(tt.wantErrors) > 0) {
+				t.Errorf("Err() = %v, want an error only when there are errors", err)
+			}
+		})
+	}
+}
+
+func TestReport_Err(t *testing.T) {
+	report := &Report{Errors: []string{"first problem", "second problem"}}
+
+	err := report.Err()
+	if err == nil {
+		t.Fatal("Err() = nil, want an error")
+	}
+	for _, want := range []string{"2 problem(s)", "  - first problem", "  - second problem"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Err() = %q, want it to contain %q", err.Error(), want)
+		}
+	}
+}
+
+func TestCheck_WithoutDatabase(t *testing.T) {
+	if err := Check(context.Background(), validConfig(), nil).Err(); err != nil {
+		t.Errorf("Check() without a database error = %v, want nil", err)
+	}
+}
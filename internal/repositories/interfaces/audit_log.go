@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"time"
+)
+
+// AuditLogFilter narrows an audit log search; zero fields match every entry
+type AuditLogFilter struct {
+	ActorID uint
+	Action  string
+	// From and To bound the entry's creation time, both inclusive
+	From *time.Time
+	To   *time.Time
+}
+
+// AuditLogRepository defines the interface for audit log operations
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *models.AuditLog) error
+	ListRecent(ctx context.Context, limit int) ([]models.AuditLog, error)
+
+	// Search returns a page of the entries matching filter, newest first, along with the total number of matches
+	Search(ctx context.Context, filter AuditLogFilter, offset, limit int) ([]models.AuditLogEntry, int64, error)
+	// SearchAll returns every entry matching filter, newest first
+	SearchAll(ctx context.Context, filter AuditLogFilter) ([]models.AuditLogEntry, error)
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+)
+
+// SessionRepository defines the interface for refresh token session operations
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByTokenID(ctx context.Context, tokenID string) (*models.Session, error)
+	Update(ctx context.Context, session *models.Session) error
+
+	// ListActiveByUser returns the user's sessions that are neither revoked nor expired
+	ListActiveByUser(ctx context.Context, userID uint) ([]models.Session, error)
+
+	// Revoke revokes a single session owned by the user, returning gorm.ErrRecordNotFound otherwise
+	Revoke(ctx context.Context, id, userID uint) error
+	// RevokeAllByUser revokes every active session of the user and returns how many were revoked
+	RevokeAllByUser(ctx context.Context, userID uint) (int64, error)
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// TokenBlacklist records revoked tokens by their jti until they expire
+type TokenBlacklist interface {
+	// Revoke records the token as revoked until expiresAt; revoking it again is not an error
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	// IsRevoked reports whether the token was revoked and has not expired yet
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	// DeleteExpired removes the entries of expired tokens and returns how many were removed
+	DeleteExpired(ctx context.Context) (int64, error)
+}
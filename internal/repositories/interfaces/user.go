@@ -1,26 +1,96 @@
 package interfaces
 
-import "customable-corporate-site-api/internal/models"
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"errors"
+	"time"
+)
 
-// UserRepository defines the interface for user data operations
+// ErrInvalidUserSort is returned by UserRepository.Query for a SortBy outside UserSortFields
+// or a SortDir other than asc or desc
+var ErrInvalidUserSort = errors.New("invalid user sort")
+
+// UserSortFields are the columns UserRepository.Query may sort by
+var UserSortFields = []string{"created_at", "email", "last_name"}
+
+// UserFilter narrows, orders and pages a user query; zero fields match every user
+type UserFilter struct {
+	Role     string
+	IsActive *bool
+	// Search matches first name, last name or email, ignoring case
+	Search string
+	// SortBy is one of UserSortFields (default created_at)
+	SortBy string
+	// SortDir is asc or desc (default desc)
+	SortDir string
+	// Limit caps the page size; 0 or less returns every match
+	Limit  int
+	Offset int
+}
+
+// UserRepository defines the interface for user data operations.
+// Methods take the caller's context so cancellation and deadlines reach the database.
 type UserRepository interface {
 	// Basic CRUD operations
-	Create(user *models.User) error
-	GetByID(id uint) (*models.User, error)
-	GetByEmail(email string) (*models.User, error)
-	Update(user *models.User) error
-	Delete(id uint) error
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id uint) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id uint) error
 
 	// Query operations
-	List(offset, limit int) ([]models.User, error)
-	Count() (int64, error)
+	List(ctx context.Context, offset, limit int) ([]models.User, error)
+	Count(ctx context.Context) (int64, error)
 
 	// Advanced queries
-	GetActiveUsers(limit, offset int) ([]models.User, error)
-	GetUsersByRole(role string, limit, offset int) ([]models.User, error)
-	SearchUsers(query string, limit, offset int) ([]models.User, error)
+	GetActiveUsers(ctx context.Context, limit, offset int) ([]models.User, error)
+	GetUsersByRole(ctx context.Context, role string, limit, offset int) ([]models.User, error)
+	SearchUsers(ctx context.Context, query string, limit, offset int) ([]models.User, error)
+
+	// Paginated queries returning the page together with the total matching the same filter.
+	// order is an ORDER BY clause built from a whitelist of columns, such as
+	// utils.PageRequest.OrderClause; empty orders newest first.
+	ListWithCount(ctx context.Context, offset, limit int, order string) ([]models.User, int64, error)
+	GetActiveUsersWithCount(ctx context.Context, limit, offset int, order string) ([]models.User, int64, error)
+	GetUsersByRoleWithCount(ctx context.Context, role string, limit, offset int, order string) ([]models.User, int64, error)
+	SearchUsersWithCount(ctx context.Context, query string, limit, offset int, order string) ([]models.User, int64, error)
+	// ListAfter pages through users by ID: it returns up to limit users with an ID above
+	// cursorID and the cursor of the next page, which is 0 on the last page
+	ListAfter(ctx context.Context, cursorID uint, limit int) ([]models.User, uint, error)
+	// Query returns the page of users matching filter along with the total number of matches
+	Query(ctx context.Context, filter UserFilter) ([]models.User, int64, error)
 
 	// Bulk operations
-	UpdateUserStatus(id uint, isActive bool) error
-	UpdateUserRole(id uint, role string) error
+	UpdateUserStatus(ctx context.Context, id uint, isActive bool) error
+	// BulkUpdateStatus sets the active status of the users in one statement and returns how
+	// many changed. Deactivated users also have their token version incremented.
+	BulkUpdateStatus(ctx context.Context, ids []uint, isActive bool) (int64, error)
+	UpdateUserRole(ctx context.Context, id uint, role string) error
+	// IncrementTokenVersion invalidates every token issued to the user so far
+	IncrementTokenVersion(ctx context.Context, id uint) error
+	// RecordFailedLogin counts a failed login; the maxAttempts-th consecutive failure locks
+	// the account until lockedUntil and resets the count
+	RecordFailedLogin(ctx context.Context, id uint, maxAttempts int, lockedUntil time.Time) error
+	// ResetFailedLogins clears the failed login count and any lock
+	ResetFailedLogins(ctx context.Context, id uint) error
+	// UpdateAudited saves the user, increments their token version when revokeTokens is set
+	// and records entry, if any, in one transaction
+	UpdateAudited(ctx context.Context, user *models.User, revokeTokens bool, entry *models.AuditLog) error
+	// DeleteMany soft-deletes the users and records the audit entries in one transaction.
+	// Admins whose deletion would leave no admin are kept instead; their IDs are returned
+	// and their audit entries are not written.
+	DeleteMany(ctx context.Context, ids []uint, entries []models.AuditLog) ([]uint, error)
+
+	// Aggregates
+	Stats(ctx context.Context, newSince time.Time) (*models.UserStats, error)
+
+	// Soft deletion
+	// ListDeletedWithCount pages through soft-deleted users; empty order lists the most recently deleted first
+	ListDeletedWithCount(ctx context.Context, offset, limit int, order string) ([]models.User, int64, error)
+	// Restore undoes a soft delete, returning gorm.ErrRecordNotFound when no deleted user has the ID
+	Restore(ctx context.Context, id uint) error
+
+	// Retention
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
 }
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+	"sync"
+	"time"
+)
+
+type tokenBlacklist struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	now     func() time.Time
+}
+
+// NewTokenBlacklist creates an in-process TokenBlacklist. Revocations are not shared between
+// instances and are lost on restart, so it suits tests and single-instance development.
+func NewTokenBlacklist() interfaces.TokenBlacklist {
+	return &tokenBlacklist{
+		revoked: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Revoke records the token as revoked until expiresAt
+func (b *tokenBlacklist) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if expiresAt.After(b.revoked[tokenID]) {
+		b.revoked[tokenID] = expiresAt
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token was revoked and has not expired yet
+func (b *tokenBlacklist) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	expiresAt, ok := b.revoked[tokenID]
+	return ok && b.now().Before(expiresAt), nil
+}
+
+// DeleteExpired removes the entries of expired tokens
+func (b *tokenBlacklist) DeleteExpired(ctx context.Context) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	var deleted int64
+	for tokenID, expiresAt := range b.revoked {
+		if !now.Before(expiresAt) {
+			delete(b.revoked, tokenID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
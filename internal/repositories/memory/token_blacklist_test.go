@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBlacklist(t *testing.T) {
+	now := time.Now()
+	blacklist := NewTokenBlacklist().(*tokenBlacklist)
+	blacklist.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	blacklist.Revoke(ctx, "short", now.Add(time.Minute))
+	blacklist.Revoke(ctx, "long", now.Add(time.Hour))
+
+	for _, tokenID := range []string{"short", "long"} {
+		if revoked, _ := blacklist.IsRevoked(ctx, tokenID); !revoked {
+			t.Errorf("IsRevoked(%q) = false, want true", tokenID)
+		}
+	}
+	if revoked, _ := blacklist.IsRevoked(ctx, "unknown"); revoked {
+		t.Error("IsRevoked(\"unknown\") = true, want false")
+	}
+
+	// Entries stop counting once the token expires and are evicted by DeleteExpired
+	now = now.Add(30 * time.Minute)
+	if revoked, _ := blacklist.IsRevoked(ctx, "short"); revoked {
+		t.Error("IsRevoked(\"short\") after expiry = true, want false")
+	}
+	if deleted, _ := blacklist.DeleteExpired(ctx); deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", deleted)
+	}
+	if len(blacklist.revoked) != 1 {
+		t.Errorf("entries after DeleteExpired = %d, want 1", len(blacklist.revoked))
+	}
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new instance of AuditLogRepository
+func NewAuditLogRepository(db *gorm.DB) interfaces.AuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+// Create records a new audit log entry
+func (r *auditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// ListRecent returns the newest audit log entries, newest first
+func (r *auditLogRepository) ListRecent(ctx context.Context, limit int) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	if err := r.db.WithContext(ctx).Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Search returns a page of the entries matching filter, newest first, along with the total number of matches
+func (r *auditLogRepository) Search(ctx context.Context, filter interfaces.AuditLogFilter, offset, limit int) ([]models.AuditLogEntry, int64, error) {
+	var entries []models.AuditLogEntry
+	var total int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AuditLog{}).Scopes(auditLogsMatching(filter)).Count(&total).Error; err != nil {
+			return err
+		}
+
+		return tx.Scopes(auditLogsMatching(filter), withActorEmail).
+			Offset(offset).
+			Limit(limit).
+			Find(&entries).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// SearchAll returns every entry matching filter, newest first
+func (r *auditLogRepository) SearchAll(ctx context.Context, filter interfaces.AuditLogFilter) ([]models.AuditLogEntry, error) {
+	var entries []models.AuditLogEntry
+	if err := r.db.WithContext(ctx).Scopes(auditLogsMatching(filter), withActorEmail).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func auditLogsMatching(filter interfaces.AuditLogFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.ActorID != 0 {
+			db = db.Where("audit_logs.actor_id = ?", filter.ActorID)
+		}
+		if filter.Action != "" {
+			db = db.Where("audit_logs.action = ?", filter.Action)
+		}
+		if filter.From != nil {
+			db = db.Where("audit_logs.created_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			db = db.Where("audit_logs.created_at <= ?", *filter.To)
+		}
+		return db
+	}
+}
+
+// withActorEmail joins in the actor's email, including for soft-deleted actors, and orders newest first
+func withActorEmail(db *gorm.DB) *gorm.DB {
+	return db.Table("audit_logs").
+		Select("audit_logs.*, users.email AS actor_email").
+		Joins("LEFT JOIN users ON users.id = audit_logs.actor_id").
+		Order("audit_logs.created_at DESC, audit_logs.id DESC")
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+	"testing"
+	"time"
+)
+
+// seedAuditLogs creates two actors, one of them since deleted, and entries spread over three days
+func seedAuditLogs(t *testing.T, repo interfaces.AuditLogRepository, userRepo interfaces.UserRepository) (admin, former *models.User) {
+	ctx := context.Background()
+	admin = &models.User{Email: "admin@example.com", Password: "password123", FirstName: "Ada", LastName: "Admin", Role: models.RoleAdmin}
+	former = &models.User{Email: "former@example.com", Password: "password123", FirstName: "Fred", LastName: "Former", Role: models.RoleAdmin}
+	for _, user := range []*models.User{admin, former} {
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, entry := range []*models.AuditLog{
+		{ActorID: admin.ID, Action: models.AuditActionUserUpdated, TargetType: "user", TargetID: 10, CreatedAt: day.Add(9 * time.Hour)},
+		{ActorID: former.ID, Action: models.AuditActionUserDeleted, TargetType: "user", TargetID: 11, CreatedAt: day.Add(23*time.Hour + 59*time.Minute)},
+		{ActorID: admin.ID, Action: models.AuditActionUserDeleted, TargetType: "user", TargetID: 12, CreatedAt: day.AddDate(0, 0, 1).Add(12 * time.Hour)},
+		{ActorID: admin.ID, Action: models.AuditActionUsersPurged, TargetType: "user", CreatedAt: day.AddDate(0, 0, 2)},
+	} {
+		if err := repo.Create(ctx, entry); err != nil {
+			t.Fatalf("Failed to create audit log entry: %v", err)
+		}
+	}
+
+	if err := userRepo.Delete(ctx, former.ID); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	return admin, former
+}
+
+func TestAuditLogRepository_Search(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAuditLogRepository(db)
+	admin, former := seedAuditLogs(t, repo, NewUserRepository(db))
+
+	at := func(s string) *time.Time {
+		t, _ := time.Parse(time.RFC3339, s)
+		return &t
+	}
+
+	tests := []struct {
+		name          string
+		filter        interfaces.AuditLogFilter
+		wantTargetIDs []uint
+	}{
+		{"No filter, newest first", interfaces.AuditLogFilter{}, []uint{0, 12, 11, 10}},
+		{"Actor", interfaces.AuditLogFilter{ActorID: admin.ID}, []uint{0, 12, 10}},
+		{"Action", interfaces.AuditLogFilter{Action: models.AuditActionUserDeleted}, []uint{12, 11}},
+		{"Actor and action", interfaces.AuditLogFilter{ActorID: former.ID, Action: models.AuditActionUserDeleted}, []uint{11}},
+		{"From is inclusive", interfaces.AuditLogFilter{From: at("2024-03-02T12:00:00Z")}, []uint{0, 12}},
+		{"To is inclusive", interfaces.AuditLogFilter{To: at("2024-03-01T23:59:00Z")}, []uint{11, 10}},
+		{"Range", interfaces.AuditLogFilter{From: at("2024-03-01T10:00:00Z"), To: at("2024-03-02T12:00:00Z")}, []uint{12, 11}},
+		{"No match", interfaces.AuditLogFilter{ActorID: admin.ID, To: at("2024-03-01T08:00:00Z")}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total, err := repo.Search(context.Background(), tt.filter, 0, 10)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if total != int64(len(tt.wantTargetIDs)) {
+				t.Errorf("Search() total = %d, want %d", total, len(tt.wantTargetIDs))
+			}
+			var targetIDs []uint
+			for _, entry := range entries {
+				targetIDs = append(targetIDs, entry.TargetID)
+			}
+			if len(targetIDs) != len(tt.wantTargetIDs) {
+				t.Fatalf("Search() target IDs = %v, want %v", targetIDs, tt.wantTargetIDs)
+			}
+			for i := range targetIDs {
+				if targetIDs[i] != tt.wantTargetIDs[i] {
+					t.Fatalf("Search() target IDs = %v, want %v", targetIDs, tt.wantTargetIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditLogRepository_Search_ActorEmail(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAuditLogRepository(db)
+	seedAuditLogs(t, repo, NewUserRepository(db))
+
+	entries, err := repo.SearchAll(context.Background(), interfaces.AuditLogFilter{Action: models.AuditActionUserDeleted})
+	if err != nil {
+		t.Fatalf("SearchAll() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("SearchAll() returned %d entries, want 2", len(entries))
+	}
+	// Deleted actors keep their email in the log
+	if entries[0].ActorEmail != "admin@example.com" || entries[1].ActorEmail != "former@example.com" {
+		t.Errorf("SearchAll() actor emails = %q, %q, want admin@example.com, former@example.com", entries[0].ActorEmail, entries[1].ActorEmail)
+	}
+}
+
+func TestAuditLogRepository_Search_Pages(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAuditLogRepository(db)
+	seedAuditLogs(t, repo, NewUserRepository(db))
+
+	entries, total, err := repo.Search(context.Background(), interfaces.AuditLogFilter{}, 2, 2)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if total != 4 || len(entries) != 2 || entries[0].TargetID != 11 || entries[1].TargetID != 10 {
+		t.Errorf("Search() second page = %+v (total %d), want the two oldest of 4 entries", entries, total)
+	}
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new instance of SessionRepository
+func NewSessionRepository(db *gorm.DB) interfaces.SessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+// Create creates a new session in the database
+func (r *sessionRepository) Create(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// GetByTokenID retrieves a session by the ID of its current refresh token
+func (r *sessionRepository) GetByTokenID(ctx context.Context, tokenID string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.WithContext(ctx).Where("token_id = ?", tokenID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Update updates an existing session in the database
+func (r *sessionRepository) Update(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+// ListActiveByUser retrieves the non-revoked, unexpired sessions of a user
+func (r *sessionRepository) ListActiveByUser(ctx context.Context, userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Revoke marks a single session of the user as revoked
+func (r *sessionRepository) Revoke(ctx context.Context, id, userID uint) error {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeAllByUser marks every active session of the user as revoked
+func (r *sessionRepository) RevokeAllByUser(ctx context.Context, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func createTestSession(t *testing.T, repo interfaces.SessionRepository, userID uint, tokenID string, expiresAt time.Time) *models.Session {
+	session := &models.Session{
+		UserID:     userID,
+		TokenID:    tokenID,
+		UserAgent:  "test-agent",
+		IPAddress:  "127.0.0.1",
+		LastUsedAt: time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+	if err := repo.Create(context.Background(), session); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	return session
+}
+
+func TestSessionRepository_GetByTokenID(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := createTestSession(t, repo, 1, "token-1", time.Now().Add(time.Hour))
+
+	found, err := repo.GetByTokenID(ctx, "token-1")
+	if err != nil {
+		t.Fatalf("Failed to get session by token ID: %v", err)
+	}
+	if found.ID != session.ID {
+		t.Errorf("Expected session ID %d, got %d", session.ID, found.ID)
+	}
+
+	if _, err := repo.GetByTokenID(ctx, "missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound for unknown token ID, got %v", err)
+	}
+}
+
+func TestSessionRepository_ListActiveByUser(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	createTestSession(t, repo, 1, "active", time.Now().Add(time.Hour))
+	createTestSession(t, repo, 1, "expired", time.Now().Add(-time.Hour))
+	revoked := createTestSession(t, repo, 1, "revoked", time.Now().Add(time.Hour))
+	createTestSession(t, repo, 2, "other-user", time.Now().Add(time.Hour))
+
+	if err := repo.Revoke(ctx, revoked.ID, 1); err != nil {
+		t.Fatalf("Failed to revoke session: %v", err)
+	}
+
+	sessions, err := repo.ListActiveByUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("Failed to list sessions: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 active session, got %d", len(sessions))
+	}
+	if sessions[0].TokenID != "active" {
+		t.Errorf("Expected active session, got %q", sessions[0].TokenID)
+	}
+}
+
+func TestSessionRepository_Revoke(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := createTestSession(t, repo, 1, "token-1", time.Now().Add(time.Hour))
+
+	// Another user cannot revoke the session
+	if err := repo.Revoke(ctx, session.ID, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound when revoking another user's session, got %v", err)
+	}
+
+	if err := repo.Revoke(ctx, session.ID, 1); err != nil {
+		t.Fatalf("Failed to revoke session: %v", err)
+	}
+
+	found, err := repo.GetByTokenID(ctx, "token-1")
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if found.IsActive() {
+		t.Errorf("Expected session to be revoked")
+	}
+}
+
+func TestSessionRepository_RevokeAllByUser(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	createTestSession(t, repo, 1, "token-1", time.Now().Add(time.Hour))
+	createTestSession(t, repo, 1, "token-2", time.Now().Add(time.Hour))
+	createTestSession(t, repo, 2, "token-3", time.Now().Add(time.Hour))
+
+	revoked, err := repo.RevokeAllByUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("Failed to revoke sessions: %v", err)
+	}
+	if revoked != 2 {
+		t.Errorf("Expected 2 revoked sessions, got %d", revoked)
+	}
+
+	remaining, err := repo.ListActiveByUser(ctx, 2)
+	if err != nil {
+		t.Fatalf("Failed to list sessions: %v", err)
+	}
+	if len(remaining) != 1 {
+		t.Errorf("Expected other user's session to remain active, got %d", len(remaining))
+	}
+}
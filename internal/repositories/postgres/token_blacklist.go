@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tokenBlacklist struct {
+	db *gorm.DB
+}
+
+// NewTokenBlacklist creates a TokenBlacklist stored in the database, shared by every instance
+func NewTokenBlacklist(db *gorm.DB) interfaces.TokenBlacklist {
+	return &tokenBlacklist{
+		db: db,
+	}
+}
+
+// Revoke records the token as revoked until expiresAt
+func (r *tokenBlacklist) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{TokenID: tokenID, ExpiresAt: expiresAt}).Error
+}
+
+// IsRevoked reports whether the token was revoked and has not expired yet
+func (r *tokenBlacklist) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RevokedToken{}).
+		Where("token_id = ? AND expires_at > ?", tokenID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteExpired removes the entries of expired tokens
+func (r *tokenBlacklist) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&models.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBlacklist(t *testing.T) {
+	db := setupTestDB(t)
+	blacklist := NewTokenBlacklist(db)
+	ctx := context.Background()
+
+	if err := blacklist.Revoke(ctx, "active", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := blacklist.Revoke(ctx, "expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	// Revoking a token twice is not an error
+	if err := blacklist.Revoke(ctx, "active", time.Now().Add(time.Hour)); err != nil {
+		t.Errorf("Revoke() twice error = %v, want nil", err)
+	}
+
+	tests := []struct {
+		tokenID string
+		want    bool
+	}{
+		{"active", true},
+		{"expired", false},
+		{"unknown", false},
+	}
+	for _, tt := range tests {
+		revoked, err := blacklist.IsRevoked(ctx, tt.tokenID)
+		if err != nil {
+			t.Fatalf("IsRevoked(%q) error = %v", tt.tokenID, err)
+		}
+		if revoked != tt.want {
+			t.Errorf("IsRevoked(%q) = %v, want %v", tt.tokenID, revoked, tt.want)
+		}
+	}
+
+	deleted, err := blacklist.DeleteExpired(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", deleted)
+	}
+	if revoked, _ := blacklist.IsRevoked(ctx, "active"); !revoked {
+		t.Error("IsRevoked(\"active\") = false after DeleteExpired, want true")
+	}
+}
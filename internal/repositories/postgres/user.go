@@ -1,11 +1,15 @@
 package postgres
 
 import (
+	"context"
 	"customable-corporate-site-api/internal/models"
 	"customable-corporate-site-api/internal/repositories/interfaces"
+	"slices"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
@@ -20,42 +24,45 @@ func NewUserRepository(db *gorm.DB) interfaces.UserRepository {
 }
 
 // Create creates a new user in the database
-func (r *userRepository) Create(user *models.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
 // GetByID retrieves a user by ID from the database
-func (r *userRepository) GetByID(id uint) (*models.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	if err := r.db.First(&user, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 // GetByEmail retrieves a user by email from the database
-func (r *userRepository) GetByEmail(email string) (*models.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 // Update updates an existing user in the database
-func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	// The token version only changes through IncrementTokenVersion, so saving a user loaded
+	// earlier cannot undo a revocation made in the meantime. The same goes for login failures
+	// and locks, which change through RecordFailedLogin and ResetFailedLogins.
+	return r.db.WithContext(ctx).Omit("TokenVersion", "FailedLoginAttempts", "LockedUntil").Save(user).Error
 }
 
 // Delete deletes a user from the database
-func (r *userRepository) Delete(id uint) error {
-	return r.db.Delete(&models.User{}, id).Error
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
 }
 
 // List retrieves a list of users from the database with pagination
-func (r *userRepository) List(offset, limit int) ([]models.User, error) {
+func (r *userRepository) List(ctx context.Context, offset, limit int) ([]models.User, error) {
 	var users []models.User
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -65,19 +72,61 @@ func (r *userRepository) List(offset, limit int) ([]models.User, error) {
 	return users, nil
 }
 
+// ListWithCount retrieves a page of users along with the total number of users
+func (r *userRepository) ListWithCount(ctx context.Context, offset, limit int, order string) ([]models.User, int64, error) {
+	return r.findWithCount(ctx, allUsers, offset, limit, order)
+}
+
+// ListAfter retrieves up to limit users with an ID above cursorID in ID order, along with the
+// cursor of the next page: the ID of the last user returned, or 0 when there are no more.
+// Unlike offset pages, pages stay stable while users are created or deleted.
+func (r *userRepository) ListAfter(ctx context.Context, cursorID uint, limit int) ([]models.User, uint, error) {
+	var users []models.User
+	// One extra row tells whether another page follows without a count
+	if err := r.db.WithContext(ctx).
+		Where("id > ?", cursorID).
+		Order("id ASC").
+		Limit(limit + 1).
+		Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if len(users) <= limit {
+		return users, 0, nil
+	}
+	users = users[:limit]
+	return users, users[limit-1].ID, nil
+}
+
+// Query retrieves the page of users matching filter along with the total number of matches.
+// SortBy and SortDir are checked against an allowlist before they reach the ORDER BY clause;
+// other values fail with interfaces.ErrInvalidUserSort.
+func (r *userRepository) Query(ctx context.Context, filter interfaces.UserFilter) ([]models.User, int64, error) {
+	order, err := userQueryOrder(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	return r.findWithCount(ctx, usersFiltered(filter), filter.Offset, limit, order)
+}
+
 // Count returns the total number of users in the database
-func (r *userRepository) Count() (int64, error) {
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.Model(&models.User{}).Count(&count).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&models.User{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
 // GetActiveUsers retrieves all active users from the database
-func (r *userRepository) GetActiveUsers(limit, offset int) ([]models.User, error) {
+func (r *userRepository) GetActiveUsers(ctx context.Context, limit, offset int) ([]models.User, error) {
 	var users []models.User
-	if err := r.db.Where("is_active = ?", true).
+	if err := r.db.WithContext(ctx).Scopes(activeUsers).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -87,10 +136,15 @@ func (r *userRepository) GetActiveUsers(limit, offset int) ([]models.User, error
 	return users, nil
 }
 
+// GetActiveUsersWithCount retrieves a page of active users along with the total number of active users
+func (r *userRepository) GetActiveUsersWithCount(ctx context.Context, limit, offset int, order string) ([]models.User, int64, error) {
+	return r.findWithCount(ctx, activeUsers, offset, limit, order)
+}
+
 // GetUsersByRole retrieves users by their role from the database
-func (r *userRepository) GetUsersByRole(role string, limit, offset int) ([]models.User, error) {
+func (r *userRepository) GetUsersByRole(ctx context.Context, role string, limit, offset int) ([]models.User, error) {
 	var users []models.User
-	if err := r.db.Where("role = ?", role).
+	if err := r.db.WithContext(ctx).Scopes(usersWithRole(role)).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -100,10 +154,15 @@ func (r *userRepository) GetUsersByRole(role string, limit, offset int) ([]model
 	return users, nil
 }
 
+// GetUsersByRoleWithCount retrieves a page of users with the given role along with the total number of matches
+func (r *userRepository) GetUsersByRoleWithCount(ctx context.Context, role string, limit, offset int, order string) ([]models.User, int64, error) {
+	return r.findWithCount(ctx, usersWithRole(role), offset, limit, order)
+}
+
 // SearchUsers searches users by name or email in the database
-func (r *userRepository) SearchUsers(query string, limit, offset int) ([]models.User, error) {
+func (r *userRepository) SearchUsers(ctx context.Context, query string, limit, offset int) ([]models.User, error) {
 	var users []models.User
-	if err := r.db.Where("LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(email) LIKE ?", "%"+strings.ToLower(query)+"%", "%"+strings.ToLower(query)+"%", "%"+strings.ToLower(query)+"%").
+	if err := r.db.WithContext(ctx).Scopes(usersMatching(query)).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -113,18 +172,308 @@ func (r *userRepository) SearchUsers(query string, limit, offset int) ([]models.
 	return users, nil
 }
 
+// SearchUsersWithCount searches users by name or email along with the total number of matches
+func (r *userRepository) SearchUsersWithCount(ctx context.Context, query string, limit, offset int, order string) ([]models.User, int64, error) {
+	return r.findWithCount(ctx, usersMatching(query), offset, limit, order)
+}
+
 // UpdateUserStatus updates the active status of a user
-func (r *userRepository) UpdateUserStatus(id uint, isActive bool) error {
-	if err := r.db.Model(&models.User{}).Where("id = ?", id).Update("is_active", isActive).Error; err != nil {
+func (r *userRepository) UpdateUserStatus(ctx context.Context, id uint, isActive bool) error {
+	if err := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("is_active", isActive).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// BulkUpdateStatus sets the active status of the users in a single update and returns how many
+// changed; users that already have the status are left alone. Deactivating also increments the
+// token version in the same statement, so tokens issued to those users stop working at once.
+func (r *userRepository) BulkUpdateStatus(ctx context.Context, ids []uint, isActive bool) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	columns := map[string]interface{}{"is_active": isActive}
+	if !isActive {
+		columns["token_version"] = gorm.Expr("token_version + 1")
+	}
+
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id IN ? AND is_active <> ?", ids, isActive).
+		UpdateColumns(columns)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
 // UpdateUserRole updates the role of a user
-func (r *userRepository) UpdateUserRole(id uint, role string) error {
-	if err := r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role).Error; err != nil {
+func (r *userRepository) UpdateUserRole(ctx context.Context, id uint, role string) error {
+	if err := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("role", role).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+// IncrementTokenVersion invalidates every token issued to the user so far
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RecordFailedLogin counts a failed login in a single statement, so concurrent attempts are
+// all counted. The right-hand sides read the values from before the update.
+func (r *userRepository) RecordFailedLogin(ctx context.Context, id uint, maxAttempts int, lockedUntil time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": gorm.Expr("CASE WHEN failed_login_attempts + 1 >= ? THEN 0 ELSE failed_login_attempts + 1 END", maxAttempts),
+			"locked_until":          gorm.Expr("CASE WHEN failed_login_attempts + 1 >= ? THEN ? ELSE locked_until END", maxAttempts, lockedUntil),
+		}).Error
+}
+
+// ResetFailedLogins clears the failed login count and any lock
+func (r *userRepository) ResetFailedLogins(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error
+}
+
+// UpdateAudited saves the user like Update, optionally increments the token version and records
+// the audit entry in one transaction, so a change is never written without its audit trail
+func (r *userRepository) UpdateAudited(ctx context.Context, user *models.User, revokeTokens bool, entry *models.AuditLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("TokenVersion", "FailedLoginAttempts", "LockedUntil").Save(user).Error; err != nil {
+			return err
+		}
+		if revokeTokens {
+			if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+				UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+				return err
+			}
+		}
+		if entry == nil {
+			return nil
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+// DeleteMany soft-deletes the users and records the audit entries in one transaction,
+// so users are never deleted without their audit trail. The admin rows are locked before
+// they are counted, so concurrent deletes cannot together remove the last admin.
+func (r *userRepository) DeleteMany(ctx context.Context, ids []uint, entries []models.AuditLog) ([]uint, error) {
+	var kept []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		kept = nil
+
+		var adminIDs []uint
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.User{}).
+			Where("role = ?", models.RoleAdmin).
+			Order("id").
+			Pluck("id", &adminIDs).Error; err != nil {
+			return err
+		}
+
+		adminsLeft := len(adminIDs)
+		deleteIDs := make([]uint, 0, len(ids))
+		for _, id := range ids {
+			if slices.Contains(adminIDs, id) {
+				if adminsLeft <= 1 {
+					kept = append(kept, id)
+					continue
+				}
+				adminsLeft--
+			}
+			deleteIDs = append(deleteIDs, id)
+		}
+
+		if len(deleteIDs) > 0 {
+			if err := tx.Delete(&models.User{}, deleteIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		deletedEntries := make([]models.AuditLog, 0, len(entries))
+		for _, entry := range entries {
+			if !slices.Contains(kept, entry.TargetID) {
+				deletedEntries = append(deletedEntries, entry)
+			}
+		}
+		if len(deletedEntries) == 0 {
+			return nil
+		}
+		return tx.Create(&deletedEntries).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// Stats counts users in total, active, created since newSince and per role with a single grouped query
+func (r *userRepository) Stats(ctx context.Context, newSince time.Time) (*models.UserStats, error) {
+	var rows []struct {
+		Role     string
+		IsActive bool
+		Total    int64
+		NewUsers int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Select("role, is_active, COUNT(*) AS total, SUM(CASE WHEN created_at >= ? THEN 1 ELSE 0 END) AS new_users", newSince).
+		Group("role, is_active").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.UserStats{ByRole: make(map[string]int64)}
+	for _, row := range rows {
+		stats.Total += row.Total
+		stats.NewThisWeek += row.NewUsers
+		stats.ByRole[row.Role] += row.Total
+		if row.IsActive {
+			stats.Active += row.Total
+		}
+	}
+	return stats, nil
+}
+
+// ListDeletedWithCount retrieves a page of soft-deleted users along with the total number of deleted users
+func (r *userRepository) ListDeletedWithCount(ctx context.Context, offset, limit int, order string) ([]models.User, int64, error) {
+	if order == "" {
+		order = "deleted_at DESC"
+	}
+	return r.findWithCount(ctx, deletedUsers, offset, limit, order)
+}
+
+// Restore clears deleted_at on a soft-deleted user. Active users are left alone and reported
+// as not found, like unknown IDs.
+func (r *userRepository) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().
+		Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes up to limit users soft-deleted before the given time
+// and returns how many were removed. Callers purge in batches to keep each delete short.
+func (r *userRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	expired := r.db.WithContext(ctx).Unscoped().
+		Model(&models.User{}).
+		Select("id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("id").
+		Limit(limit)
+
+	result := r.db.WithContext(ctx).Unscoped().Where("id IN (?)", expired).Delete(&models.User{})
+	return result.RowsAffected, result.Error
+}
+
+// findWithCount counts the users matching scope and fetches the requested page in a single transaction,
+// so the total always reflects the same WHERE clause as the returned rows
+func (r *userRepository) findWithCount(ctx context.Context, scope func(*gorm.DB) *gorm.DB, offset, limit int, order string) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	if order == "" {
+		order = "created_at DESC"
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Scopes(scope).Count(&total).Error; err != nil {
+			return err
+		}
+
+		return tx.Scopes(scope).
+			Order(order).
+			Offset(offset).
+			Limit(limit).
+			Find(&users).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// Query scopes shared by the list methods and their *WithCount siblings
+
+func allUsers(db *gorm.DB) *gorm.DB {
+	return db
+}
+
+func activeUsers(db *gorm.DB) *gorm.DB {
+	return db.Where("is_active = ?", true)
+}
+
+// deletedUsers lifts the soft delete filter to select only deleted users
+func deletedUsers(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+func usersWithRole(role string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("role = ?", role)
+	}
+}
+
+func usersMatching(query string) func(*gorm.DB) *gorm.DB {
+	pattern := "%" + strings.ToLower(query) + "%"
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern, pattern)
+	}
+}
+
+func usersFiltered(filter interfaces.UserFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Role != "" {
+			db = usersWithRole(filter.Role)(db)
+		}
+		if filter.IsActive != nil {
+			db = db.Where("is_active = ?", *filter.IsActive)
+		}
+		if filter.Search != "" {
+			db = usersMatching(filter.Search)(db)
+		}
+		return db
+	}
+}
+
+// userQueryOrder builds the ORDER BY clause of a UserFilter, newest first by default
+func userQueryOrder(filter interfaces.UserFilter) (string, error) {
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	if !slices.Contains(interfaces.UserSortFields, sortBy) {
+		return "", interfaces.ErrInvalidUserSort
+	}
+
+	switch dir := strings.ToLower(filter.SortDir); dir {
+	case "":
+		return sortBy + " DESC", nil
+	case "asc", "desc":
+		return sortBy + " " + strings.ToUpper(dir), nil
+	default:
+		return "", interfaces.ErrInvalidUserSort
+	}
+}
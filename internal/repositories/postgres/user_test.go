@@ -1,9 +1,13 @@
 package postgres
 
 import (
+	"context"
 	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
 	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
@@ -15,8 +19,8 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
 
-	// Auto-migrate the User model
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	// Auto-migrate the models
+	if err := db.AutoMigrate(&models.User{}, &models.Session{}, &models.AuditLog{}, &models.RevokedToken{}); err != nil {
 		t.Fatalf("Failed to auto-migrate test database: %v", err)
 	}
 
@@ -26,6 +30,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 func TestUserRepository_Create(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	user := &models.User{
 		Email:     "test@example.com",
@@ -35,7 +40,7 @@ func TestUserRepository_Create(t *testing.T) {
 		Role:      models.RoleUser,
 	}
 
-	if err := repo.Create(user); err != nil {
+	if err := repo.Create(ctx, user); err != nil {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
@@ -51,6 +56,7 @@ func TestUserRepository_Create(t *testing.T) {
 func TestUserRepository_GetByID(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	user := &models.User{
 		Email:     "test@example.com",
@@ -60,11 +66,11 @@ func TestUserRepository_GetByID(t *testing.T) {
 		Role:      models.RoleUser,
 	}
 
-	if err := repo.Create(user); err != nil {
+	if err := repo.Create(ctx, user); err != nil {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
-	retrievedUser, err := repo.GetByID(user.ID)
+	retrievedUser, err := repo.GetByID(ctx, user.ID)
 	if err != nil {
 		t.Fatalf("Failed to retrieve user by ID: %v", err)
 	}
@@ -82,6 +88,7 @@ func TestUserRepository_GetByID(t *testing.T) {
 func TestUserRepository_GetByEmail(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	user := &models.User{
 		Email:     "test@example.com",
@@ -91,11 +98,11 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 		Role:      models.RoleUser,
 	}
 
-	if err := repo.Create(user); err != nil {
+	if err := repo.Create(ctx, user); err != nil {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
-	retrievedUser, err := repo.GetByEmail(user.Email)
+	retrievedUser, err := repo.GetByEmail(ctx, user.Email)
 	if err != nil {
 		t.Fatalf("Failed to retrieve user by email: %v", err)
 	}
@@ -113,6 +120,7 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 func TestUserRepository_Update(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	user := &models.User{
 		Email:     "test@example.com",
@@ -122,15 +130,15 @@ func TestUserRepository_Update(t *testing.T) {
 		Role:      models.RoleUser,
 	}
 
-	if err := repo.Create(user); err != nil {
+	if err := repo.Create(ctx, user); err != nil {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
 	user.FirstName = "Jane"
-	if err := repo.Update(user); err != nil {
+	if err := repo.Update(ctx, user); err != nil {
 		t.Fatalf("Failed to update user: %v", err)
 	}
-	updatedUser, err := repo.GetByID(user.ID)
+	updatedUser, err := repo.GetByID(ctx, user.ID)
 	if err != nil {
 		t.Fatalf("Failed to retrieve user by ID: %v", err)
 	}
@@ -142,6 +150,7 @@ func TestUserRepository_Update(t *testing.T) {
 func TestUserRepository_Delete(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	user := &models.User{
 		Email:     "test@example.com",
@@ -151,16 +160,16 @@ func TestUserRepository_Delete(t *testing.T) {
 		Role:      models.RoleUser,
 	}
 
-	if err := repo.Create(user); err != nil {
+	if err := repo.Create(ctx, user); err != nil {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
-	if err := repo.Delete(user.ID); err != nil {
+	if err := repo.Delete(ctx, user.ID); err != nil {
 		t.Fatalf("Failed to delete user: %v", err)
 	}
 
 	// After delete, GORM should return ErrRecordNotFound when fetching
-	deletedUser, err := repo.GetByID(user.ID)
+	deletedUser, err := repo.GetByID(ctx, user.ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// expected: user deleted
@@ -177,6 +186,7 @@ func TestUserRepository_Delete(t *testing.T) {
 func TestUserRepository_List(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	// Create multiple users
 	users := []models.User{
@@ -188,13 +198,13 @@ func TestUserRepository_List(t *testing.T) {
 	}
 
 	for i := range users {
-		if err := repo.Create(&users[i]); err != nil {
+		if err := repo.Create(ctx, &users[i]); err != nil {
 			t.Fatalf("Failed to create user: %v", err)
 		}
 	}
 
 	// Test Pagination (offset 0)
-	retrievedUsers, err := repo.List(0, 10) // Offset 0, Limit 10
+	retrievedUsers, err := repo.List(ctx, 0, 10) // Offset 0, Limit 10
 	if err != nil {
 		t.Fatalf("Failed to list users: %v", err)
 	}
@@ -204,9 +214,80 @@ func TestUserRepository_List(t *testing.T) {
 	}
 }
 
+func TestUserRepository_ListAfter(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	users := []models.User{
+		{Email: "user1@example.com", Password: "password123", FirstName: "User", LastName: "One", Role: models.RoleUser},
+		{Email: "user2@example.com", Password: "password123", FirstName: "User", LastName: "Two", Role: models.RoleUser},
+		{Email: "user3@example.com", Password: "password123", FirstName: "User", LastName: "Three", Role: models.RoleUser},
+		{Email: "user4@example.com", Password: "password123", FirstName: "User", LastName: "Four", Role: models.RoleUser},
+		{Email: "user5@example.com", Password: "password123", FirstName: "User", LastName: "Five", Role: models.RoleUser},
+	}
+	for i := range users {
+		if err := repo.Create(ctx, &users[i]); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	first, next, err := repo.ListAfter(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("ListAfter() error = %v", err)
+	}
+	if len(first) != 2 || first[0].ID != users[0].ID || first[1].ID != users[1].ID {
+		t.Fatalf("ListAfter(0, 2) = %v, want users %d and %d", userIDs(first), users[0].ID, users[1].ID)
+	}
+	if next != first[1].ID {
+		t.Errorf("ListAfter(0, 2) next = %d, want the last ID %d", next, first[1].ID)
+	}
+
+	// Deleting a user already seen would shift an offset page; the cursor page stays put
+	if err := repo.Delete(ctx, users[0].ID); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+
+	second, next, err := repo.ListAfter(ctx, next, 2)
+	if err != nil {
+		t.Fatalf("ListAfter() error = %v", err)
+	}
+	if len(second) != 2 || second[0].ID != users[2].ID || second[1].ID != users[3].ID {
+		t.Fatalf("ListAfter() second page = %v, want users %d and %d", userIDs(second), users[2].ID, users[3].ID)
+	}
+	if next != second[1].ID {
+		t.Errorf("ListAfter() second page next = %d, want the last ID %d", next, second[1].ID)
+	}
+
+	last, next, err := repo.ListAfter(ctx, next, 2)
+	if err != nil {
+		t.Fatalf("ListAfter() error = %v", err)
+	}
+	if len(last) != 1 || last[0].ID != users[4].ID {
+		t.Errorf("ListAfter() last page = %v, want user %d", userIDs(last), users[4].ID)
+	}
+	if next != 0 {
+		t.Errorf("ListAfter() last page next = %d, want 0", next)
+	}
+
+	// A full last page has no next page either
+	if _, next, _ := repo.ListAfter(ctx, users[2].ID, 2); next != 0 {
+		t.Errorf("ListAfter() full last page next = %d, want 0", next)
+	}
+}
+
+func userIDs(users []models.User) []uint {
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
 func TestUserRepository_Count(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	// Create test users
 	users := []models.User{
@@ -216,12 +297,12 @@ func TestUserRepository_Count(t *testing.T) {
 	}
 
 	for i := range users {
-		if err := repo.Create(&users[i]); err != nil {
+		if err := repo.Create(ctx, &users[i]); err != nil {
 			t.Fatalf("Failed to create user: %v", err)
 		}
 	}
 
-	count, err := repo.Count()
+	count, err := repo.Count(ctx)
 	if err != nil {
 		t.Fatalf("Failed to count users: %v", err)
 	}
@@ -234,6 +315,7 @@ func TestUserRepository_Count(t *testing.T) {
 func TestUserRepository_GetActiveUsers(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	// Create active and inactive users
 	activeUser := &models.User{
@@ -254,21 +336,21 @@ func TestUserRepository_GetActiveUsers(t *testing.T) {
 		IsActive:  false,
 	}
 
-	if err := repo.Create(activeUser); err != nil {
+	if err := repo.Create(ctx, activeUser); err != nil {
 		t.Fatalf("Failed to create active user: %v", err)
 	}
 
-	if err := repo.Create(inactiveUser); err != nil {
+	if err := repo.Create(ctx, inactiveUser); err != nil {
 		t.Fatalf("Failed to create inactive user: %v", err)
 	}
 
 	// Ensure the second user is marked inactive in DB (some drivers may apply defaults)
-	if err := repo.UpdateUserStatus(inactiveUser.ID, false); err != nil {
+	if err := repo.UpdateUserStatus(ctx, inactiveUser.ID, false); err != nil {
 		t.Fatalf("Failed to mark user inactive: %v", err)
 	}
 
 	// GetActiveUsers(limit, offset)
-	activeUsers, err := repo.GetActiveUsers(10, 0)
+	activeUsers, err := repo.GetActiveUsers(ctx, 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to get active users: %v", err)
 	}
@@ -285,6 +367,7 @@ func TestUserRepository_GetActiveUsers(t *testing.T) {
 func TestUserRepository_GetUserByRole(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	// Create users with different roles
 	adminUser := &models.User{
@@ -311,19 +394,19 @@ func TestUserRepository_GetUserByRole(t *testing.T) {
 		Role:      models.RoleUser,
 	}
 
-	if err := repo.Create(adminUser); err != nil {
+	if err := repo.Create(ctx, adminUser); err != nil {
 		t.Fatalf("Failed to create admin user: %v", err)
 	}
 
-	if err := repo.Create(editorUser); err != nil {
+	if err := repo.Create(ctx, editorUser); err != nil {
 		t.Fatalf("Failed to create editor user: %v", err)
 	}
 
-	if err := repo.Create(viewerUser); err != nil {
+	if err := repo.Create(ctx, viewerUser); err != nil {
 		t.Fatalf("Failed to create viewer user: %v", err)
 	}
 
-	admins, err := repo.GetUsersByRole(models.RoleAdmin, 10, 0)
+	admins, err := repo.GetUsersByRole(ctx, models.RoleAdmin, 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to get admin users: %v", err)
 	}
@@ -336,7 +419,7 @@ func TestUserRepository_GetUserByRole(t *testing.T) {
 		t.Errorf("Expected admin user role %q, got %q", models.RoleAdmin, admins[0].Role)
 	}
 
-	editors, err := repo.GetUsersByRole(models.RoleEditor, 10, 0)
+	editors, err := repo.GetUsersByRole(ctx, models.RoleEditor, 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to get editor users: %v", err)
 	}
@@ -349,7 +432,7 @@ func TestUserRepository_GetUserByRole(t *testing.T) {
 		t.Errorf("Expected editor user role %q, got %q", models.RoleEditor, editors[0].Role)
 	}
 
-	viewers, err := repo.GetUsersByRole(models.RoleUser, 10, 0)
+	viewers, err := repo.GetUsersByRole(ctx, models.RoleUser, 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to get viewer users: %v", err)
 	}
@@ -366,6 +449,7 @@ func TestUserRepository_GetUserByRole(t *testing.T) {
 func TestUserRepository_SearchUsers(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewUserRepository(db)
+	ctx := context.Background()
 
 	// Create test users
 	users := []models.User{
@@ -375,7 +459,7 @@ func TestUserRepository_SearchUsers(t *testing.T) {
 	}
 
 	for _, user := range users {
-		if err := repo.Create(&user); err != nil {
+		if err := repo.Create(ctx, &user); err != nil {
 			t.Fatalf("Failed to create user: %v", err)
 		}
 	}
@@ -392,7 +476,7 @@ func TestUserRepository_SearchUsers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := repo.SearchUsers(tt.query, 10, 0)
+			got, err := repo.SearchUsers(ctx, tt.query, 10, 0)
 			if err != nil {
 				t.Fatalf("SearchUsers() error = %v", err)
 			}
@@ -402,3 +486,405 @@ func TestUserRepository_SearchUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepository_WithCount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	// Create users across roles so every filter matches a different subset
+	users := []models.User{
+		{Email: "admin1@example.com", Password: "password123", FirstName: "Alice", LastName: "Admin", Role: models.RoleAdmin},
+		{Email: "admin2@example.com", Password: "password123", FirstName: "Bob", LastName: "Admin", Role: models.RoleAdmin},
+		{Email: "editor@example.com", Password: "password123", FirstName: "Carol", LastName: "Editor", Role: models.RoleEditor},
+		{Email: "user1@example.com", Password: "password123", FirstName: "Dave", LastName: "Smith", Role: models.RoleUser},
+		{Email: "user2@example.com", Password: "password123", FirstName: "Eve", LastName: "Smith", Role: models.RoleUser},
+	}
+
+	for i := range users {
+		if err := repo.Create(ctx, &users[i]); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	// Deactivate one user so the active filter differs from the unfiltered total
+	if err := repo.UpdateUserStatus(ctx, users[4].ID, false); err != nil {
+		t.Fatalf("Failed to mark user inactive: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		query     func() ([]models.User, int64, error)
+		wantTotal int64
+	}{
+		{
+			name:      "ListWithCount",
+			query:     func() ([]models.User, int64, error) { return repo.ListWithCount(ctx, 0, 1, "") },
+			wantTotal: 5,
+		},
+		{
+			name:      "GetActiveUsersWithCount",
+			query:     func() ([]models.User, int64, error) { return repo.GetActiveUsersWithCount(ctx, 1, 0, "") },
+			wantTotal: 4,
+		},
+		{
+			name: "GetUsersByRoleWithCount",
+			query: func() ([]models.User, int64, error) {
+				return repo.GetUsersByRoleWithCount(ctx, models.RoleAdmin, 1, 0, "")
+			},
+			wantTotal: 2,
+		},
+		{
+			name:      "SearchUsersWithCount",
+			query:     func() ([]models.User, int64, error) { return repo.SearchUsersWithCount(ctx, "smith", 1, 0, "") },
+			wantTotal: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total, err := tt.query()
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			if len(page) != 1 {
+				t.Errorf("%s() returned %d users, want page size 1", tt.name, len(page))
+			}
+			if total != tt.wantTotal {
+				t.Errorf("%s() total = %d, want %d", tt.name, total, tt.wantTotal)
+			}
+		})
+	}
+
+	// The filtered total must differ from the unfiltered Count
+	count, err := repo.Count(ctx)
+	if err != nil {
+		t.Fatalf("Failed to count users: %v", err)
+	}
+	if _, total, _ := repo.GetUsersByRoleWithCount(ctx, models.RoleEditor, 10, 0, ""); total == count {
+		t.Errorf("Expected filtered total to differ from unfiltered count %d", count)
+	}
+}
+
+func TestUserRepository_Query(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	users := []models.User{
+		{Email: "carol@example.com", Password: "password123", FirstName: "Carol", LastName: "Smith", Role: models.RoleUser},
+		{Email: "alice@example.com", Password: "password123", FirstName: "Alice", LastName: "Jones", Role: models.RoleUser},
+		{Email: "bob@example.com", Password: "password123", FirstName: "Bob", LastName: "Smith", Role: models.RoleUser},
+		{Email: "dave@example.com", Password: "password123", FirstName: "Dave", LastName: "Brown", Role: models.RoleUser},
+		{Email: "admin@example.com", Password: "password123", FirstName: "Ada", LastName: "Admin", Role: models.RoleAdmin},
+	}
+	for i := range users {
+		if err := repo.Create(ctx, &users[i]); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+	if err := repo.UpdateUserStatus(ctx, users[3].ID, false); err != nil {
+		t.Fatalf("Failed to deactivate user: %v", err)
+	}
+
+	active, inactive := true, false
+	tests := []struct {
+		name       string
+		filter     interfaces.UserFilter
+		wantEmails []string
+		wantTotal  int64
+		wantErr    error
+	}{
+		{
+			name:       "Active users with a role by email",
+			filter:     interfaces.UserFilter{Role: models.RoleUser, IsActive: &active, SortBy: "email", SortDir: "asc"},
+			wantEmails: []string{"alice@example.com", "bob@example.com", "carol@example.com"},
+			wantTotal:  3,
+		},
+		{
+			name:       "Active users with a role, second page",
+			filter:     interfaces.UserFilter{Role: models.RoleUser, IsActive: &active, SortBy: "email", SortDir: "ASC", Limit: 2, Offset: 2},
+			wantEmails: []string{"carol@example.com"},
+			wantTotal:  3,
+		},
+		{
+			name:       "Inactive users",
+			filter:     interfaces.UserFilter{IsActive: &inactive},
+			wantEmails: []string{"dave@example.com"},
+			wantTotal:  1,
+		},
+		{
+			name:       "Search by last name descending",
+			filter:     interfaces.UserFilter{Search: "smith", SortBy: "email", SortDir: "desc"},
+			wantEmails: []string{"carol@example.com", "bob@example.com"},
+			wantTotal:  2,
+		},
+		{
+			name:       "Newest first by default",
+			filter:     interfaces.UserFilter{Role: models.RoleUser, Limit: 1},
+			wantEmails: []string{"dave@example.com"},
+			wantTotal:  4,
+		},
+		{
+			name:    "Sort field outside the allowlist",
+			filter:  interfaces.UserFilter{SortBy: "password"},
+			wantErr: interfaces.ErrInvalidUserSort,
+		},
+		{
+			name:    "Injected sort field",
+			filter:  interfaces.UserFilter{SortBy: "email; DROP TABLE users"},
+			wantErr: interfaces.ErrInvalidUserSort,
+		},
+		{
+			name:    "Invalid sort direction",
+			filter:  interfaces.UserFilter{SortBy: "email", SortDir: "sideways"},
+			wantErr: interfaces.ErrInvalidUserSort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := repo.Query(ctx, tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Query() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			emails := make([]string, len(got))
+			for i, user := range got {
+				emails[i] = user.Email
+			}
+			if total != tt.wantTotal || !slices.Equal(emails, tt.wantEmails) {
+				t.Errorf("Query() = %v of %d, want %v of %d", emails, total, tt.wantEmails, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestUserRepository_BulkUpdateStatus(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	users := []models.User{
+		{Email: "user1@example.com", Password: "password123", FirstName: "User", LastName: "One", Role: models.RoleUser},
+		{Email: "user2@example.com", Password: "password123", FirstName: "User", LastName: "Two", Role: models.RoleUser},
+		{Email: "user3@example.com", Password: "password123", FirstName: "User", LastName: "Three", Role: models.RoleUser},
+	}
+	for i := range users {
+		if err := repo.Create(ctx, &users[i]); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		ids      []uint
+		isActive bool
+		want     int64
+	}{
+		{"Empty ID list", nil, false, 0},
+		{"Deactivate", []uint{users[0].ID, users[1].ID, 999}, false, 2},
+		{"Already inactive", []uint{users[0].ID}, false, 0},
+		{"Activate", []uint{users[0].ID, users[1].ID, users[2].ID}, true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.BulkUpdateStatus(ctx, tt.ids, tt.isActive)
+			if err != nil {
+				t.Fatalf("BulkUpdateStatus() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BulkUpdateStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// Only the deactivation incremented the token versions, and only of the users it changed
+	for i, want := range []uint{1, 1, 0} {
+		found, err := repo.GetByID(ctx, users[i].ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if !found.IsActive || found.TokenVersion != want {
+			t.Errorf("user %d active = %v, token version %d, want active, token version %d", found.ID, found.IsActive, found.TokenVersion, want)
+		}
+	}
+}
+
+func TestUserRepository_CanceledContext(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	user := &models.User{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+		Role:      models.RoleUser,
+	}
+	if err := repo.Create(ctx, user); !errors.Is(err, context.Canceled) {
+		t.Errorf("Create() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := repo.GetByEmail(ctx, "test@example.com"); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetByEmail() error = %v, want %v", err, context.Canceled)
+	}
+	if _, _, err := repo.ListAfter(ctx, 0, 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListAfter() error = %v, want %v", err, context.Canceled)
+	}
+	if _, _, err := repo.Query(ctx, interfaces.UserFilter{Search: "john"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Query() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := repo.BulkUpdateStatus(ctx, []uint{1}, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("BulkUpdateStatus() error = %v, want %v", err, context.Canceled)
+	}
+
+	// Nothing was written by the canceled call
+	if count, err := repo.Count(context.Background()); err != nil || count != 0 {
+		t.Errorf("Count() = %d, %v, want 0 users", count, err)
+	}
+}
+
+func TestUserRepository_FailedLogins(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{Email: "test@example.com", Password: "password123", FirstName: "John", LastName: "Doe"}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	lockedUntil := time.Now().Add(time.Hour).Truncate(time.Second)
+	for i := 0; i < 2; i++ {
+		if err := repo.RecordFailedLogin(ctx, user.ID, 3, lockedUntil); err != nil {
+			t.Fatalf("RecordFailedLogin() error = %v", err)
+		}
+	}
+	found, _ := repo.GetByID(ctx, user.ID)
+	if found.FailedLoginAttempts != 2 || found.LockedUntil != nil {
+		t.Fatalf("after 2 failures attempts = %d, locked until %v, want 2 and not locked", found.FailedLoginAttempts, found.LockedUntil)
+	}
+
+	// Saving a user loaded earlier keeps the count
+	user.FirstName = "Jane"
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// The third failure locks the account and starts the count over
+	if err := repo.RecordFailedLogin(ctx, user.ID, 3, lockedUntil); err != nil {
+		t.Fatalf("RecordFailedLogin() error = %v", err)
+	}
+	found, _ = repo.GetByID(ctx, user.ID)
+	if found.FailedLoginAttempts != 0 || found.LockedUntil == nil || !found.LockedUntil.Equal(lockedUntil) {
+		t.Fatalf("after 3 failures attempts = %d, locked until %v, want 0 and locked until %v", found.FailedLoginAttempts, found.LockedUntil, lockedUntil)
+	}
+
+	if err := repo.ResetFailedLogins(ctx, user.ID); err != nil {
+		t.Fatalf("ResetFailedLogins() error = %v", err)
+	}
+	found, _ = repo.GetByID(ctx, user.ID)
+	if found.FailedLoginAttempts != 0 || found.LockedUntil != nil {
+		t.Errorf("after reset attempts = %d, locked until %v, want 0 and not locked", found.FailedLoginAttempts, found.LockedUntil)
+	}
+}
+
+func TestUserRepository_Restore(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{Email: "test@example.com", Password: "password123", FirstName: "John", LastName: "Doe"}
+	active := &models.User{Email: "active@example.com", Password: "password123", FirstName: "Jane", LastName: "Doe"}
+	for _, u := range []*models.User{user, active} {
+		if err := repo.Create(ctx, u); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	if err := repo.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByID() after delete error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	deleted, total, err := repo.ListDeletedWithCount(ctx, 0, 10, "")
+	if err != nil {
+		t.Fatalf("ListDeletedWithCount() error = %v", err)
+	}
+	if total != 1 || len(deleted) != 1 || deleted[0].ID != user.ID {
+		t.Fatalf("ListDeletedWithCount() = %d users, total %d, want only user %d", len(deleted), total, user.ID)
+	}
+
+	if err := repo.Restore(ctx, user.ID); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	found, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID() after restore error = %v", err)
+	}
+	if found.Email != user.Email || found.DeletedAt.Valid {
+		t.Errorf("GetByID() after restore = %s, deleted at %v, want %s not deleted", found.Email, found.DeletedAt, user.Email)
+	}
+
+	if _, total, _ := repo.ListDeletedWithCount(ctx, 0, 10, ""); total != 0 {
+		t.Errorf("ListDeletedWithCount() total after restore = %d, want 0", total)
+	}
+
+	// Only deleted users can be restored
+	for _, id := range []uint{user.ID, active.ID, 999} {
+		if err := repo.Restore(ctx, id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("Restore(%d) error = %v, want %v", id, err, gorm.ErrRecordNotFound)
+		}
+	}
+}
+
+func TestUserRepository_DeleteMany(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	admin := &models.User{Email: "admin@example.com", Password: "password123", FirstName: "Ada", LastName: "Admin", Role: models.RoleAdmin}
+	otherAdmin := &models.User{Email: "other@example.com", Password: "password123", FirstName: "Otto", LastName: "Admin", Role: models.RoleAdmin}
+	user := &models.User{Email: "user@example.com", Password: "password123", FirstName: "John", LastName: "Doe", Role: models.RoleUser}
+	for _, u := range []*models.User{admin, otherAdmin, user} {
+		if err := repo.Create(ctx, u); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	ids := []uint{admin.ID, otherAdmin.ID, user.ID}
+	entries := make([]models.AuditLog, len(ids))
+	for i, id := range ids {
+		entries[i] = models.AuditLog{ActorID: 99, Action: models.AuditActionUserDeleted, TargetType: "user", TargetID: id}
+	}
+
+	// Deleting every admin keeps the last one and skips its audit entry
+	kept, err := repo.DeleteMany(ctx, ids, entries)
+	if err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	if !slices.Equal(kept, []uint{otherAdmin.ID}) {
+		t.Errorf("DeleteMany() kept = %v, want [%d]", kept, otherAdmin.ID)
+	}
+
+	var remaining []models.User
+	db.Find(&remaining)
+	if len(remaining) != 1 || remaining[0].ID != otherAdmin.ID {
+		t.Errorf("DeleteMany() remaining users = %+v, want only user %d", remaining, otherAdmin.ID)
+	}
+
+	var logged []models.AuditLog
+	db.Order("target_id").Find(&logged)
+	if len(logged) != 2 || logged[0].TargetID != admin.ID || logged[1].TargetID != user.ID {
+		t.Errorf("DeleteMany() audit entries = %+v, want entries for users %d and %d", logged, admin.ID, user.ID)
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"time"
+)
+
+// auditLogDateLayout is the date-only form accepted by the from and to filters, read as UTC
+const auditLogDateLayout = "2006-01-02"
+
+// ErrInvalidDateRange is returned when an audit log search ends before it starts.
+var ErrInvalidDateRange = &FieldError{
+	Field:   "to",
+	Code:    utils.CodeInvalidDateRange,
+	Message: "to must not be before from",
+}
+
+// AuditLogQuery holds the filters of an audit log search. From and To accept a date, which
+// covers the whole day, or an RFC 3339 timestamp; both bounds are inclusive.
+type AuditLogQuery struct {
+	ActorID uint   `form:"actor_id"`
+	Action  string `form:"action" binding:"omitempty,max=100"`
+	From    string `form:"from"`
+	To      string `form:"to"`
+}
+
+// AuditLogService lets admins search the audit log
+type AuditLogService struct {
+	auditRepo interfaces.AuditLogRepository
+}
+
+// NewAuditLogService creates a new instance of AuditLogService.
+func NewAuditLogService(auditRepo interfaces.AuditLogRepository) *AuditLogService {
+	return &AuditLogService{auditRepo: auditRepo}
+}
+
+// Search returns a page of the entries matching query, newest first, along with the total number of matches
+func (s *AuditLogService) Search(ctx context.Context, query *AuditLogQuery, offset, limit int) ([]models.AuditLogEntry, int64, error) {
+	filter, err := auditLogFilter(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries, total, err := s.auditRepo.Search(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, 0, errors.New("failed to search audit log")
+	}
+	return entries, total, nil
+}
+
+// Export returns every entry matching query, newest first
+func (s *AuditLogService) Export(ctx context.Context, query *AuditLogQuery) ([]models.AuditLogEntry, error) {
+	filter, err := auditLogFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.auditRepo.SearchAll(ctx, filter)
+	if err != nil {
+		return nil, errors.New("failed to export audit log")
+	}
+	return entries, nil
+}
+
+// auditLogFilter validates the query's dates and converts it into a repository filter
+func auditLogFilter(query *AuditLogQuery) (interfaces.AuditLogFilter, error) {
+	filter := interfaces.AuditLogFilter{ActorID: query.ActorID, Action: query.Action}
+
+	if query.From != "" {
+		from, _, err := parseAuditLogTime("from", query.From)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = &from
+	}
+
+	if query.To != "" {
+		to, dateOnly, err := parseAuditLogTime("to", query.To)
+		if err != nil {
+			return filter, err
+		}
+		// A date covers the whole day
+		if dateOnly {
+			to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		filter.To = &to
+	}
+
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, ErrInvalidDateRange
+	}
+	return filter, nil
+}
+
+// parseAuditLogTime parses a date or an RFC 3339 timestamp, reporting which one it was
+func parseAuditLogTime(field, value string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse(auditLogDateLayout, value); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), false, nil
+	}
+	return time.Time{}, false, &FieldError{
+		Field:   field,
+		Code:    utils.CodeInvalidDate,
+		Message: field + " must be a date (YYYY-MM-DD) or an RFC 3339 timestamp",
+	}
+}
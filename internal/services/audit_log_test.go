@@ -0,0 +1,71 @@
+package services
+
+import (
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuditLogFilter_Dates(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    AuditLogQuery
+		wantFrom string
+		wantTo   string
+	}{
+		{"No dates", AuditLogQuery{}, "", ""},
+		{"Dates cover whole days", AuditLogQuery{From: "2024-03-01", To: "2024-03-02"}, "2024-03-01T00:00:00Z", "2024-03-02T23:59:59.999999999Z"},
+		{"Same day", AuditLogQuery{From: "2024-03-01", To: "2024-03-01"}, "2024-03-01T00:00:00Z", "2024-03-01T23:59:59.999999999Z"},
+		{"Timestamps are exact", AuditLogQuery{From: "2024-03-01T10:00:00+02:00", To: "2024-03-01T12:30:00Z"}, "2024-03-01T08:00:00Z", "2024-03-01T12:30:00Z"},
+	}
+
+	format := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339Nano)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := auditLogFilter(&tt.query)
+			if err != nil {
+				t.Fatalf("auditLogFilter() error = %v", err)
+			}
+			if got := format(filter.From); got != tt.wantFrom {
+				t.Errorf("auditLogFilter() from = %q, want %q", got, tt.wantFrom)
+			}
+			if got := format(filter.To); got != tt.wantTo {
+				t.Errorf("auditLogFilter() to = %q, want %q", got, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestAuditLogFilter_InvalidDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     AuditLogQuery
+		wantField string
+		wantCode  string
+	}{
+		{"Malformed from", AuditLogQuery{From: "yesterday"}, "from", utils.CodeInvalidDate},
+		{"Impossible date", AuditLogQuery{To: "2024-02-30"}, "to", utils.CodeInvalidDate},
+		{"To before from", AuditLogQuery{From: "2024-03-02", To: "2024-03-01"}, "to", utils.CodeInvalidDateRange},
+		{"To before from on the same day", AuditLogQuery{From: "2024-03-01T12:00:00Z", To: "2024-03-01T11:00:00Z"}, "to", utils.CodeInvalidDateRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := auditLogFilter(&tt.query)
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("auditLogFilter() error = %v, want a *FieldError", err)
+			}
+			if fieldErr.Field != tt.wantField || fieldErr.Code != tt.wantCode {
+				t.Errorf("auditLogFilter() error = %s %s, want %s %s", fieldErr.Field, fieldErr.Code, tt.wantField, tt.wantCode)
+			}
+		})
+	}
+}
@@ -1,28 +1,195 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"customable-corporate-site-api/internal/events"
+	"customable-corporate-site-api/internal/jwtutil"
 	"customable-corporate-site-api/internal/models"
 	"customable-corporate-site-api/internal/repositories/interfaces"
+	"customable-corporate-site-api/internal/store"
+	"customable-corporate-site-api/internal/utils"
+	"encoding/hex"
 	"errors"
-	"strings"
+	"regexp"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
 )
 
 // AuthService defines the interface for authentication services.
+// Methods take the caller's context, which is passed on to every repository call.
 type AuthService struct {
 	userRepo  interfaces.UserRepository
 	jwtSecret string
 	jwtExpiry time.Duration
+	// roleExpiry overrides jwtExpiry for access tokens of the listed roles
+	roleExpiry map[string]time.Duration
+
+	// signingKeys maps key IDs to secrets; tokens are signed with activeKeyID and
+	// verified with whichever key their kid header names
+	signingKeys map[string]string
+	activeKeyID string
+	// privateKey switches signing to RS256 when set
+	privateKey *rsa.PrivateKey
+
+	// sessionRepo persists refresh tokens as sessions; nil keeps refresh tokens stateless
+	sessionRepo   interfaces.SessionRepository
+	refreshExpiry time.Duration
+	// rememberMeExpiry replaces refreshExpiry for logins that ask to be remembered
+	rememberMeExpiry time.Duration
+	// blacklist records tokens revoked by logging out; nil leaves them valid until they expire
+	blacklist interfaces.TokenBlacklist
+
+	// lockoutAttempts consecutive failed logins lock the account for lockoutDuration;
+	// 0 disables the lockout
+	lockoutAttempts int
+	lockoutDuration time.Duration
+
+	// events receives lifecycle events such as registrations; nil disables publishing
+	events *events.Bus
+
+	// passwordPolicy is checked on registration and password changes
+	passwordPolicy utils.PasswordPolicy
+
+	// mxResolver checks that registration emails can receive mail; nil skips the check
+	mxResolver MXResolver
+	mxTimeout  time.Duration
+	mxStrict   bool
+
+	// blockedDomains rejects registrations from these email domains
+	blockedDomains domainBlocklist
+	// reservedEmails rejects public registrations of reserved addresses
+	reservedEmails reservedEmails
+
+	// captcha verifies the CAPTCHA token sent with registrations; nil skips the check
+	captcha CaptchaVerifier
+
+	// mailer sends email change confirmations; nil disables email changes by link
+	mailer            Mailer
+	emailChangeURL    string
+	emailChangeExpiry time.Duration
+
+	// emailVerifyURL is the link sent at registration to verify the email; empty sends none
+	emailVerifyURL    string
+	emailVerifyExpiry time.Duration
+	// requireEmailVerification refuses logins until the email is verified
+	requireEmailVerification bool
+	// verifyResends records recently resent verification links, so each email is sent at
+	// most one per verifyResendCooldown
+	verifyResends        store.Store
+	verifyResendCooldown time.Duration
 }
 
-// JWT Claims structure
-type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	jwt.RegisteredClaims
+// Default refresh token lifetimes, for regular logins and for logins with remember_me.
+const (
+	defaultRefreshExpiry    = 7 * 24 * time.Hour
+	defaultRememberMeExpiry = 30 * 24 * time.Hour
+)
+
+// defaultLockoutDuration is how long an account stays locked after repeated failed logins.
+const defaultLockoutDuration = 15 * time.Minute
+
+// ErrSessionNotFound is returned when a session does not exist or belongs to another user.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrTokenRevoked is returned for tokens issued before the user's tokens were revoked.
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// ErrAccountLocked is returned by Login while an account is locked after repeated failures.
+var ErrAccountLocked = errors.New("account temporarily locked")
+
+// ErrInvalidCurrentPassword is returned when a sensitive change is confirmed with the wrong password.
+var ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+
+// AuthConfig holds the configuration for the authentication service.
+type AuthConfig struct {
+	JWTSecret string
+	JWTExpiry time.Duration
+
+	// RoleExpiry sets the access token lifetime per role (e.g. admin 15m, user 24h).
+	// Roles not listed use JWTExpiry.
+	RoleExpiry map[string]time.Duration
+
+	// SigningKeys and ActiveKeyID enable key rotation. When set, new tokens carry a kid
+	// header naming the active key, and tokens signed with any other known key stay valid
+	// until they expire. Tokens without a kid are verified with JWTSecret.
+	SigningKeys map[string]string
+	ActiveKeyID string
+
+	// PrivateKey, when set, signs tokens with RS256 instead of HS256 and verifies them with
+	// its public key, so other services can verify tokens without holding the signing key.
+	// JWTSecret and SigningKeys are then unused.
+	PrivateKey *rsa.PrivateKey
+
+	// Sessions, when set, persists every issued refresh token so users can list and
+	// revoke their sessions. Refresh tokens whose session is revoked are rejected.
+	Sessions interfaces.SessionRepository
+
+	// RefreshExpiry is the refresh token lifetime (default 7 days). Logins with remember_me
+	// get RememberMeExpiry instead (default 30 days). Access tokens are unaffected.
+	RefreshExpiry    time.Duration
+	RememberMeExpiry time.Duration
+
+	// Blacklist, when set, records the jti of tokens passed to Logout until they expire, so
+	// they are rejected even though their signature is still valid.
+	Blacklist interfaces.TokenBlacklist
+
+	// LockoutAttempts consecutive failed logins lock the account for LockoutDuration
+	// (default 15m). Logins are refused while locked, even with the right password.
+	// 0 disables the lockout.
+	LockoutAttempts int
+	LockoutDuration time.Duration
+
+	// Events, when set, receives lifecycle events such as new registrations.
+	Events *events.Bus
+
+	// MXResolver, when set, rejects registrations whose email domain has no mail servers.
+	// Lookups give up after MXTimeout (default 2s) and a failed lookup lets the
+	// registration through, unless MXStrict is set.
+	MXResolver MXResolver
+	MXTimeout  time.Duration
+	MXStrict   bool
+
+	// BlockedEmailDomains rejects registrations from these domains, matched case-insensitively.
+	// "*.example.com" blocks every subdomain of example.com. Empty by default.
+	BlockedEmailDomains []string
+
+	// ReservedEmailLocalParts rejects registrations whose local part (ignoring any +tag) is
+	// listed, such as "postmaster". ReservedEmailPatterns rejects registrations whose whole,
+	// lowercased email matches. Both are empty by default; admins managing users bypass them.
+	ReservedEmailLocalParts []string
+	ReservedEmailPatterns   []*regexp.Regexp
+
+	// PasswordPolicy sets the rules new passwords must follow on registration and password
+	// changes. The zero value only requires 6 characters, like the request bindings.
+	PasswordPolicy utils.PasswordPolicy
+
+	// Captcha, when set, requires registrations to carry a captcha_token it accepts.
+	Captcha CaptchaVerifier
+
+	// Mailer, when set, lets users change their email by confirming a link sent to the new
+	// address. The link is EmailChangeURL with a token query parameter and stays valid for
+	// EmailChangeExpiry (default 24h).
+	Mailer            Mailer
+	EmailChangeURL    string
+	EmailChangeExpiry time.Duration
+
+	// EmailVerifyURL, when set together with Mailer, sends new users a link verifying their
+	// email. The link is EmailVerifyURL with a token query parameter and stays valid for
+	// EmailVerifyExpiry (default 24h). RequireEmailVerification refuses logins until the
+	// link is opened.
+	EmailVerifyURL           string
+	EmailVerifyExpiry        time.Duration
+	RequireEmailVerification bool
+
+	// VerifyResends records the verification links resent on request, so each email gets
+	// at most one per VerifyResendCooldown (default 60s). A shared Store applies the
+	// cooldown across instances; nil uses an in-memory store.
+	VerifyResends        store.Store
+	VerifyResendCooldown time.Duration
 }
 
 // Request DTOs
@@ -31,25 +198,71 @@ type RegisterRequest struct {
 	Password  string `json:"password" binding:"required,min=6"`
 	FirstName string `json:"first_name" binding:"required,min=2,max=50"`
 	LastName  string `json:"last_name" binding:"required,min=2,max=50"`
+	// CaptchaToken is required when CAPTCHA verification is enabled
+	CaptchaToken string `json:"captcha_token"`
+
+	// Client details passed on to the CAPTCHA provider, filled in by the handler
+	IPAddress string `json:"-"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	// RememberMe issues a longer-lived refresh token
+	RememberMe bool `json:"remember_me"`
+
+	// Client details recorded on the session, filled in by the handler
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
 }
 
+// UpdateProfileRequest replaces the whole profile, so every field is required
 type UpdateProfileRequest struct {
-	FirstName string `json:"first_name" binding:"omitempty,min=2,max=50"`
-	LastName  string `json:"last_name" binding:"omitempty,min=2,max=50"`
+	FirstName string `json:"first_name" binding:"required,min=2,max=50"`
+	LastName  string `json:"last_name" binding:"required,min=2,max=50"`
+}
+
+// PatchProfileRequest updates only the fields present in the request body.
+// Pointer fields distinguish an omitted field (nil) from one explicitly set.
+type PatchProfileRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,min=2,max=50"`
+	LastName  *string `json:"last_name" binding:"omitempty,min=2,max=50"`
+}
+
+// ChangeEmailRequest changes the login email once confirmed by a link sent to the new
+// address; it requires the current password
+type ChangeEmailRequest struct {
+	Email           string `json:"email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+// ChangePasswordRequest replaces the password; it requires the current password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ResendVerificationRequest asks for a new link verifying the account's email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// DeleteAccountRequest confirms account deletion with the current password
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
 }
 
 // Response DTOs
+// TokenResponse carries issued tokens. The tokens are left empty (and omitted) when
+// they are delivered as cookies instead.
 type TokenResponse struct {
-	AccessToken  string               `json:"access_token"`
-	RefreshToken string               `json:"refresh_token"`
-	ExpiresIn    int64                `json:"expires_in"`
-	TokenType    string               `json:"token_type"`
-	User         *models.UserResponse `json:"user"`
+	AccessToken      string               `json:"access_token,omitempty"`
+	RefreshToken     string               `json:"refresh_token,omitempty"`
+	ExpiresIn        int64                `json:"expires_in"`
+	RefreshExpiresIn int64                `json:"refresh_expires_in"`
+	RememberMe       bool                 `json:"remember_me"`
+	TokenType        string               `json:"token_type"`
+	User             *models.UserResponse `json:"user"`
 }
 
 type AuthResponse struct {
@@ -60,24 +273,120 @@ type AuthResponse struct {
 
 // NewAuthService creates a new instance of AuthService.
 func NewAuthService(userRepo interfaces.UserRepository, jwtSecret string, jwtExpiry time.Duration) *AuthService {
+	return NewAuthServiceWithConfig(userRepo, AuthConfig{
+		JWTSecret: jwtSecret,
+		JWTExpiry: jwtExpiry,
+	})
+}
+
+// NewAuthServiceWithConfig creates a new instance of AuthService with custom configuration.
+func NewAuthServiceWithConfig(userRepo interfaces.UserRepository, cfg AuthConfig) *AuthService {
+	mxTimeout := cfg.MXTimeout
+	if mxTimeout <= 0 {
+		mxTimeout = defaultMXTimeout
+	}
+	refreshExpiry := cfg.RefreshExpiry
+	if refreshExpiry <= 0 {
+		refreshExpiry = defaultRefreshExpiry
+	}
+	rememberMeExpiry := cfg.RememberMeExpiry
+	if rememberMeExpiry <= 0 {
+		rememberMeExpiry = defaultRememberMeExpiry
+	}
+	emailChangeExpiry := cfg.EmailChangeExpiry
+	if emailChangeExpiry <= 0 {
+		emailChangeExpiry = defaultEmailChangeExpiry
+	}
+	emailVerifyExpiry := cfg.EmailVerifyExpiry
+	if emailVerifyExpiry <= 0 {
+		emailVerifyExpiry = defaultEmailVerifyExpiry
+	}
+	verifyResends := cfg.VerifyResends
+	if verifyResends == nil {
+		verifyResends = store.NewMemoryStore()
+	}
+	verifyResendCooldown := cfg.VerifyResendCooldown
+	if verifyResendCooldown <= 0 {
+		verifyResendCooldown = defaultVerifyResendCooldown
+	}
+	lockoutDuration := cfg.LockoutDuration
+	if lockoutDuration <= 0 {
+		lockoutDuration = defaultLockoutDuration
+	}
+
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
-		jwtExpiry: jwtExpiry,
+		userRepo:    userRepo,
+		jwtSecret:   cfg.JWTSecret,
+		jwtExpiry:   cfg.JWTExpiry,
+		roleExpiry:  cfg.RoleExpiry,
+		signingKeys: cfg.SigningKeys,
+		activeKeyID: cfg.ActiveKeyID,
+		privateKey:  cfg.PrivateKey,
+
+		sessionRepo:      cfg.Sessions,
+		refreshExpiry:    refreshExpiry,
+		rememberMeExpiry: rememberMeExpiry,
+		blacklist:        cfg.Blacklist,
+
+		lockoutAttempts: cfg.LockoutAttempts,
+		lockoutDuration: lockoutDuration,
+
+		events: cfg.Events,
+
+		passwordPolicy: cfg.PasswordPolicy,
+
+		mxResolver: cfg.MXResolver,
+		mxTimeout:  mxTimeout,
+		mxStrict:   cfg.MXStrict,
+
+		blockedDomains: newDomainBlocklist(cfg.BlockedEmailDomains),
+		reservedEmails: newReservedEmails(cfg.ReservedEmailLocalParts, cfg.ReservedEmailPatterns),
+		captcha:        cfg.Captcha,
+
+		mailer:            cfg.Mailer,
+		emailChangeURL:    cfg.EmailChangeURL,
+		emailChangeExpiry: emailChangeExpiry,
+
+		emailVerifyURL:           cfg.EmailVerifyURL,
+		emailVerifyExpiry:        emailVerifyExpiry,
+		requireEmailVerification: cfg.RequireEmailVerification,
+		verifyResends:            verifyResends,
+		verifyResendCooldown:     verifyResendCooldown,
 	}
 }
 
 // Register creates a new user account.
-func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
-	// Normalize email
-	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*AuthResponse, error) {
+	// Reject bots before touching the database
+	if err := s.checkCaptcha(ctx, req.CaptchaToken, req.IPAddress); err != nil {
+		return nil, err
+	}
+
+	// Normalize email and names
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPasswordStrength("password", req.Password); err != nil {
+		return nil, err
+	}
 
 	// Check if user already exists
-	existingUser, _ := s.userRepo.GetByEmail(req.Email)
+	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
 		return nil, errors.New("user with this email already exists")
 	}
 
+	if err := s.checkReservedEmail(req.Email); err != nil {
+		return nil, err
+	}
+	if err := s.checkBlockedDomain(req.Email); err != nil {
+		return nil, err
+	}
+	if err := s.checkMX(ctx, req.Email); err != nil {
+		return nil, err
+	}
+
 	// Create new user
 	newUser := &models.User{
 		Email:     req.Email,
@@ -88,10 +397,13 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		IsActive:  true,
 	}
 
-	if err := s.userRepo.Create(newUser); err != nil {
+	if err := s.userRepo.Create(ctx, newUser); err != nil {
 		return nil, errors.New("failed to create user account")
 	}
 
+	s.publish(events.UserRegistered, newUser.ToResponse())
+	s.SendVerificationEmail(ctx, newUser)
+
 	return &AuthResponse{
 		Message: "User registered successfully",
 		User:    newUser.ToResponse(),
@@ -99,12 +411,12 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 }
 
 // Login authenticates a user and returns JWT tokens.
-func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
+func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthResponse, error) {
 	// Normalize email
-	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.normalize()
 
 	// Fetch user by email
-	user, err := s.userRepo.GetByEmail(req.Email)
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil || user == nil {
 		return nil, errors.New("invalid email or password")
 	}
@@ -114,13 +426,39 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 		return nil, errors.New("user account is inactive")
 	}
 
+	// Refuse locked accounts before comparing the password, so attempts while locked
+	// neither count nor reveal whether the password was right
+	if s.lockoutAttempts > 0 && user.IsLocked(time.Now()) {
+		return nil, ErrAccountLocked
+	}
+
 	// Verify password
 	if !user.CheckPassword(req.Password) {
+		s.recordFailedLogin(ctx, user)
 		return nil, errors.New("invalid email or password")
 	}
+	if s.requireEmailVerification && !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+			return nil, errors.New("failed to reset failed login attempts")
+		}
+	}
+
+	// Upgrade hashes made with an outdated cost while the plaintext is at hand
+	if user.NeedsRehash() {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+
+	// Start a session for the refresh token when sessions are tracked
+	tokenID, err := s.startSession(ctx, user, req)
+	if err != nil {
+		return nil, errors.New("failed to start session")
+	}
 
 	// Generate JWT tokens
-	tokenResponse, err := s.generateTokenResponse(user)
+	tokenResponse, err := s.generateTokenResponse(user, tokenID, req.RememberMe)
 	if err != nil {
 		return nil, errors.New("failed to generate access token")
 	}
@@ -133,98 +471,382 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 }
 
 // RefreshToken generates a new access token using a refresh token.
-func (s *AuthService) RefreshToken(refreshToken string) (*TokenResponse, error) {
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
 	// Parse and validate the refresh token
-	token, err := jwt.ParseWithClaims(refreshToken, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecret), nil
-	})
-
-	if err != nil || !token.Valid {
+	claims, err := jwtutil.Parse(refreshToken, s.keys().Keyfunc)
+	if err != nil {
 		return nil, errors.New("invalid refresh token")
 	}
 
-	// Generate new access token
-	claims, ok := token.Claims.(*JWTClaims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
-	}
-
 	// Verify is a refresh token
-	if claims.Subject != "refresh_token" {
+	if claims.Subject != jwtutil.SubjectRefresh {
 		return nil, errors.New("invalid token type")
 	}
 
 	// Get user to ensure they still exist and are active
-	user, err := s.userRepo.GetByID(claims.UserID)
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
 	if err != nil || user == nil {
 		return nil, errors.New("user not found")
 	}
+	if claims.TokenVersion != user.TokenVersion {
+		return nil, ErrTokenRevoked
+	}
+	if err := s.checkBlacklist(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	// Rotate the refresh token within its session when sessions are tracked.
+	// The new refresh token keeps the lifetime chosen at login.
+	tokenID, err := s.rotateSession(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.generateTokenResponse(user, tokenID, claims.RememberMe)
+}
+
+// Logout revokes the given access and refresh tokens, either of which may be empty. The
+// refresh token's session is ended and, when a blacklist is configured, both tokens are
+// recorded in it until they expire. Invalid tokens and sessions that are already gone are
+// ignored so that logging out always succeeds.
+func (s *AuthService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	for _, token := range []string{accessToken, refreshToken} {
+		if err := s.revokeToken(ctx, token); err != nil {
+			return err
+		}
+	}
+
+	if s.sessionRepo == nil || refreshToken == "" {
+		return nil
+	}
+
+	claims, err := jwtutil.Parse(refreshToken, s.keys().Keyfunc)
+	if err != nil || claims.Subject != jwtutil.SubjectRefresh || claims.ID == "" {
+		return nil
+	}
+
+	session, err := s.sessionRepo.GetByTokenID(ctx, claims.ID)
+	if err != nil || session == nil || session.UserID != claims.UserID || !session.IsActive() {
+		return nil
+	}
+
+	if err := s.sessionRepo.Revoke(ctx, session.ID, session.UserID); err != nil {
+		return errors.New("failed to end session")
+	}
+	return nil
+}
+
+// ListSessions returns the active sessions of the authenticated user.
+func (s *AuthService) ListSessions(ctx context.Context, userID uint) ([]*models.SessionResponse, error) {
+	if s.sessionRepo == nil {
+		return nil, errors.New("session tracking is not enabled")
+	}
+
+	sessions, err := s.sessionRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New("failed to fetch sessions")
+	}
+
+	responses := make([]*models.SessionResponse, 0, len(sessions))
+	for i := range sessions {
+		responses = append(responses, sessions[i].ToResponse())
+	}
+	return responses, nil
+}
+
+// RevokeSession revokes one of the authenticated user's sessions.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	if s.sessionRepo == nil {
+		return errors.New("session tracking is not enabled")
+	}
+
+	if err := s.sessionRepo.Revoke(ctx, sessionID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return errors.New("failed to revoke session")
+	}
+	return nil
+}
+
+// RevokeAllSessions revokes every session of the authenticated user, logging them out everywhere.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID uint) (int64, error) {
+	if s.sessionRepo == nil {
+		return 0, errors.New("session tracking is not enabled")
+	}
 
-	return s.generateTokenResponse(user)
+	revoked, err := s.sessionRepo.RevokeAllByUser(ctx, userID)
+	if err != nil {
+		return 0, errors.New("failed to revoke sessions")
+	}
+	return revoked, nil
 }
 
 // GetProfile retrieves the profile of the authenticated user.
-func (s *AuthService) GetProfile(userID uint) (*models.UserResponse, error) {
-	user, err := s.userRepo.GetByID(userID)
+func (s *AuthService) GetProfile(ctx context.Context, userID uint) (*models.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil || user == nil {
 		return nil, errors.New("user not found")
 	}
 	return user.ToResponse(), nil
 }
 
-// UpdateProfile updates the profile of the authenticated user.
-func (s *AuthService) UpdateProfile(userID uint, req *UpdateProfileRequest) (*models.UserResponse, error) {
-	user, err := s.userRepo.GetByID(userID)
+// UpdateProfile replaces the profile of the authenticated user.
+func (s *AuthService) UpdateProfile(ctx context.Context, userID uint, req *UpdateProfileRequest) (*models.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil || user == nil {
 		return nil, errors.New("user not found")
 	}
 
-	// Update fields if provided
-	if req.FirstName != "" {
-		user.FirstName = strings.TrimSpace(req.FirstName)
+	if err := req.normalize(); err != nil {
+		return nil, err
 	}
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
 
-	if req.LastName != "" {
-		user.LastName = strings.TrimSpace(req.LastName)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, errors.New("failed to update profile")
 	}
 
-	if err := s.userRepo.Update(user); err != nil {
+	return user.ToResponse(), nil
+}
+
+// PatchProfile updates only the provided fields of the authenticated user's profile.
+func (s *AuthService) PatchProfile(ctx context.Context, userID uint, req *PatchProfileRequest) (*models.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Update fields if present in the request
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, errors.New("failed to update profile")
 	}
 
 	return user.ToResponse(), nil
 }
 
-// ValidateToken validates a JWT token and returns the associated user.
-func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	// Parse and validate the token
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecret), nil
-	})
+// DeleteAccount deletes the authenticated user's account after re-checking their password
+// and revokes all of their sessions.
+func (s *AuthService) DeleteAccount(ctx context.Context, userID uint, req *DeleteAccountRequest) error {
+	if _, err := s.reauthenticate(ctx, userID, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	if s.sessionRepo != nil {
+		if _, err := s.sessionRepo.RevokeAllByUser(ctx, userID); err != nil {
+			return errors.New("failed to revoke sessions")
+		}
+	}
+
+	if err := s.userRepo.Delete(ctx, userID); err != nil {
+		return errors.New("failed to delete account")
+	}
+	return nil
+}
 
-	if err != nil || !token.Valid {
+// ValidateToken validates an access token and returns its claims once the user is found.
+// It applies the same rules as the JWT middleware.
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*jwtutil.Claims, error) {
+	// Parse and validate the token
+	claims, err := jwtutil.Parse(tokenString, s.keys().Keyfunc)
+	if err != nil {
 		return nil, errors.New("invalid or expired token")
 	}
 
-	// Extract user ID from token claims
-	claims, ok := token.Claims.(*JWTClaims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
+	// Only access tokens authenticate requests
+	if claims.Subject != jwtutil.SubjectAccess {
+		return nil, errors.New("invalid token type")
 	}
 
 	// Fetch user by ID
-	user, err := s.userRepo.GetByID(claims.UserID)
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
 	if err != nil || user == nil {
 		return nil, errors.New("user not found")
 	}
+	if claims.TokenVersion != user.TokenVersion {
+		return nil, ErrTokenRevoked
+	}
+	if err := s.checkBlacklist(ctx, claims); err != nil {
+		return nil, err
+	}
 
 	return claims, nil
 }
 
+// IsRevoked reports whether the token with the given jti was revoked by logging out. The JWT
+// middleware rejects such tokens.
+func (s *AuthService) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	if s.blacklist == nil || tokenID == "" {
+		return false, nil
+	}
+	return s.blacklist.IsRevoked(ctx, tokenID)
+}
+
+// TokenVersion returns the user's current token version. The JWT middleware rejects access
+// tokens carrying another version.
+func (s *AuthService) TokenVersion(ctx context.Context, userID uint) (uint, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return 0, ErrUserNotFound
+	}
+	return user.TokenVersion, nil
+}
+
 // Private helper methods
 
+// reauthenticate re-checks the user's current password before a sensitive change,
+// regardless of how fresh their session is.
+func (s *AuthService) reauthenticate(ctx context.Context, userID uint, password string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if !user.CheckPassword(password) {
+		return nil, ErrInvalidCurrentPassword
+	}
+	return user, nil
+}
+
+// revokeToken records a valid token in the blacklist until it expires. Tokens that do not
+// parse, carry no jti or have no expiry are skipped.
+func (s *AuthService) revokeToken(ctx context.Context, token string) error {
+	if s.blacklist == nil || token == "" {
+		return nil
+	}
+
+	claims, err := jwtutil.Parse(token, s.keys().Keyfunc)
+	if err != nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	if err := s.blacklist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return errors.New("failed to revoke token")
+	}
+	return nil
+}
+
+// checkBlacklist rejects tokens revoked by logging out
+func (s *AuthService) checkBlacklist(ctx context.Context, claims *jwtutil.Claims) error {
+	revoked, err := s.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return errors.New("failed to check token revocation")
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// recordFailedLogin counts a failed login towards the lockout. It is best effort: a failure
+// to record it must not change the response to the login.
+func (s *AuthService) recordFailedLogin(ctx context.Context, user *models.User) {
+	if s.lockoutAttempts <= 0 {
+		return
+	}
+	s.userRepo.RecordFailedLogin(ctx, user.ID, s.lockoutAttempts, time.Now().Add(s.lockoutDuration))
+}
+
+// publish sends a lifecycle event when an event bus is configured
+func (s *AuthService) publish(eventType string, data interface{}) {
+	if s.events == nil {
+		return
+	}
+	s.events.Publish(events.New(eventType, data))
+}
+
+// rehashPassword stores a new hash of the user's password at the current cost. It is best
+// effort: on failure the old hash stays valid and the upgrade is retried on the next login.
+func (s *AuthService) rehashPassword(ctx context.Context, user *models.User, password string) {
+	previous := user.Password
+	if err := user.SetPassword(password); err != nil {
+		return
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		user.Password = previous
+	}
+}
+
+// startSession records a new session for the login and returns the ID for its refresh token.
+// It returns an empty ID when sessions are not tracked.
+func (s *AuthService) startSession(ctx context.Context, user *models.User, req *LoginRequest) (string, error) {
+	if s.sessionRepo == nil {
+		return "", nil
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		UserID:     user.ID,
+		TokenID:    tokenID,
+		UserAgent:  req.UserAgent,
+		IPAddress:  req.IPAddress,
+		RememberMe: req.RememberMe,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.refreshLifetime(req.RememberMe)),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return "", err
+	}
+
+	return tokenID, nil
+}
+
+// rotateSession checks that the refresh token's session is still active and assigns it a new token ID.
+// It returns an empty ID when sessions are not tracked.
+func (s *AuthService) rotateSession(ctx context.Context, claims *jwtutil.Claims) (string, error) {
+	if s.sessionRepo == nil {
+		return "", nil
+	}
+
+	session, err := s.sessionRepo.GetByTokenID(ctx, claims.ID)
+	if err != nil || session == nil || session.UserID != claims.UserID || !session.IsActive() {
+		return "", errors.New("session has been revoked or expired")
+	}
+
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", errors.New("failed to rotate refresh token")
+	}
+
+	now := time.Now()
+	session.TokenID = tokenID
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.refreshLifetime(session.RememberMe))
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		return "", errors.New("failed to rotate refresh token")
+	}
+
+	return tokenID, nil
+}
+
+// newTokenID generates a random identifier for the jti claim.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // generateTokenResponse creates access and refresh tokens for a user.
-func (s *AuthService) generateTokenResponse(user *models.User) (*TokenResponse, error) {
+func (s *AuthService) generateTokenResponse(user *models.User, refreshTokenID string, rememberMe bool) (*TokenResponse, error) {
 	// Create access token
 	accessToken, err := s.generateAccessToken(user)
 	if err != nil {
@@ -232,54 +854,117 @@ func (s *AuthService) generateTokenResponse(user *models.User) (*TokenResponse,
 	}
 
 	// Create refresh token
-	refreshToken, err := s.generateRefreshToken(user)
+	refreshToken, err := s.generateRefreshToken(user, refreshTokenID, rememberMe)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    int64(s.jwtExpiry.Seconds()),
-		TokenType:    "Bearer",
-		User:         user.ToResponse(),
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(s.accessExpiry(user).Seconds()),
+		RefreshExpiresIn: int64(s.refreshLifetime(rememberMe).Seconds()),
+		RememberMe:       rememberMe,
+		TokenType:        "Bearer",
+		User:             user.ToResponse(),
 	}, nil
 }
 
+// refreshLifetime returns the refresh token lifetime for a login with or without remember_me.
+func (s *AuthService) refreshLifetime(rememberMe bool) time.Duration {
+	if rememberMe {
+		return s.rememberMeExpiry
+	}
+	return s.refreshExpiry
+}
+
+// accessExpiry returns the access token lifetime for the user's role.
+func (s *AuthService) accessExpiry(user *models.User) time.Duration {
+	if expiry, ok := s.roleExpiry[user.Role]; ok && expiry > 0 {
+		return expiry
+	}
+	return s.jwtExpiry
+}
+
 // generateAccessToken creates a JWT access token for a user.
 func (s *AuthService) generateAccessToken(user *models.User) (string, error) {
-	claims := &JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	claims := &jwtutil.Claims{
+		UserID:       user.ID,
+		Email:        user.Email,
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtExpiry)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.accessExpiry(user))),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Subject:   "access_token",
+			Subject:   jwtutil.SubjectAccess,
 			Issuer:    "customable-corporate-site-api",
+			ID:        tokenID,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	return s.signToken(claims)
 }
 
-// generateRefreshToken creates a JWT refresh token for a user.
-func (s *AuthService) generateRefreshToken(user *models.User) (string, error) {
-	claims := &JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+// generateRefreshToken creates a JWT refresh token for a user. The jti is the session's
+// token ID when sessions are tracked, and a random ID otherwise.
+func (s *AuthService) generateRefreshToken(user *models.User, tokenID string, rememberMe bool) (string, error) {
+	if tokenID == "" {
+		var err error
+		if tokenID, err = newTokenID(); err != nil {
+			return "", err
+		}
+	}
+
+	claims := &jwtutil.Claims{
+		UserID:       user.ID,
+		Email:        user.Email,
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+		RememberMe:   rememberMe,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // Refresh token valid for 7 days
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.refreshLifetime(rememberMe))),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Subject:   "refresh_token",
+			Subject:   jwtutil.SubjectRefresh,
 			Issuer:    "customable-corporate-site-api",
+			ID:        tokenID,
 		},
 	}
 
+	return s.signToken(claims)
+}
+
+// signToken signs the claims with the RSA private key when configured, and otherwise with
+// the active HMAC key, recording its ID in the kid header.
+func (s *AuthService) signToken(claims *jwtutil.Claims) (string, error) {
+	if s.privateKey != nil {
+		return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(s.privateKey)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	if s.activeKeyID == "" {
+		return token.SignedString([]byte(s.jwtSecret))
+	}
+
+	secret, ok := s.signingKeys[s.activeKeyID]
+	if !ok {
+		return "", errors.New("active signing key not found")
+	}
+	token.Header["kid"] = s.activeKeyID
+	return token.SignedString([]byte(secret))
+}
+
+// keys returns the keys tokens are verified with: the RSA public key when tokens are
+// signed with RS256, otherwise the HMAC secrets.
+func (s *AuthService) keys() jwtutil.Keys {
+	if s.privateKey != nil {
+		return jwtutil.Keys{PublicKey: &s.privateKey.PublicKey}
+	}
+	return jwtutil.Keys{Secret: s.jwtSecret, ByID: s.signingKeys}
 }
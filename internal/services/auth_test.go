@@ -1,12 +1,23 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"customable-corporate-site-api/internal/events"
+	"customable-corporate-site-api/internal/jwtutil"
 	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/memory"
 	"customable-corporate-site-api/internal/repositories/postgres"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin/binding"
 	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -60,7 +71,7 @@ func TestAuthService_Register(t *testing.T) {
 	// Run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := authService.Register(tt.req)
+			resp, err := authService.Register(context.Background(), tt.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -77,6 +88,83 @@ func TestAuthService_Register(t *testing.T) {
 	}
 }
 
+func TestAuthService_PasswordPolicy(t *testing.T) {
+	_, db := setupTestService(t)
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret:      "test_secret-key",
+		JWTExpiry:      24 * time.Hour,
+		PasswordPolicy: utils.DefaultPasswordPolicy(),
+	})
+	ctx := context.Background()
+
+	register := func(email, password string) error {
+		_, err := authService.Register(ctx, &RegisterRequest{Email: email, Password: password, FirstName: "John", LastName: "Doe"})
+		return err
+	}
+
+	// Every broken rule is reported on the password field
+	err := register("weak@example.com", "password123")
+	var fieldErrs FieldErrors
+	if !errors.As(err, &fieldErrs) || len(fieldErrs) != 2 || fieldErrs[0].Field != "password" {
+		t.Fatalf("Register() with a weak password error = %v, want the missing uppercase letter and the common password", err)
+	}
+	if fieldErrs[0].Code != utils.CodePasswordMissingUpper || fieldErrs[1].Code != utils.CodePasswordTooCommon {
+		t.Errorf("Register() error codes = %s, %s, want %s, %s", fieldErrs[0].Code, fieldErrs[1].Code, utils.CodePasswordMissingUpper, utils.CodePasswordTooCommon)
+	}
+
+	if err := register("strong@example.com", "Correct7Horse"); err != nil {
+		t.Fatalf("Register() with a strong password error = %v", err)
+	}
+	var registered models.User
+	if err := db.Where("email = ?", "strong@example.com").First(&registered).Error; err != nil {
+		t.Fatalf("Failed to load registered user: %v", err)
+	}
+
+	if err := authService.ChangePassword(ctx, registered.ID, "Correct7Horse", "short"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Errorf("ChangePassword() error = %v, want %v", err, ErrPasswordTooShort)
+	}
+	if err := authService.ChangePassword(ctx, registered.ID, "Correct7Horse", "battery-staple"); !errors.As(err, &fieldErrs) || fieldErrs[0].Field != "new_password" {
+		t.Errorf("ChangePassword() error = %v, want errors on new_password", err)
+	}
+	if err := authService.ChangePassword(ctx, registered.ID, "Correct7Horse", "Battery9Staple"); err != nil {
+		t.Errorf("ChangePassword() with a strong password error = %v", err)
+	}
+}
+
+func TestAuthService_Register_PublishesEvent(t *testing.T) {
+	_, db := setupTestService(t)
+	bus := events.NewBus()
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret: "test_secret-key",
+		JWTExpiry: 24 * time.Hour,
+		Events:    bus,
+	})
+
+	stream, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	if _, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+	}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	select {
+	case event := <-stream:
+		if event.Type != events.UserRegistered {
+			t.Errorf("Register() event type = %q, want %q", event.Type, events.UserRegistered)
+		}
+		if user, ok := event.Data.(*models.UserResponse); !ok || user.Email != "test@example.com" {
+			t.Errorf("Register() event data = %v, want the registered user", event.Data)
+		}
+	default:
+		t.Errorf("Register() published no event")
+	}
+}
+
 func TestAuthService_Login(t *testing.T) {
 	authService, _ := setupTestService(t)
 
@@ -87,7 +175,7 @@ func TestAuthService_Login(t *testing.T) {
 		FirstName: "John",
 		LastName:  "Doe",
 	}
-	_, err := authService.Register(registerReq)
+	_, err := authService.Register(context.Background(), registerReq)
 	if err != nil {
 		t.Fatalf("Failed to register test user: %v", err)
 	}
@@ -127,7 +215,7 @@ func TestAuthService_Login(t *testing.T) {
 	// Run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := authService.Login(tt.req)
+			resp, err := authService.Login(context.Background(), tt.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Login() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -157,7 +245,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 		FirstName: "John",
 		LastName:  "Doe",
 	}
-	_, err := authService.Register(registerReq)
+	_, err := authService.Register(context.Background(), registerReq)
 	if err != nil {
 		t.Fatalf("Failed to register test user: %v", err)
 	}
@@ -166,7 +254,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 		Email:    "test@example.com",
 		Password: "password123",
 	}
-	loginResp, err := authService.Login(loginReq)
+	loginResp, err := authService.Login(context.Background(), loginReq)
 	if err != nil {
 		t.Fatalf("Failed to login test user: %v", err)
 	}
@@ -197,7 +285,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	// Run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			claims, err := authService.ValidateToken(tt.token)
+			claims, err := authService.ValidateToken(context.Background(), tt.token)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -208,6 +296,56 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	}
 }
 
+func TestAuthService_RejectsOtherAlgorithms(t *testing.T) {
+	authService, _ := setupTestService(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	// Forge tokens carrying the same claims as the issued ones under a different alg
+	forge := func(tokenString string, method jwt.SigningMethod, key interface{}) string {
+		claims := &jwtutil.Claims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+			t.Fatalf("Failed to decode issued token: %v", err)
+		}
+		forged, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign forged token: %v", err)
+		}
+		return forged
+	}
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		key    interface{}
+	}{
+		{"none", jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType},
+		{"RS256", jwt.SigningMethodRS256, rsaKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := authService.ValidateToken(context.Background(), forge(login.Token.AccessToken, tt.method, tt.key)); err == nil {
+				t.Errorf("ValidateToken() accepted a token signed with %s", tt.name)
+			}
+			if _, err := authService.RefreshToken(context.Background(), forge(login.Token.RefreshToken, tt.method, tt.key)); err == nil {
+				t.Errorf("RefreshToken() accepted a token signed with %s", tt.name)
+			}
+		})
+	}
+
+	// A key function that skipped the method check would hand these tokens the HMAC secret
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodNone, jwt.SigningMethodRS256} {
+		if key, err := authService.keys().Keyfunc(&jwt.Token{Method: method, Header: map[string]interface{}{}}); err == nil {
+			t.Errorf("Keyfunc(%s) = %v, want an error", method.Alg(), key)
+		}
+	}
+}
+
 func TestAuthService_RefreshToken(t *testing.T) {
 	authService, _ := setupTestService(t)
 
@@ -218,7 +356,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		FirstName: "John",
 		LastName:  "Doe",
 	}
-	_, err := authService.Register(registerReq)
+	_, err := authService.Register(context.Background(), registerReq)
 	if err != nil {
 		t.Fatalf("Failed to register test user: %v", err)
 	}
@@ -227,7 +365,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		Email:    "test@example.com",
 		Password: "password123",
 	}
-	loginResp, err := authService.Login(loginReq)
+	loginResp, err := authService.Login(context.Background(), loginReq)
 	if err != nil {
 		t.Fatalf("Failed to login test user: %v", err)
 	}
@@ -258,7 +396,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	// Run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp, err := authService.RefreshToken(tt.token)
+			resp, err := authService.RefreshToken(context.Background(), tt.token)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("RefreshToken() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -285,7 +423,7 @@ func TestAuthService_GetProfile(t *testing.T) {
 		FirstName: "John",
 		LastName:  "Doe",
 	}
-	registerResp, err := authService.Register(registerReq)
+	registerResp, err := authService.Register(context.Background(), registerReq)
 	if err != nil {
 		t.Fatalf("Failed to register test user: %v", err)
 	}
@@ -311,7 +449,7 @@ func TestAuthService_GetProfile(t *testing.T) {
 	// Run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user, err := authService.GetProfile(tt.userID)
+			user, err := authService.GetProfile(context.Background(), tt.userID)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetProfile() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -328,6 +466,18 @@ func TestAuthService_GetProfile(t *testing.T) {
 	}
 }
 
+func TestAuthService_GetProfile_CanceledContext(t *testing.T) {
+	authService, _ := setupTestService(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := authService.GetProfile(ctx, login.User.ID); err == nil {
+		t.Errorf("GetProfile() with canceled context error = nil, want an error")
+	}
+}
+
 func TestAuthService_UpdateProfile(t *testing.T) {
 	authService, _ := setupTestService(t)
 
@@ -338,7 +488,7 @@ func TestAuthService_UpdateProfile(t *testing.T) {
 		FirstName: "John",
 		LastName:  "Doe",
 	}
-	_, err := authService.Register(registerReq)
+	_, err := authService.Register(context.Background(), registerReq)
 	if err != nil {
 		t.Fatalf("Failed to register test user: %v", err)
 	}
@@ -370,11 +520,11 @@ func TestAuthService_UpdateProfile(t *testing.T) {
 	// First case: update the created user (ID should be 1)
 	t.Run(tests[0].name, func(t *testing.T) {
 		// Get created user
-		user, err := authService.GetProfile(1)
+		user, err := authService.GetProfile(context.Background(), 1)
 		if err != nil {
 			t.Fatalf("Failed to get created user: %v", err)
 		}
-		updated, err := authService.UpdateProfile(user.ID, tests[0].req)
+		updated, err := authService.UpdateProfile(context.Background(), user.ID, tests[0].req)
 		if (err != nil) != tests[0].wantErr {
 			t.Errorf("UpdateProfile() error = %v, wantErr %v", err, tests[0].wantErr)
 			return
@@ -391,9 +541,653 @@ func TestAuthService_UpdateProfile(t *testing.T) {
 
 	// Second case: attempt update on non-existent user ID
 	t.Run(tests[1].name, func(t *testing.T) {
-		_, err := authService.UpdateProfile(9999, tests[1].req)
+		_, err := authService.UpdateProfile(context.Background(), 9999, tests[1].req)
 		if (err != nil) != tests[1].wantErr {
 			t.Errorf("UpdateProfile() error = %v, wantErr %v", err, tests[1].wantErr)
 		}
 	})
 }
+
+func TestAuthService_KeyRotation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	userRepo := postgres.NewUserRepository(db)
+
+	// The old deployment signs with key "v1"
+	oldService := NewAuthServiceWithConfig(userRepo, AuthConfig{
+		JWTSecret:   "test_secret-key",
+		JWTExpiry:   time.Hour,
+		SigningKeys: map[string]string{"v1": "first-secret"},
+		ActiveKeyID: "v1",
+	})
+
+	// The rotated deployment signs with "v2" but still knows the retired "v1"
+	rotatedService := NewAuthServiceWithConfig(userRepo, AuthConfig{
+		JWTSecret:   "test_secret-key",
+		JWTExpiry:   time.Hour,
+		SigningKeys: map[string]string{"v1": "first-secret", "v2": "second-secret"},
+		ActiveKeyID: "v2",
+	})
+
+	// A deployment that has dropped the retired key entirely
+	prunedService := NewAuthServiceWithConfig(userRepo, AuthConfig{
+		JWTSecret:   "test_secret-key",
+		JWTExpiry:   time.Hour,
+		SigningKeys: map[string]string{"v2": "second-secret"},
+		ActiveKeyID: "v2",
+	})
+
+	if _, err := oldService.Register(context.Background(), &RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	loginReq := &LoginRequest{Email: "test@example.com", Password: "password123"}
+	oldLogin, err := oldService.Login(context.Background(), loginReq)
+	if err != nil {
+		t.Fatalf("Failed to login with old service: %v", err)
+	}
+	newLogin, err := rotatedService.Login(context.Background(), loginReq)
+	if err != nil {
+		t.Fatalf("Failed to login with rotated service: %v", err)
+	}
+
+	// New tokens carry the active key ID
+	token, _, err := jwt.NewParser().ParseUnverified(newLogin.Token.AccessToken, &jwtutil.Claims{})
+	if err != nil {
+		t.Fatalf("Failed to parse access token: %v", err)
+	}
+	if kid := token.Header["kid"]; kid != "v2" {
+		t.Errorf("Access token kid = %v, want %q", kid, "v2")
+	}
+
+	tests := []struct {
+		name    string
+		service *AuthService
+		token   string
+		wantErr bool
+	}{
+		{"Active key", rotatedService, newLogin.Token.AccessToken, false},
+		{"Retired key still known", rotatedService, oldLogin.Token.AccessToken, false},
+		{"Retired key removed", prunedService, oldLogin.Token.AccessToken, true},
+		{"Unknown key to old deployment", oldService, newLogin.Token.AccessToken, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.service.ValidateToken(context.Background(), tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func setupTestServiceWithSessions(t *testing.T) *AuthService {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.Session{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	return NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret: "test_secret-key",
+		JWTExpiry: 24 * time.Hour,
+		Sessions:  postgres.NewSessionRepository(db),
+	})
+}
+
+func registerAndLogin(t *testing.T, authService *AuthService, email string) *AuthResponse {
+	if _, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     email,
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	resp, err := authService.Login(context.Background(), &LoginRequest{
+		Email:     email,
+		Password:  "password123",
+		UserAgent: "test-agent",
+		IPAddress: "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("Failed to login test user: %v", err)
+	}
+	return resp
+}
+
+func TestAuthService_RS256(t *testing.T) {
+	_, db := setupTestService(t)
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret:  "test_secret-key",
+		JWTExpiry:  time.Hour,
+		PrivateKey: privateKey,
+	})
+	login := registerAndLogin(t, authService, "test@example.com")
+
+	token, _, err := jwt.NewParser().ParseUnverified(login.Token.AccessToken, &jwtutil.Claims{})
+	if err != nil || token.Method.Alg() != "RS256" {
+		t.Fatalf("Login() access token alg = %v (%v), want RS256", token, err)
+	}
+
+	// The public key alone verifies the issued tokens
+	if _, err := jwt.ParseWithClaims(login.Token.AccessToken, &jwtutil.Claims{}, func(*jwt.Token) (interface{}, error) {
+		return &privateKey.PublicKey, nil
+	}); err != nil {
+		t.Errorf("access token does not verify with the public key: %v", err)
+	}
+	if _, err := authService.ValidateToken(context.Background(), login.Token.AccessToken); err != nil {
+		t.Errorf("ValidateToken() error = %v", err)
+	}
+	if _, err := authService.RefreshToken(context.Background(), login.Token.RefreshToken); err != nil {
+		t.Errorf("RefreshToken() error = %v", err)
+	}
+
+	// An HS256 token signed with the shared secret is rejected once RS256 is configured
+	hmacService := NewAuthService(postgres.NewUserRepository(db), "test_secret-key", time.Hour)
+	hmacToken, err := hmacService.generateAccessToken(&models.User{ID: login.User.ID, Email: login.User.Email, Role: login.User.Role})
+	if err != nil {
+		t.Fatalf("generateAccessToken() error = %v", err)
+	}
+	if _, err := authService.ValidateToken(context.Background(), hmacToken); err == nil {
+		t.Errorf("ValidateToken() accepted an HS256 token with RS256 configured")
+	}
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	authService := setupTestServiceWithSessions(t)
+
+	login := registerAndLogin(t, authService, "test@example.com")
+	other := registerAndLogin(t, authService, "other@example.com")
+
+	sessions, err := authService.ListSessions(context.Background(), login.User.ID)
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("ListSessions() returned %d sessions, want 1", len(sessions))
+	}
+	if sessions[0].UserAgent != "test-agent" || sessions[0].IPAddress != "127.0.0.1" {
+		t.Errorf("ListSessions() got client %q/%q, want test-agent/127.0.0.1", sessions[0].UserAgent, sessions[0].IPAddress)
+	}
+
+	// Refreshing rotates the token within the same session
+	refreshed, err := authService.RefreshToken(context.Background(), login.Token.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	if _, err := authService.RefreshToken(context.Background(), login.Token.RefreshToken); err == nil {
+		t.Errorf("RefreshToken() accepted a rotated refresh token")
+	}
+	if sessions, _ := authService.ListSessions(context.Background(), login.User.ID); len(sessions) != 1 {
+		t.Errorf("ListSessions() after refresh returned %d sessions, want 1", len(sessions))
+	}
+
+	// A user cannot revoke someone else's session
+	otherSessions, err := authService.ListSessions(context.Background(), other.User.ID)
+	if err != nil || len(otherSessions) != 1 {
+		t.Fatalf("ListSessions() for other user = %v, %v", otherSessions, err)
+	}
+	if err := authService.RevokeSession(context.Background(), login.User.ID, otherSessions[0].ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("RevokeSession() on another user's session error = %v, want ErrSessionNotFound", err)
+	}
+
+	// Revoking the session invalidates its refresh token
+	if err := authService.RevokeSession(context.Background(), login.User.ID, sessions[0].ID); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if _, err := authService.RefreshToken(context.Background(), refreshed.RefreshToken); err == nil {
+		t.Errorf("RefreshToken() accepted a token from a revoked session")
+	}
+
+	// The other user's session is untouched
+	if _, err := authService.RefreshToken(context.Background(), other.Token.RefreshToken); err != nil {
+		t.Errorf("RefreshToken() for other user error = %v", err)
+	}
+}
+
+func TestAuthService_Login_RememberMe(t *testing.T) {
+	authService := setupTestServiceWithSessions(t)
+	user := registerAndLogin(t, authService, "test@example.com").User
+
+	tests := []struct {
+		name        string
+		rememberMe  bool
+		wantRefresh time.Duration
+	}{
+		{"Default lifetime", false, defaultRefreshExpiry},
+		{"Remember me", true, defaultRememberMeExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			login, err := authService.Login(context.Background(), &LoginRequest{
+				Email:      "test@example.com",
+				Password:   "password123",
+				RememberMe: tt.rememberMe,
+			})
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+			if got := time.Duration(login.Token.RefreshExpiresIn) * time.Second; got != tt.wantRefresh {
+				t.Errorf("Login() refresh_expires_in = %v, want %v", got, tt.wantRefresh)
+			}
+			if got := time.Duration(login.Token.ExpiresIn) * time.Second; got != 24*time.Hour {
+				t.Errorf("Login() expires_in = %v, want the unchanged access lifetime", got)
+			}
+
+			// Rotation keeps the lifetime chosen at login
+			refreshed, err := authService.RefreshToken(context.Background(), login.Token.RefreshToken)
+			if err != nil {
+				t.Fatalf("RefreshToken() error = %v", err)
+			}
+			if refreshed.RememberMe != tt.rememberMe || time.Duration(refreshed.RefreshExpiresIn)*time.Second != tt.wantRefresh {
+				t.Errorf("RefreshToken() = remember_me %v, refresh_expires_in %ds, want %v, %v", refreshed.RememberMe, refreshed.RefreshExpiresIn, tt.rememberMe, tt.wantRefresh)
+			}
+		})
+	}
+
+	// The choice is recorded on the sessions
+	sessions, err := authService.ListSessions(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	remembered := 0
+	for _, session := range sessions {
+		if session.RememberMe {
+			remembered++
+			if lifetime := time.Until(session.ExpiresAt); lifetime < defaultRefreshExpiry {
+				t.Errorf("remembered session expires in %v, want about %v", lifetime, defaultRememberMeExpiry)
+			}
+		}
+	}
+	if len(sessions) != 3 || remembered != 1 {
+		t.Errorf("ListSessions() = %d sessions with %d remembered, want 3 with 1", len(sessions), remembered)
+	}
+}
+
+func TestAuthService_RevokeAllSessions(t *testing.T) {
+	authService := setupTestServiceWithSessions(t)
+
+	first := registerAndLogin(t, authService, "test@example.com")
+	second, err := authService.Login(context.Background(), &LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Failed to login a second time: %v", err)
+	}
+
+	revoked, err := authService.RevokeAllSessions(context.Background(), first.User.ID)
+	if err != nil {
+		t.Fatalf("RevokeAllSessions() error = %v", err)
+	}
+	if revoked != 2 {
+		t.Errorf("RevokeAllSessions() revoked %d sessions, want 2", revoked)
+	}
+
+	for _, token := range []string{first.Token.RefreshToken, second.Token.RefreshToken} {
+		if _, err := authService.RefreshToken(context.Background(), token); err == nil {
+			t.Errorf("RefreshToken() accepted a token after logging out everywhere")
+		}
+	}
+}
+
+func TestAuthService_PatchProfile(t *testing.T) {
+	authService, _ := setupTestService(t)
+
+	registerResp, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+
+	// Omitted fields are left untouched
+	firstName := "Jane"
+	updated, err := authService.PatchProfile(context.Background(), registerResp.User.ID, &PatchProfileRequest{FirstName: &firstName})
+	if err != nil {
+		t.Fatalf("PatchProfile() error = %v", err)
+	}
+	if updated.FirstName != "Jane" {
+		t.Errorf("PatchProfile() first name = %q, want %q", updated.FirstName, "Jane")
+	}
+	if updated.LastName != "Doe" {
+		t.Errorf("PatchProfile() changed omitted last name to %q", updated.LastName)
+	}
+
+	if _, err := authService.PatchProfile(context.Background(), 9999, &PatchProfileRequest{FirstName: &firstName}); err == nil {
+		t.Errorf("PatchProfile() expected error for non-existent user")
+	}
+}
+
+func TestPatchProfileRequest_Validation(t *testing.T) {
+	empty := ""
+	short := "J"
+	valid := "Jane"
+
+	tests := []struct {
+		name    string
+		req     PatchProfileRequest
+		wantErr bool
+	}{
+		{"All fields omitted", PatchProfileRequest{}, false},
+		{"Valid field", PatchProfileRequest{FirstName: &valid}, false},
+		{"Explicit empty string", PatchProfileRequest{LastName: &empty}, true},
+		{"Too short", PatchProfileRequest{FirstName: &short}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateProfileRequest_RequiresAllFields(t *testing.T) {
+	req := UpdateProfileRequest{FirstName: "Jane"}
+	if err := binding.Validator.ValidateStruct(&req); err == nil {
+		t.Errorf("ValidateStruct() expected error when last name is missing from a full update")
+	}
+}
+
+func TestAuthService_RoleExpiry(t *testing.T) {
+	authService := NewAuthServiceWithConfig(nil, AuthConfig{
+		JWTSecret: "test_secret-key",
+		JWTExpiry: 24 * time.Hour,
+		RoleExpiry: map[string]time.Duration{
+			models.RoleAdmin:  15 * time.Minute,
+			models.RoleEditor: time.Hour,
+		},
+	})
+
+	tests := []struct {
+		role string
+		want time.Duration
+	}{
+		{models.RoleAdmin, 15 * time.Minute},
+		{models.RoleEditor, time.Hour},
+		{models.RoleUser, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			user := &models.User{ID: 1, Email: tt.role + "@example.com", Role: tt.role}
+
+			resp, err := authService.generateTokenResponse(user, "", false)
+			if err != nil {
+				t.Fatalf("generateTokenResponse() error = %v", err)
+			}
+			if resp.ExpiresIn != int64(tt.want.Seconds()) {
+				t.Errorf("generateTokenResponse() ExpiresIn = %d, want %d", resp.ExpiresIn, int64(tt.want.Seconds()))
+			}
+
+			claims := &jwtutil.Claims{}
+			if _, err := jwt.ParseWithClaims(resp.AccessToken, claims, authService.keys().Keyfunc); err != nil {
+				t.Fatalf("Failed to parse access token: %v", err)
+			}
+			if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != tt.want {
+				t.Errorf("access token lifetime = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthService_DeleteAccount(t *testing.T) {
+	authService := setupTestServiceWithSessions(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+
+	err := authService.DeleteAccount(context.Background(), login.User.ID, &DeleteAccountRequest{CurrentPassword: "wrong-password"})
+	if !errors.Is(err, ErrInvalidCurrentPassword) {
+		t.Fatalf("DeleteAccount() error = %v, want %v", err, ErrInvalidCurrentPassword)
+	}
+	if _, err := authService.GetProfile(context.Background(), login.User.ID); err != nil {
+		t.Fatalf("DeleteAccount() with wrong password deleted the account")
+	}
+
+	if err := authService.DeleteAccount(context.Background(), login.User.ID, &DeleteAccountRequest{CurrentPassword: "password123"}); err != nil {
+		t.Fatalf("DeleteAccount() error = %v", err)
+	}
+	if _, err := authService.GetProfile(context.Background(), login.User.ID); err == nil {
+		t.Errorf("DeleteAccount() account still exists")
+	}
+	if _, err := authService.RefreshToken(context.Background(), login.Token.RefreshToken); err == nil {
+		t.Errorf("RefreshToken() after DeleteAccount() succeeded, want the session revoked")
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	authService := setupTestServiceWithSessions(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		wantErr         error
+	}{
+		{"Wrong current password", "wrong-password", "new-password", ErrInvalidCurrentPassword},
+		{"New password too short", "password123", "short", ErrPasswordTooShort},
+		{"Same password", "password123", "password123", ErrPasswordUnchanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := authService.ChangePassword(ctx, login.User.ID, tt.currentPassword, tt.newPassword); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ChangePassword() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Rejected changes keep the password and tokens working
+	if _, err := authService.ValidateToken(ctx, login.Token.AccessToken); err != nil {
+		t.Fatalf("ValidateToken() after rejected changes error = %v", err)
+	}
+
+	if err := authService.ChangePassword(ctx, login.User.ID, "password123", "new-password"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if _, err := authService.Login(ctx, &LoginRequest{Email: "test@example.com", Password: "password123"}); err == nil {
+		t.Error("Login() with the old password succeeded")
+	}
+	if _, err := authService.Login(ctx, &LoginRequest{Email: "test@example.com", Password: "new-password"}); err != nil {
+		t.Errorf("Login() with the new password error = %v", err)
+	}
+
+	// Tokens issued before the change no longer work
+	if _, err := authService.ValidateToken(ctx, login.Token.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateToken() old access token error = %v, want %v", err, ErrTokenRevoked)
+	}
+	if _, err := authService.RefreshToken(ctx, login.Token.RefreshToken); err == nil {
+		t.Error("RefreshToken() old refresh token succeeded, want it revoked")
+	}
+}
+
+func TestAuthService_Login_RehashesPassword(t *testing.T) {
+	defer func(cost int) { models.PasswordCost = cost }(models.PasswordCost)
+	models.PasswordCost = bcrypt.MinCost
+
+	authService, db := setupTestService(t)
+	if _, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	storedCost := func() int {
+		var user models.User
+		db.Where("email = ?", "test@example.com").First(&user)
+		cost, err := bcrypt.Cost([]byte(user.Password))
+		if err != nil {
+			t.Fatalf("stored password is not a bcrypt hash: %v", err)
+		}
+		return cost
+	}
+
+	login := func() {
+		if _, err := authService.Login(context.Background(), &LoginRequest{Email: "test@example.com", Password: "password123"}); err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+	}
+
+	// Logging in at the configured cost leaves the hash alone
+	login()
+	if cost := storedCost(); cost != bcrypt.MinCost {
+		t.Errorf("stored cost after login = %d, want %d", cost, bcrypt.MinCost)
+	}
+
+	// Raising the cost upgrades the hash on the next login, and the password still works
+	models.PasswordCost = bcrypt.MinCost + 1
+	login()
+	if cost := storedCost(); cost != bcrypt.MinCost+1 {
+		t.Errorf("stored cost after raising cost = %d, want %d", cost, bcrypt.MinCost+1)
+	}
+	login()
+}
+
+func TestAuthService_TokenVersion(t *testing.T) {
+	authService, db := setupTestService(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+	ctx := context.Background()
+
+	if version, err := authService.TokenVersion(ctx, login.User.ID); err != nil || version != 0 {
+		t.Fatalf("TokenVersion() = %d, %v, want 0", version, err)
+	}
+
+	if err := postgres.NewUserRepository(db).IncrementTokenVersion(ctx, login.User.ID); err != nil {
+		t.Fatalf("IncrementTokenVersion() error = %v", err)
+	}
+
+	if version, _ := authService.TokenVersion(ctx, login.User.ID); version != 1 {
+		t.Errorf("TokenVersion() = %d, want 1", version)
+	}
+	if _, err := authService.ValidateToken(ctx, login.Token.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateToken() error = %v, want %v", err, ErrTokenRevoked)
+	}
+	if _, err := authService.RefreshToken(ctx, login.Token.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("RefreshToken() error = %v, want %v", err, ErrTokenRevoked)
+	}
+
+	// Tokens issued afterwards carry the new version
+	relogin, err := authService.Login(ctx, &LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if _, err := authService.ValidateToken(ctx, relogin.Token.AccessToken); err != nil {
+		t.Errorf("ValidateToken() new token error = %v", err)
+	}
+
+	if _, err := authService.TokenVersion(ctx, 99); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("TokenVersion() unknown user error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestAuthService_Logout_Blacklist(t *testing.T) {
+	_, db := setupTestService(t)
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret: "test_secret-key",
+		JWTExpiry: time.Hour,
+		Blacklist: memory.NewTokenBlacklist(),
+	})
+	login := registerAndLogin(t, authService, "test@example.com")
+	other := registerAndLogin(t, authService, "other@example.com")
+	ctx := context.Background()
+
+	for name, token := range map[string]string{"access": login.Token.AccessToken, "refresh": login.Token.RefreshToken} {
+		if claims, _ := jwtutil.Parse(token, authService.keys().Keyfunc); claims == nil || claims.ID == "" {
+			t.Errorf("%s token has no jti", name)
+		}
+	}
+
+	if err := authService.Logout(ctx, login.Token.AccessToken, login.Token.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	if _, err := authService.ValidateToken(ctx, login.Token.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateToken() after logout error = %v, want %v", err, ErrTokenRevoked)
+	}
+	if _, err := authService.RefreshToken(ctx, login.Token.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("RefreshToken() after logout error = %v, want %v", err, ErrTokenRevoked)
+	}
+
+	// Other tokens stay valid, and invalid tokens are ignored
+	if _, err := authService.ValidateToken(ctx, other.Token.AccessToken); err != nil {
+		t.Errorf("ValidateToken() for another login error = %v, want nil", err)
+	}
+	if err := authService.Logout(ctx, "not-a-token", ""); err != nil {
+		t.Errorf("Logout() with an invalid token error = %v, want nil", err)
+	}
+}
+
+func TestAuthService_Login_Lockout(t *testing.T) {
+	_, db := setupTestService(t)
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret:       "test_secret-key",
+		JWTExpiry:       time.Hour,
+		LockoutAttempts: 3,
+		LockoutDuration: 15 * time.Minute,
+	})
+	registerAndLogin(t, authService, "test@example.com")
+	ctx := context.Background()
+	wrong := &LoginRequest{Email: "test@example.com", Password: "wrong-password"}
+	right := &LoginRequest{Email: "test@example.com", Password: "password123"}
+
+	// A successful login starts the count over
+	authService.Login(ctx, wrong)
+	authService.Login(ctx, wrong)
+	if _, err := authService.Login(ctx, right); err != nil {
+		t.Fatalf("Login() below the threshold error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := authService.Login(ctx, wrong); err == nil || errors.Is(err, ErrAccountLocked) {
+			t.Fatalf("Login() failure %d error = %v, want invalid credentials", i+1, err)
+		}
+	}
+	if _, err := authService.Login(ctx, right); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("Login() while locked error = %v, want %v", err, ErrAccountLocked)
+	}
+
+	var user models.User
+	db.Where("email = ?", "test@example.com").First(&user)
+	if user.LockedUntil == nil || user.LockedUntil.Before(time.Now().Add(14*time.Minute)) {
+		t.Errorf("LockedUntil = %v, want about 15 minutes from now", user.LockedUntil)
+	}
+
+	// Once the window passes the account unlocks by itself
+	db.Model(&user).UpdateColumn("locked_until", time.Now().Add(-time.Second))
+	if _, err := authService.Login(ctx, right); err != nil {
+		t.Fatalf("Login() after the lock expired error = %v", err)
+	}
+	var unlocked models.User
+	db.First(&unlocked, user.ID)
+	if unlocked.FailedLoginAttempts != 0 || unlocked.LockedUntil != nil {
+		t.Errorf("after login attempts = %d, locked until %v, want the lockout cleared", unlocked.FailedLoginAttempts, unlocked.LockedUntil)
+	}
+}
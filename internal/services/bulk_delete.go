@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultConfirmationExpiry is how long an admin has to confirm a bulk delete
+const defaultConfirmationExpiry = 5 * time.Minute
+
+// ErrInvalidConfirmation is returned when a bulk delete is confirmed with a token that is
+// unknown, expired, already used, or was issued to another admin or for other users.
+var ErrInvalidConfirmation = errors.New("invalid or expired confirmation token")
+
+// Bulk delete outcomes per user
+const (
+	BulkDeleteDeleted  = "deleted"
+	BulkDeleteNotFound = "not_found"
+	BulkDeleteSkipped  = "skipped"
+)
+
+// BulkDeleteUsersRequest deletes several users. Without Confirm nothing is deleted; the
+// response carries the token to send back in Confirm with the same IDs.
+type BulkDeleteUsersRequest struct {
+	IDs     []uint `json:"ids" binding:"required,min=1,max=100,dive,min=1"`
+	Confirm string `json:"confirm"`
+
+	// Client details recorded on the audit log, filled in by the handler
+	IPAddress string `json:"-"`
+}
+
+// BulkDeleteConfirmation carries the token confirming a bulk delete
+type BulkDeleteConfirmation struct {
+	Confirm   string `json:"confirm"`
+	IDs       []uint `json:"ids"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// BulkDeleteResult is the outcome of a bulk delete for one user
+type BulkDeleteResult struct {
+	ID     uint   `json:"id"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// RequestBulkDelete issues a single-use token that lets actorID delete exactly these users
+// within the confirmation expiry.
+func (s *UserService) RequestBulkDelete(ctx context.Context, actorID uint, ids []uint) (*BulkDeleteConfirmation, error) {
+	ids = uniqueIDs(ids)
+
+	token, err := newTokenID()
+	if err != nil {
+		return nil, errors.New("failed to issue confirmation token")
+	}
+	if err := s.confirmations.Set(confirmationKey(token), []byte(confirmationScope(actorID, ids)), s.confirmationExpiry); err != nil {
+		return nil, errors.New("failed to issue confirmation token")
+	}
+
+	return &BulkDeleteConfirmation{
+		Confirm:   token,
+		IDs:       ids,
+		ExpiresIn: int64(s.confirmationExpiry.Seconds()),
+	}, nil
+}
+
+// BulkDeleteUsers soft-deletes the requested users once the confirmation token checks out.
+// The acting admin and the last remaining admin are skipped, and unknown IDs are reported as
+// not found. The deletes and their audit entries are written in one transaction.
+func (s *UserService) BulkDeleteUsers(ctx context.Context, actorID uint, req *BulkDeleteUsersRequest) ([]BulkDeleteResult, error) {
+	ids := uniqueIDs(req.IDs)
+	if err := s.consumeConfirmation(req.Confirm, actorID, ids); err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkDeleteResult, 0, len(ids))
+	var deleteIDs []uint
+	var entries []models.AuditLog
+	for _, id := range ids {
+		if id == actorID {
+			results = append(results, BulkDeleteResult{ID: id, Status: BulkDeleteSkipped, Reason: "admins cannot delete themselves"})
+			continue
+		}
+
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				results = append(results, BulkDeleteResult{ID: id, Status: BulkDeleteNotFound})
+				continue
+			}
+			return nil, errors.New("failed to retrieve user")
+		}
+
+		details, err := json.Marshal(map[string]interface{}{"email": user.Email, "role": user.Role})
+		if err != nil {
+			return nil, errors.New("failed to write audit log")
+		}
+		entries = append(entries, models.AuditLog{
+			ActorID:    actorID,
+			Action:     models.AuditActionUserDeleted,
+			TargetType: "user",
+			TargetID:   id,
+			Details:    string(details),
+			IPAddress:  req.IPAddress,
+		})
+		deleteIDs = append(deleteIDs, id)
+		results = append(results, BulkDeleteResult{ID: id, Status: BulkDeleteDeleted})
+	}
+
+	if len(deleteIDs) == 0 {
+		return results, nil
+	}
+
+	// The last-admin guard runs in the delete transaction, with the admin rows locked
+	kept, err := s.userRepo.DeleteMany(ctx, deleteIDs, entries)
+	if err != nil {
+		return nil, errors.New("failed to delete users")
+	}
+	for i, result := range results {
+		if slices.Contains(kept, result.ID) {
+			results[i] = BulkDeleteResult{ID: result.ID, Status: BulkDeleteSkipped, Reason: "the last admin cannot be deleted"}
+		}
+	}
+
+	return results, nil
+}
+
+// consumeConfirmation checks that token was issued to actorID for exactly ids and invalidates it
+func (s *UserService) consumeConfirmation(token string, actorID uint, ids []uint) error {
+	if token == "" {
+		return ErrInvalidConfirmation
+	}
+
+	key := confirmationKey(token)
+	scope, err := s.confirmations.Get(key)
+	if err != nil || string(scope) != confirmationScope(actorID, ids) {
+		return ErrInvalidConfirmation
+	}
+	if err := s.confirmations.Delete(key); err != nil {
+		return errors.New("failed to consume confirmation token")
+	}
+	return nil
+}
+
+// confirmationKey is the store key of a bulk delete confirmation token
+func confirmationKey(token string) string {
+	return "bulk-delete:" + token
+}
+
+// confirmationScope describes who may confirm a bulk delete and for which users
+func confirmationScope(actorID uint, ids []uint) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strconv.FormatUint(uint64(actorID), 10) + ":" + strings.Join(parts, ",")
+}
+
+// uniqueIDs returns the IDs sorted and without duplicates
+func uniqueIDs(ids []uint) []uint {
+	sorted := append([]uint(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	unique := sorted[:0]
+	for i, id := range sorted {
+		if i == 0 || id != sorted[i-1] {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUserService_BulkDeleteUsers(t *testing.T) {
+	userService, db := setupUserService(t)
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	otherAdmin := createTestUser(t, db, "other-admin@example.com", models.RoleAdmin)
+	user := createTestUser(t, db, "user@example.com", models.RoleUser)
+	editor := createTestUser(t, db, "editor@example.com", models.RoleEditor)
+	ctx := context.Background()
+
+	ids := []uint{editor.ID, user.ID, admin.ID, otherAdmin.ID, 99, user.ID}
+	confirmation, err := userService.RequestBulkDelete(ctx, admin.ID, ids)
+	if err != nil {
+		t.Fatalf("RequestBulkDelete() error = %v", err)
+	}
+	wantIDs := []uint{admin.ID, otherAdmin.ID, user.ID, editor.ID, 99}
+	if !reflect.DeepEqual(confirmation.IDs, wantIDs) || confirmation.ExpiresIn != int64(defaultConfirmationExpiry.Seconds()) {
+		t.Errorf("RequestBulkDelete() = %+v, want sorted unique ids expiring in %v", confirmation, defaultConfirmationExpiry)
+	}
+
+	// Requesting alone deletes nothing
+	var count int64
+	db.Model(&models.User{}).Count(&count)
+	if count != 4 {
+		t.Fatalf("RequestBulkDelete() left %d users, want 4", count)
+	}
+
+	results, err := userService.BulkDeleteUsers(ctx, admin.ID, &BulkDeleteUsersRequest{IDs: ids, Confirm: confirmation.Confirm, IPAddress: "203.0.113.7"})
+	if err != nil {
+		t.Fatalf("BulkDeleteUsers() error = %v", err)
+	}
+
+	// The acting admin is skipped, which keeps an admin around, so the other admin can go
+	wantResults := []BulkDeleteResult{
+		{ID: admin.ID, Status: BulkDeleteSkipped, Reason: "admins cannot delete themselves"},
+		{ID: otherAdmin.ID, Status: BulkDeleteDeleted},
+		{ID: user.ID, Status: BulkDeleteDeleted},
+		{ID: editor.ID, Status: BulkDeleteDeleted},
+		{ID: 99, Status: BulkDeleteNotFound},
+	}
+	if !reflect.DeepEqual(results, wantResults) {
+		t.Errorf("BulkDeleteUsers() = %+v, want %+v", results, wantResults)
+	}
+
+	var remaining []models.User
+	db.Find(&remaining)
+	if len(remaining) != 1 || remaining[0].ID != admin.ID {
+		t.Errorf("BulkDeleteUsers() remaining users = %+v, want only the acting admin", remaining)
+	}
+	db.Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL").Count(&count)
+	if count != 3 {
+		t.Errorf("BulkDeleteUsers() soft-deleted %d users, want 3", count)
+	}
+
+	var entries []models.AuditLog
+	db.Where("action = ?", models.AuditActionUserDeleted).Order("target_id").Find(&entries)
+	if len(entries) != 3 || entries[0].ActorID != admin.ID || entries[0].IPAddress != "203.0.113.7" {
+		t.Errorf("BulkDeleteUsers() audit entries = %+v, want 3 by the acting admin", entries)
+	}
+
+	// Tokens are single use
+	_, err = userService.BulkDeleteUsers(ctx, admin.ID, &BulkDeleteUsersRequest{IDs: ids, Confirm: confirmation.Confirm})
+	if !errors.Is(err, ErrInvalidConfirmation) {
+		t.Errorf("BulkDeleteUsers() reused token error = %v, want %v", err, ErrInvalidConfirmation)
+	}
+}
+
+func TestUserService_BulkDeleteUsers_LastAdmin(t *testing.T) {
+	userService, db := setupUserService(t)
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	ctx := context.Background()
+
+	// An editor acting on a database with a single admin must not remove it
+	editor := createTestUser(t, db, "editor@example.com", models.RoleEditor)
+	confirmation, _ := userService.RequestBulkDelete(ctx, editor.ID, []uint{admin.ID})
+	results, err := userService.BulkDeleteUsers(ctx, editor.ID, &BulkDeleteUsersRequest{IDs: []uint{admin.ID}, Confirm: confirmation.Confirm})
+	if err != nil {
+		t.Fatalf("BulkDeleteUsers() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Status != BulkDeleteSkipped {
+		t.Errorf("BulkDeleteUsers() = %+v, want the last admin skipped", results)
+	}
+}
+
+func TestUserService_BulkDeleteUsers_Confirmation(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		actorID uint
+		ids     []uint
+		confirm func(token string) string
+	}{
+		{"Missing token", 1, []uint{2, 3}, func(string) string { return "" }},
+		{"Unknown token", 1, []uint{2, 3}, func(string) string { return "not-a-token" }},
+		{"Other admin", 4, []uint{2, 3}, func(token string) string { return token }},
+		{"Other users", 1, []uint{2}, func(token string) string { return token }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService, db := setupUserService(t)
+			for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+				createTestUser(t, db, email, models.RoleAdmin)
+			}
+
+			confirmation, err := userService.RequestBulkDelete(ctx, 1, []uint{3, 2})
+			if err != nil {
+				t.Fatalf("RequestBulkDelete() error = %v", err)
+			}
+
+			_, err = userService.BulkDeleteUsers(ctx, tt.actorID, &BulkDeleteUsersRequest{IDs: tt.ids, Confirm: tt.confirm(confirmation.Confirm)})
+			if !errors.Is(err, ErrInvalidConfirmation) {
+				t.Errorf("BulkDeleteUsers() error = %v, want %v", err, ErrInvalidConfirmation)
+			}
+
+			var count int64
+			db.Model(&models.User{}).Count(&count)
+			if count != 4 {
+				t.Errorf("BulkDeleteUsers() left %d users, want all 4", count)
+			}
+		})
+	}
+}
+
+func TestUserService_BulkDeleteUsers_ExpiredToken(t *testing.T) {
+	userService, db := setupUserService(t)
+	userService.confirmationExpiry = 10 * time.Millisecond
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	user := createTestUser(t, db, "user@example.com", models.RoleUser)
+	ctx := context.Background()
+
+	confirmation, _ := userService.RequestBulkDelete(ctx, admin.ID, []uint{user.ID})
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := userService.BulkDeleteUsers(ctx, admin.ID, &BulkDeleteUsersRequest{IDs: []uint{user.ID}, Confirm: confirmation.Confirm})
+	if !errors.Is(err, ErrInvalidConfirmation) {
+		t.Errorf("BulkDeleteUsers() expired token error = %v, want %v", err, ErrInvalidConfirmation)
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CaptchaVerifier checks a CAPTCHA token solved by the client. NewCaptchaVerifier returns one
+// backed by a provider's siteverify API; tests inject fakes.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// Supported CAPTCHA providers
+const (
+	CaptchaProviderRecaptcha = "recaptcha"
+	CaptchaProviderHCaptcha  = "hcaptcha"
+	CaptchaProviderTurnstile = "turnstile"
+)
+
+// captchaVerifyURLs are the siteverify endpoints of the supported providers
+var captchaVerifyURLs = map[string]string{
+	CaptchaProviderRecaptcha: "https://www.google.com/recaptcha/api/siteverify",
+	CaptchaProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	CaptchaProviderTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// defaultCaptchaTimeout bounds a siteverify call so a slow provider cannot stall registration.
+const defaultCaptchaTimeout = 5 * time.Second
+
+// ErrCaptchaRequired is returned when CAPTCHA verification is enabled and no token was sent.
+var ErrCaptchaRequired = &FieldError{
+	Field:   "captcha_token",
+	Code:    utils.CodeCaptchaRequired,
+	Message: "captcha token is required",
+}
+
+// ErrCaptchaInvalid is returned when the provider rejects the CAPTCHA token.
+var ErrCaptchaInvalid = &FieldError{
+	Field:   "captcha_token",
+	Code:    utils.CodeCaptchaInvalid,
+	Message: "captcha verification failed",
+}
+
+// siteVerifier verifies tokens with a provider's siteverify API. reCAPTCHA, hCaptcha and
+// Turnstile share the same form-encoded request and {"success": bool} response.
+type siteVerifier struct {
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+// NewCaptchaVerifier creates a verifier for the provider (recaptcha, hcaptcha or turnstile)
+// authenticating with its secret key.
+func NewCaptchaVerifier(provider, secret string) (CaptchaVerifier, error) {
+	verifyURL, ok := captchaVerifyURLs[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unknown captcha provider %q", provider)
+	}
+	if secret == "" {
+		return nil, errors.New("captcha secret is required")
+	}
+
+	return &siteVerifier{
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    &http.Client{Timeout: defaultCaptchaTimeout},
+	}, nil
+}
+
+// Verify asks the provider whether the token is valid. An error means the provider could not
+// be reached or answered unexpectedly, not that the token was rejected.
+func (v *siteVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha provider returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
+
+// checkCaptcha verifies the request's CAPTCHA token when a verifier is configured. A provider
+// that cannot be reached fails the request rather than letting it through.
+func (s *AuthService) checkCaptcha(ctx context.Context, token, remoteIP string) error {
+	if s.captcha == nil {
+		return nil
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrCaptchaRequired
+	}
+
+	ok, err := s.captcha.Verify(ctx, token, remoteIP)
+	if err != nil {
+		return errors.New("failed to verify captcha")
+	}
+	if !ok {
+		return ErrCaptchaInvalid
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubCaptcha accepts only the token "valid" and records the client IP it was given
+type stubCaptcha struct {
+	err      error
+	remoteIP string
+}
+
+func (c *stubCaptcha) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	c.remoteIP = remoteIP
+	if c.err != nil {
+		return false, c.err
+	}
+	return token == "valid", nil
+}
+
+func TestAuthService_Register_Captcha(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		verifyErr  error
+		wantErr    error
+		wantFailed bool
+	}{
+		{"Valid token", "valid", nil, nil, false},
+		{"Missing token", "", nil, ErrCaptchaRequired, true},
+		{"Blank token", "   ", nil, ErrCaptchaRequired, true},
+		{"Rejected token", "bot", nil, ErrCaptchaInvalid, true},
+		{"Provider unavailable", "valid", errors.New("connection refused"), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db := setupTestService(t)
+			captcha := &stubCaptcha{err: tt.verifyErr}
+			authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+				JWTSecret: "test_secret-key",
+				JWTExpiry: 24 * time.Hour,
+				Captcha:   captcha,
+			})
+
+			_, err := authService.Register(context.Background(), &RegisterRequest{
+				Email:        "user@example.com",
+				Password:     "password123",
+				FirstName:    "John",
+				LastName:     "Doe",
+				CaptchaToken: tt.token,
+				IPAddress:    "203.0.113.7",
+			})
+			if (err != nil) != tt.wantFailed {
+				t.Fatalf("Register() error = %v, wantFailed %v", err, tt.wantFailed)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.token == "valid" && captcha.remoteIP != "203.0.113.7" {
+				t.Errorf("Verify() remoteIP = %q, want %q", captcha.remoteIP, "203.0.113.7")
+			}
+
+			var count int64
+			db.Table("users").Count(&count)
+			if tt.wantFailed && count != 0 {
+				t.Errorf("Register() stored %d users, want none after a failed captcha", count)
+			}
+		})
+	}
+}
+
+func TestAuthService_Register_NoCaptchaByDefault(t *testing.T) {
+	authService, _ := setupTestService(t)
+
+	if _, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "user@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Errorf("Register() error = %v, want nil without a captcha verifier", err)
+	}
+}
+
+func TestSiteVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    bool
+		wantErr bool
+	}{
+		{"Accepted", http.StatusOK, `{"success":true}`, true, false},
+		{"Rejected", http.StatusOK, `{"success":false,"error-codes":["invalid-input-response"]}`, false, false},
+		{"Provider error", http.StatusInternalServerError, ``, false, true},
+		{"Malformed response", http.StatusOK, `not json`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.PostFormValue("secret") != "test-secret" || r.PostFormValue("response") != "token" || r.PostFormValue("remoteip") != "203.0.113.7" {
+					t.Errorf("siteverify form = %v, want secret, response and remoteip", r.PostForm)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			verifier, err := NewCaptchaVerifier(CaptchaProviderTurnstile, "test-secret")
+			if err != nil {
+				t.Fatalf("NewCaptchaVerifier() error = %v", err)
+			}
+			verifier.(*siteVerifier).verifyURL = server.URL
+
+			got, err := verifier.Verify(context.Background(), "token", "203.0.113.7")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCaptchaVerifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		secret   string
+		wantErr  bool
+	}{
+		{"reCAPTCHA", "recaptcha", "secret", false},
+		{"hCaptcha", "hcaptcha", "secret", false},
+		{"Turnstile, mixed case", "Turnstile", "secret", false},
+		{"Unknown provider", "captchaco", "secret", true},
+		{"Missing secret", "recaptcha", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCaptchaVerifier(tt.provider, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCaptchaVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
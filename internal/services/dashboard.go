@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/interfaces"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Dashboard defaults
+const (
+	// defaultDashboardCacheTTL is how long a computed dashboard is served before it is recomputed
+	defaultDashboardCacheTTL = 30 * time.Second
+	// dashboardRecentAuditEntries is how many audit log entries the dashboard lists
+	dashboardRecentAuditEntries = 10
+)
+
+// Dashboard is the admin landing page summary
+type Dashboard struct {
+	Users              *models.UserStats `json:"users"`
+	RecentAuditEntries []models.AuditLog `json:"recent_audit_entries"`
+	GeneratedAt        time.Time         `json:"generated_at"`
+}
+
+// DashboardService aggregates the admin dashboard, caching it briefly so that
+// reloading the page does not rerun the aggregate queries.
+type DashboardService struct {
+	userRepo  interfaces.UserRepository
+	auditRepo interfaces.AuditLogRepository
+	cacheTTL  time.Duration
+
+	mu     sync.Mutex
+	cached *Dashboard
+}
+
+// NewDashboardService creates a new instance of DashboardService.
+func NewDashboardService(userRepo interfaces.UserRepository, auditRepo interfaces.AuditLogRepository) *DashboardService {
+	return &DashboardService{
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		cacheTTL:  defaultDashboardCacheTTL,
+	}
+}
+
+// GetDashboard returns the dashboard, recomputing it once the cached copy is older than the cache TTL.
+func (s *DashboardService) GetDashboard(ctx context.Context) (*Dashboard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if s.cached != nil && now.Sub(s.cached.GeneratedAt) < s.cacheTTL {
+		return s.cached, nil
+	}
+
+	users, err := s.userRepo.Stats(ctx, now.Add(-7*24*time.Hour))
+	if err != nil {
+		return nil, errors.New("failed to count users")
+	}
+
+	entries, err := s.auditRepo.ListRecent(ctx, dashboardRecentAuditEntries)
+	if err != nil {
+		return nil, errors.New("failed to retrieve audit log")
+	}
+	for i := range entries {
+		entries[i].CreatedAt = entries[i].CreatedAt.UTC()
+	}
+
+	s.cached = &Dashboard{
+		Users:              users,
+		RecentAuditEntries: entries,
+		GeneratedAt:        now,
+	}
+	return s.cached, nil
+}
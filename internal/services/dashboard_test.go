@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"testing"
+	"time"
+)
+
+func TestDashboardService_GetDashboard(t *testing.T) {
+	_, db := setupUserService(t)
+	dashboardService := NewDashboardService(postgres.NewUserRepository(db), postgres.NewAuditLogRepository(db))
+
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	createTestUser(t, db, "editor@example.com", models.RoleEditor)
+	createTestUser(t, db, "user@example.com", models.RoleUser)
+	inactive := createTestUser(t, db, "inactive@example.com", models.RoleUser)
+	db.Model(inactive).Update("is_active", false)
+	old := createTestUser(t, db, "old@example.com", models.RoleUser)
+	db.Model(old).Update("created_at", time.Now().Add(-30*24*time.Hour))
+
+	for i := 0; i < dashboardRecentAuditEntries+2; i++ {
+		db.Create(&models.AuditLog{ActorID: admin.ID, Action: models.AuditActionUserUpdated, TargetType: "user", TargetID: old.ID})
+	}
+
+	dashboard, err := dashboardService.GetDashboard(context.Background())
+	if err != nil {
+		t.Fatalf("GetDashboard() error = %v", err)
+	}
+
+	users := dashboard.Users
+	if users.Total != 5 || users.Active != 4 || users.NewThisWeek != 4 {
+		t.Errorf("GetDashboard() users = %+v, want 5 total, 4 active, 4 new this week", users)
+	}
+	wantRoles := map[string]int64{models.RoleAdmin: 1, models.RoleEditor: 1, models.RoleUser: 3}
+	for role, want := range wantRoles {
+		if users.ByRole[role] != want {
+			t.Errorf("GetDashboard() by_role[%s] = %d, want %d", role, users.ByRole[role], want)
+		}
+	}
+	if len(dashboard.RecentAuditEntries) != dashboardRecentAuditEntries {
+		t.Errorf("GetDashboard() recent audit entries = %d, want %d", len(dashboard.RecentAuditEntries), dashboardRecentAuditEntries)
+	}
+
+	// The dashboard is served from the cache until it expires
+	createTestUser(t, db, "new@example.com", models.RoleUser)
+	if cached, _ := dashboardService.GetDashboard(context.Background()); cached.Users.Total != 5 {
+		t.Errorf("GetDashboard() cached total = %d, want 5", cached.Users.Total)
+	}
+
+	dashboardService.cacheTTL = 0
+	if fresh, _ := dashboardService.GetDashboard(context.Background()); fresh.Users.Total != 6 {
+		t.Errorf("GetDashboard() after cache expiry total = %d, want 6", fresh.Users.Total)
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MXResolver looks up the mail servers of a domain. *net.Resolver satisfies it; tests inject fakes.
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// defaultMXTimeout bounds an MX lookup so a slow DNS server cannot stall registration.
+const defaultMXTimeout = 2 * time.Second
+
+// ErrUndeliverableEmail is returned when the email domain has no mail servers.
+var ErrUndeliverableEmail = &FieldError{
+	Field:   "email",
+	Code:    utils.CodeUndeliverableEmail,
+	Message: "email domain does not accept mail",
+}
+
+// ErrBlockedEmailDomain is returned when the email domain is on the blocklist, such as a
+// disposable email provider.
+var ErrBlockedEmailDomain = &FieldError{
+	Field:   "email",
+	Code:    utils.CodeBlockedEmailDomain,
+	Message: "email domain is not allowed",
+}
+
+// ErrReservedEmail is returned when the email's local part is reserved, such as postmaster@,
+// or the email matches a reserved pattern.
+var ErrReservedEmail = &FieldError{
+	Field:   "email",
+	Code:    utils.CodeReservedEmail,
+	Message: "email address is reserved",
+}
+
+// reservedEmails matches emails that the public may not register
+type reservedEmails struct {
+	localParts map[string]bool
+	patterns   []*regexp.Regexp
+}
+
+// newReservedEmails builds the reserved list from local parts, ignoring blank ones, and patterns
+func newReservedEmails(localParts []string, patterns []*regexp.Regexp) reservedEmails {
+	reserved := reservedEmails{localParts: make(map[string]bool), patterns: patterns}
+	for _, localPart := range localParts {
+		if localPart = strings.ToLower(strings.TrimSpace(localPart)); localPart != "" {
+			reserved.localParts[localPart] = true
+		}
+	}
+	return reserved
+}
+
+// reserves reports whether the normalized email is reserved. A +tag on the local part is
+// ignored, so postmaster+x@example.com is as reserved as postmaster@example.com.
+func (r reservedEmails) reserves(email string) bool {
+	localPart := email[:strings.LastIndex(email, "@")]
+	localPart, _, _ = strings.Cut(localPart, "+")
+	if r.localParts[localPart] {
+		return true
+	}
+	for _, pattern := range r.patterns {
+		if pattern.MatchString(email) {
+			return true
+		}
+	}
+	return false
+}
+
+// domainBlocklist matches email domains case-insensitively. Entries of the form
+// "*.example.com" block every subdomain of example.com; list "example.com" as well to
+// block the domain itself.
+type domainBlocklist struct {
+	domains  map[string]bool
+	suffixes []string
+}
+
+// newDomainBlocklist builds a blocklist from domain entries, ignoring blank ones
+func newDomainBlocklist(entries []string) domainBlocklist {
+	blocklist := domainBlocklist{domains: make(map[string]bool)}
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if suffix, ok := strings.CutPrefix(entry, "*."); ok && suffix != "" {
+			blocklist.suffixes = append(blocklist.suffixes, "."+suffix)
+		} else if entry != "" {
+			blocklist.domains[entry] = true
+		}
+	}
+	return blocklist
+}
+
+// blocks reports whether the domain is on the blocklist
+func (b domainBlocklist) blocks(domain string) bool {
+	domain = strings.ToLower(domain)
+	if b.domains[domain] {
+		return true
+	}
+	for _, suffix := range b.suffixes {
+		if strings.HasSuffix(domain, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// emailDomain returns the part of the email after the last @
+func emailDomain(email string) string {
+	return email[strings.LastIndex(email, "@")+1:]
+}
+
+// checkBlockedDomain rejects emails whose domain is on the blocklist
+func (s *AuthService) checkBlockedDomain(email string) error {
+	if s.blockedDomains.blocks(emailDomain(email)) {
+		return ErrBlockedEmailDomain
+	}
+	return nil
+}
+
+// checkReservedEmail rejects emails on the reserved list
+func (s *AuthService) checkReservedEmail(email string) error {
+	if s.reservedEmails.reserves(email) {
+		return ErrReservedEmail
+	}
+	return nil
+}
+
+// checkMX rejects emails whose domain publishes no mail servers. Lookup failures other than
+// a missing domain are treated as transient and allowed, unless strict mode is on.
+func (s *AuthService) checkMX(ctx context.Context, email string) error {
+	if s.mxResolver == nil {
+		return nil
+	}
+
+	domain := emailDomain(email)
+
+	ctx, cancel := context.WithTimeout(ctx, s.mxTimeout)
+	defer cancel()
+
+	records, err := s.mxResolver.LookupMX(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if (errors.As(err, &dnsErr) && dnsErr.IsNotFound) || s.mxStrict {
+			return ErrUndeliverableEmail
+		}
+		return nil
+	}
+
+	// A single "." record is a null MX (RFC 7505): the domain explicitly accepts no mail
+	if len(records) == 0 || (len(records) == 1 && strings.TrimSuffix(records[0].Host, ".") == "") {
+		return ErrUndeliverableEmail
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/jwtutil"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// defaultEmailChangeExpiry is how long the link confirming a new email stays valid.
+const defaultEmailChangeExpiry = 24 * time.Hour
+
+// ErrInvalidEmailChangeToken is returned for confirmation links that are expired, forged,
+// already used, or replaced by a newer change request.
+var ErrInvalidEmailChangeToken = errors.New("invalid or expired email confirmation token")
+
+// ErrEmailUnchanged is returned when the requested email is the user's current email.
+var ErrEmailUnchanged = &FieldError{
+	Field:   "email",
+	Code:    utils.CodeEmailUnchanged,
+	Message: "email is already the current email",
+}
+
+// EmailChangeResponse acknowledges a change request; the change applies once the link sent
+// to PendingEmail is opened
+type EmailChangeResponse struct {
+	PendingEmail string `json:"pending_email"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// RequestEmailChange stores the new email as pending and sends a confirmation link to it.
+// The user keeps logging in with their current email until the link is opened; a new request
+// replaces the pending email and invalidates earlier links.
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID uint, req *ChangeEmailRequest) (*EmailChangeResponse, error) {
+	if s.mailer == nil {
+		return nil, errors.New("email delivery is not configured")
+	}
+
+	user, err := s.reauthenticate(ctx, userID, req.CurrentPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	req.normalize()
+	email := req.Email
+	if email == user.Email {
+		return nil, ErrEmailUnchanged
+	}
+	if existingUser, _ := s.userRepo.GetByEmail(ctx, email); existingUser != nil {
+		return nil, ErrEmailTaken
+	}
+	if err := s.checkReservedEmail(email); err != nil {
+		return nil, err
+	}
+	if err := s.checkBlockedDomain(email); err != nil {
+		return nil, err
+	}
+
+	token, err := s.issueEmailChangeToken(user.ID, email)
+	if err != nil {
+		return nil, errors.New("failed to generate confirmation token")
+	}
+
+	user.PendingEmail = email
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, errors.New("failed to request email change")
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\nOpen this link to confirm %s as the new email of your account:\n\n%s\n\n"+
+		"The link expires in %s. If you did not ask for this change, ignore this email.\n",
+		user.FirstName, email, tokenLink(s.emailChangeURL, token), s.emailChangeExpiry)
+	if err := s.mailer.Send(ctx, email, "Confirm your new email address", body); err != nil {
+		return nil, errors.New("failed to send confirmation email")
+	}
+
+	return &EmailChangeResponse{
+		PendingEmail: email,
+		ExpiresIn:    int64(s.emailChangeExpiry.Seconds()),
+	}, nil
+}
+
+// ConfirmEmailChange makes the pending email the user's email. When another account has
+// taken the email since the request, the pending email is dropped and ErrEmailTaken returned.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, token string) (*models.UserResponse, error) {
+	claims, err := jwtutil.Parse(token, s.keys().Keyfunc)
+	if err != nil || claims.Subject != jwtutil.SubjectEmailChange {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || user == nil {
+		return nil, ErrInvalidEmailChangeToken
+	}
+	// Only the link for the latest request is valid, and only until it is used
+	if user.PendingEmail == "" || user.PendingEmail != claims.Email {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	if existingUser, _ := s.userRepo.GetByEmail(ctx, user.PendingEmail); existingUser != nil && existingUser.ID != user.ID {
+		user.PendingEmail = ""
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, errors.New("failed to change email")
+		}
+		return nil, ErrEmailTaken
+	}
+
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, errors.New("failed to change email")
+	}
+
+	return user.ToResponse(), nil
+}
+
+// issueEmailChangeToken signs a token confirming email as the new email of the user. It
+// carries its own subject, so it cannot be used to authenticate.
+func (s *AuthService) issueEmailChangeToken(userID uint, email string) (string, error) {
+	now := time.Now()
+	claims := &jwtutil.Claims{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.emailChangeExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   jwtutil.SubjectEmailChange,
+			Issuer:    "customable-corporate-site-api",
+		},
+	}
+	return s.signToken(claims)
+}
+
+// tokenLink adds the token to a link's URL, keeping any query it already has
+func tokenLink(rawURL, token string) string {
+	link, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// sentMail is a message captured by fakeMailer
+type sentMail struct {
+	to, subject, body string
+}
+
+// fakeMailer records messages instead of sending them, failing with err when set
+type fakeMailer struct {
+	sent []sentMail
+	err  error
+}
+
+func (m *fakeMailer) Send(ctx context.Context, to, subject, body string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, sentMail{to: to, subject: subject, body: body})
+	return nil
+}
+
+// confirmLink matches the confirmation link in an email change message
+var confirmLink = regexp.MustCompile(`https://\S+`)
+
+// lastToken returns the token of the confirmation link in the latest message
+func (m *fakeMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("no email was sent")
+	}
+	link, err := url.Parse(confirmLink.FindString(m.sent[len(m.sent)-1].body))
+	if err != nil || link.Query().Get("token") == "" {
+		t.Fatalf("email body %q has no confirmation link", m.sent[len(m.sent)-1].body)
+	}
+	return link.Query().Get("token")
+}
+
+func setupEmailChangeService(t *testing.T) (*AuthService, *fakeMailer) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	mailer := &fakeMailer{}
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret:           "test_secret-key",
+		JWTExpiry:           time.Hour,
+		Mailer:              mailer,
+		EmailChangeURL:      "https://example.com/confirm-email?lang=en",
+		BlockedEmailDomains: []string{"mailinator.com"},
+	})
+	return authService, mailer
+}
+
+func TestAuthService_EmailChange(t *testing.T) {
+	authService, mailer := setupEmailChangeService(t)
+	login := registerAndLogin(t, authService, "old@example.com")
+	ctx := context.Background()
+
+	resp, err := authService.RequestEmailChange(ctx, login.User.ID, &ChangeEmailRequest{Email: " New@Example.com ", CurrentPassword: "password123"})
+	if err != nil {
+		t.Fatalf("RequestEmailChange() error = %v", err)
+	}
+	if resp.PendingEmail != "new@example.com" || resp.ExpiresIn != int64(defaultEmailChangeExpiry.Seconds()) {
+		t.Errorf("RequestEmailChange() = %+v, want new@example.com expiring in %v", resp, defaultEmailChangeExpiry)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].to != "new@example.com" || !strings.Contains(mailer.sent[0].body, "lang=en") {
+		t.Fatalf("sent = %+v, want one link to new@example.com keeping the URL's query", mailer.sent)
+	}
+
+	// Until the link is opened, only the old email logs in
+	if _, err := authService.Login(ctx, &LoginRequest{Email: "old@example.com", Password: "password123"}); err != nil {
+		t.Errorf("Login() with the old email error = %v, want nil before confirmation", err)
+	}
+	if _, err := authService.Login(ctx, &LoginRequest{Email: "new@example.com", Password: "password123"}); err == nil {
+		t.Error("Login() with the pending email succeeded before confirmation")
+	}
+	profile, _ := authService.GetProfile(ctx, login.User.ID)
+	if profile.Email != "old@example.com" || profile.PendingEmail != "new@example.com" {
+		t.Errorf("profile = %+v, want old email with new@example.com pending", profile)
+	}
+
+	token := mailer.lastToken(t)
+	user, err := authService.ConfirmEmailChange(ctx, token)
+	if err != nil {
+		t.Fatalf("ConfirmEmailChange() error = %v", err)
+	}
+	if user.Email != "new@example.com" || user.PendingEmail != "" {
+		t.Errorf("ConfirmEmailChange() = %+v, want new@example.com with nothing pending", user)
+	}
+	if _, err := authService.Login(ctx, &LoginRequest{Email: "new@example.com", Password: "password123"}); err != nil {
+		t.Errorf("Login() with the new email error = %v, want nil after confirmation", err)
+	}
+
+	// Links are single use
+	if _, err := authService.ConfirmEmailChange(ctx, token); !errors.Is(err, ErrInvalidEmailChangeToken) {
+		t.Errorf("ConfirmEmailChange() reused link error = %v, want %v", err, ErrInvalidEmailChangeToken)
+	}
+}
+
+func TestAuthService_RequestEmailChange_Rejected(t *testing.T) {
+	authService, mailer := setupEmailChangeService(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+	registerAndLogin(t, authService, "taken@example.com")
+
+	tests := []struct {
+		name    string
+		req     *ChangeEmailRequest
+		wantErr error
+	}{
+		{"Wrong password", &ChangeEmailRequest{Email: "new@example.com", CurrentPassword: "wrong-password"}, ErrInvalidCurrentPassword},
+		{"Email taken", &ChangeEmailRequest{Email: "Taken@example.com", CurrentPassword: "password123"}, ErrEmailTaken},
+		{"Same email", &ChangeEmailRequest{Email: "test@example.com", CurrentPassword: "password123"}, ErrEmailUnchanged},
+		{"Blocked domain", &ChangeEmailRequest{Email: "new@mailinator.com", CurrentPassword: "password123"}, ErrBlockedEmailDomain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authService.RequestEmailChange(context.Background(), login.User.ID, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequestEmailChange() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if len(mailer.sent) != 0 {
+		t.Errorf("sent = %+v, want no email for rejected requests", mailer.sent)
+	}
+	profile, _ := authService.GetProfile(context.Background(), login.User.ID)
+	if profile.PendingEmail != "" {
+		t.Errorf("pending email = %q, want none after rejected requests", profile.PendingEmail)
+	}
+}
+
+func TestAuthService_ConfirmEmailChange_EmailTakenMeanwhile(t *testing.T) {
+	authService, mailer := setupEmailChangeService(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+	ctx := context.Background()
+
+	if _, err := authService.RequestEmailChange(ctx, login.User.ID, &ChangeEmailRequest{Email: "new@example.com", CurrentPassword: "password123"}); err != nil {
+		t.Fatalf("RequestEmailChange() error = %v", err)
+	}
+
+	// Someone registers the address before the link is opened
+	registerAndLogin(t, authService, "new@example.com")
+
+	if _, err := authService.ConfirmEmailChange(ctx, mailer.lastToken(t)); !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("ConfirmEmailChange() error = %v, want %v", err, ErrEmailTaken)
+	}
+	profile, _ := authService.GetProfile(ctx, login.User.ID)
+	if profile.Email != "test@example.com" || profile.PendingEmail != "" {
+		t.Errorf("profile = %+v, want the old email kept and the pending email dropped", profile)
+	}
+}
+
+func TestAuthService_ConfirmEmailChange_InvalidTokens(t *testing.T) {
+	authService, mailer := setupEmailChangeService(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+	ctx := context.Background()
+
+	authService.RequestEmailChange(ctx, login.User.ID, &ChangeEmailRequest{Email: "first@example.com", CurrentPassword: "password123"})
+	superseded := mailer.lastToken(t)
+	authService.RequestEmailChange(ctx, login.User.ID, &ChangeEmailRequest{Email: "second@example.com", CurrentPassword: "password123"})
+
+	authService.emailChangeExpiry = -time.Minute
+	authService.RequestEmailChange(ctx, login.User.ID, &ChangeEmailRequest{Email: "third@example.com", CurrentPassword: "password123"})
+	expired := mailer.lastToken(t)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"Malformed", "not-a-token"},
+		{"Access token", login.Token.AccessToken},
+		{"Superseded by a newer request", superseded},
+		{"Expired", expired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := authService.ConfirmEmailChange(ctx, tt.token); !errors.Is(err, ErrInvalidEmailChangeToken) {
+				t.Errorf("ConfirmEmailChange() error = %v, want %v", err, ErrInvalidEmailChangeToken)
+			}
+		})
+	}
+
+	profile, _ := authService.GetProfile(ctx, login.User.ID)
+	if profile.Email != "test@example.com" {
+		t.Errorf("email = %q, want it unchanged", profile.Email)
+	}
+}
+
+func TestAuthService_RequestEmailChange_MailFailure(t *testing.T) {
+	authService, mailer := setupEmailChangeService(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+	mailer.err = errors.New("connection refused")
+
+	if _, err := authService.RequestEmailChange(context.Background(), login.User.ID, &ChangeEmailRequest{Email: "new@example.com", CurrentPassword: "password123"}); err == nil {
+		t.Error("RequestEmailChange() error = nil, want the delivery failure reported")
+	}
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"errors"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// fakeResolver answers MX lookups from a map of domain to records or error
+type fakeResolver struct {
+	records map[string][]*net.MX
+	errs    map[string]error
+}
+
+func (r *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if err, ok := r.errs[name]; ok {
+		return nil, err
+	}
+	return r.records[name], nil
+}
+
+func TestAuthService_Register_MXCheck(t *testing.T) {
+	resolver := &fakeResolver{
+		records: map[string][]*net.MX{
+			"example.com": {{Host: "mail.example.com.", Pref: 10}},
+			"nullmx.com":  {{Host: ".", Pref: 0}},
+		},
+		errs: map[string]error{
+			"missing.com": &net.DNSError{Err: "no such host", Name: "missing.com", IsNotFound: true},
+			"timeout.com": &net.DNSError{Err: "i/o timeout", Name: "timeout.com", IsTimeout: true},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		email   string
+		strict  bool
+		wantErr error
+	}{
+		{"Domain with mail servers", "user@example.com", false, nil},
+		{"Domain without MX records", "user@nomx.com", false, ErrUndeliverableEmail},
+		{"Null MX", "user@nullmx.com", false, ErrUndeliverableEmail},
+		{"Domain not found", "user@missing.com", false, ErrUndeliverableEmail},
+		{"Transient failure is allowed", "user@timeout.com", false, nil},
+		{"Transient failure in strict mode", "user@timeout.com", true, ErrUndeliverableEmail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db := setupTestService(t)
+			authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+				JWTSecret:  "test_secret-key",
+				JWTExpiry:  24 * time.Hour,
+				MXResolver: resolver,
+				MXStrict:   tt.strict,
+			})
+
+			_, err := authService.Register(context.Background(), &RegisterRequest{
+				Email:     tt.email,
+				Password:  "password123",
+				FirstName: "John",
+				LastName:  "Doe",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var fieldErr *FieldError
+			if tt.wantErr != nil && (!errors.As(err, &fieldErr) || fieldErr.Field != "email") {
+				t.Errorf("Register() error = %v, want a FieldError on email", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_Register_MXCheckTimeout(t *testing.T) {
+	_, db := setupTestService(t)
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret:  "test_secret-key",
+		JWTExpiry:  24 * time.Hour,
+		MXResolver: blockingResolver{},
+		MXTimeout:  10 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "user@slow.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	})
+	if err != nil {
+		t.Errorf("Register() error = %v, want the registration allowed after a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Register() took %v, want the lookup to give up after MXTimeout", elapsed)
+	}
+}
+
+// blockingResolver never answers before the lookup's context is done
+type blockingResolver struct{}
+
+func (blockingResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAuthService_Register_BlockedDomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		wantErr error
+	}{
+		{"Blocked domain", "user@mailinator.com", ErrBlockedEmailDomain},
+		{"Blocked domain in other case", "User@MailInator.COM", ErrBlockedEmailDomain},
+		{"Subdomain of wildcard entry", "user@inbox.tempmail.dev", ErrBlockedEmailDomain},
+		{"Nested subdomain of wildcard entry", "user@a.b.tempmail.dev", ErrBlockedEmailDomain},
+		{"Wildcard entry does not block the domain itself", "user@tempmail.dev", nil},
+		{"Exact entry does not block subdomains", "user@eu.mailinator.com", nil},
+		{"Lookalike domain", "user@notmailinator.com", nil},
+		{"Allowed domain", "user@example.com", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db := setupTestService(t)
+			authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+				JWTSecret:           "test_secret-key",
+				JWTExpiry:           24 * time.Hour,
+				BlockedEmailDomains: []string{"mailinator.com", " *.TempMail.dev ", ""},
+			})
+
+			_, err := authService.Register(context.Background(), &RegisterRequest{
+				Email:     tt.email,
+				Password:  "password123",
+				FirstName: "John",
+				LastName:  "Doe",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthService_Register_NoBlockedDomainsByDefault(t *testing.T) {
+	authService, _ := setupTestService(t)
+
+	if _, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "user@mailinator.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err != nil {
+		t.Errorf("Register() error = %v, want no domains blocked by default", err)
+	}
+}
+
+func TestAuthService_Register_ReservedEmails(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		wantErr error
+	}{
+		{"Reserved local part", "postmaster@example.com", ErrReservedEmail},
+		{"Reserved local part in other case", "PostMaster@Example.com", ErrReservedEmail},
+		{"Reserved local part with a tag", "abuse+reports@example.com", ErrReservedEmail},
+		{"Matching pattern", "team-admin@example.com", ErrReservedEmail},
+		{"Pattern on the whole email", "jane@corp.example.com", ErrReservedEmail},
+		{"Reserved word inside a local part", "postmasters@example.com", nil},
+		{"Allowed email", "jane@example.com", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db := setupTestService(t)
+			authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+				JWTSecret:               "test_secret-key",
+				JWTExpiry:               24 * time.Hour,
+				ReservedEmailLocalParts: []string{"postmaster", " Abuse ", ""},
+				ReservedEmailPatterns: []*regexp.Regexp{
+					regexp.MustCompile(`-admin@`),
+					regexp.MustCompile(`@corp\.example\.com$`),
+				},
+			})
+
+			_, err := authService.Register(context.Background(), &RegisterRequest{
+				Email:     tt.email,
+				Password:  "password123",
+				FirstName: "John",
+				LastName:  "Doe",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserService_UpdateUser_BypassesReservedEmails(t *testing.T) {
+	_, db := setupTestService(t)
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret:               "test_secret-key",
+		JWTExpiry:               24 * time.Hour,
+		ReservedEmailLocalParts: []string{"postmaster"},
+	})
+	resp, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "jane@example.com",
+		Password:  "password123",
+		FirstName: "Jane",
+		LastName:  "Doe",
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	userService := NewUserService(postgres.NewUserRepository(db), postgres.NewAuditLogRepository(db))
+	isActive := true
+	if _, err := userService.UpdateUser(context.Background(), 99, resp.User.ID, &AdminUpdateUserRequest{
+		Email:     "postmaster@example.com",
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Role:      models.RoleUser,
+		IsActive:  &isActive,
+	}); err != nil {
+		t.Errorf("UpdateUser() error = %v, want admins to assign reserved emails", err)
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/jwtutil"
+	"customable-corporate-site-api/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// defaultEmailVerifyExpiry is how long the link verifying a new user's email stays valid.
+const defaultEmailVerifyExpiry = 24 * time.Hour
+
+// defaultVerifyResendCooldown is how long an email waits before another verification link
+// can be resent to it.
+const defaultVerifyResendCooldown = time.Minute
+
+// ErrInvalidEmailVerifyToken is returned for verification links that are expired, forged,
+// or issued for an email the account no longer has.
+var ErrInvalidEmailVerifyToken = errors.New("invalid or expired email verification token")
+
+// ErrEmailNotVerified is returned by Login when email verification is required and the user
+// has not opened the verification link yet.
+var ErrEmailNotVerified = errors.New("email address is not verified")
+
+// VerifyEmail marks the user's email as verified, using the token from the link sent at
+// registration. Opening the link again once verified succeeds without changing anything.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := jwtutil.Parse(token, s.keys().Keyfunc)
+	if err != nil || claims.Subject != jwtutil.SubjectEmailVerify {
+		return ErrInvalidEmailVerifyToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || user == nil {
+		return ErrInvalidEmailVerifyToken
+	}
+	// The link only verifies the email it was sent to
+	if user.Email != claims.Email {
+		return ErrInvalidEmailVerifyToken
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return errors.New("failed to verify email")
+	}
+	return nil
+}
+
+// SendVerificationEmail sends the user the link verifying their current email, after they
+// register or an admin changes their email. It is best effort: the account is saved already,
+// so a delivery failure must not fail the request.
+func (s *AuthService) SendVerificationEmail(ctx context.Context, user *models.User) {
+	if s.mailer == nil || s.emailVerifyURL == "" {
+		return
+	}
+
+	token, err := s.issueEmailVerifyToken(user)
+	if err != nil {
+		return
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\nOpen this link to verify the email of your account:\n\n%s\n\n"+
+		"The link expires in %s. If you do not have an account with us, ignore this email.\n",
+		user.FirstName, tokenLink(s.emailVerifyURL, token), s.emailVerifyExpiry)
+	s.mailer.Send(ctx, user.Email, "Verify your email address", body)
+}
+
+// ResendVerificationEmail sends a new verification link to the account with this email, at
+// most once per cooldown. Unknown and already verified emails succeed without sending
+// anything, so the result does not reveal which accounts exist.
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, req *ResendVerificationRequest) error {
+	req.normalize()
+
+	// The cooldown also applies to unknown emails, so they answer the same way
+	sent, err := s.verifyResends.SetNX("verify-resend:"+req.Email, []byte{1}, s.verifyResendCooldown)
+	if err != nil {
+		return errors.New("failed to resend verification email")
+	}
+	if !sent {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil || user == nil || user.EmailVerified {
+		return nil
+	}
+	s.SendVerificationEmail(ctx, user)
+	return nil
+}
+
+// issueEmailVerifyToken signs a token verifying the user's current email. It carries its own
+// subject, so it cannot be used to authenticate.
+func (s *AuthService) issueEmailVerifyToken(user *models.User) (string, error) {
+	now := time.Now()
+	claims := &jwtutil.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.emailVerifyExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   jwtutil.SubjectEmailVerify,
+			Issuer:    "customable-corporate-site-api",
+		},
+	}
+	return s.signToken(claims)
+}
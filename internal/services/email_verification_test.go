@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"customable-corporate-site-api/internal/store"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupEmailVerificationService(t *testing.T, required bool) (*AuthService, *fakeMailer) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	mailer := &fakeMailer{}
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret:                "test_secret-key",
+		JWTExpiry:                time.Hour,
+		Mailer:                   mailer,
+		EmailVerifyURL:           "https://example.com/verify-email",
+		RequireEmailVerification: required,
+	})
+	return authService, mailer
+}
+
+func register(t *testing.T, authService *AuthService, email string) *models.UserResponse {
+	t.Helper()
+	resp, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     email,
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register test user: %v", err)
+	}
+	return resp.User
+}
+
+func TestAuthService_VerifyEmail(t *testing.T) {
+	authService, mailer := setupEmailVerificationService(t, false)
+	user := register(t, authService, "test@example.com")
+	ctx := context.Background()
+
+	if user.EmailVerified {
+		t.Fatal("Register() user is verified, want unverified until the link is opened")
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0].to != "test@example.com" {
+		t.Fatalf("sent = %+v, want one verification link to test@example.com", mailer.sent)
+	}
+
+	token := mailer.lastToken(t)
+	if err := authService.VerifyEmail(ctx, token); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	profile, _ := authService.GetProfile(ctx, user.ID)
+	if !profile.EmailVerified {
+		t.Error("profile EmailVerified = false after VerifyEmail, want true")
+	}
+
+	// Opening the link again is harmless
+	if err := authService.VerifyEmail(ctx, token); err != nil {
+		t.Errorf("VerifyEmail() again error = %v, want nil", err)
+	}
+}
+
+func TestAuthService_VerifyEmail_InvalidTokens(t *testing.T) {
+	authService, mailer := setupEmailVerificationService(t, false)
+	user := register(t, authService, "test@example.com")
+	ctx := context.Background()
+	sent := mailer.lastToken(t)
+
+	login, err := authService.Login(ctx, &LoginRequest{Email: "test@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	authService.emailVerifyExpiry = -time.Minute
+	expired, _ := authService.issueEmailVerifyToken(&models.User{ID: user.ID, Email: user.Email})
+	otherEmail, _ := authService.issueEmailVerifyToken(&models.User{ID: user.ID, Email: "old@example.com"})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"Malformed", "not-a-token"},
+		{"Access token", login.Token.AccessToken},
+		{"Expired", expired},
+		{"For another email", otherEmail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := authService.VerifyEmail(ctx, tt.token); !errors.Is(err, ErrInvalidEmailVerifyToken) {
+				t.Errorf("VerifyEmail() error = %v, want %v", err, ErrInvalidEmailVerifyToken)
+			}
+		})
+	}
+
+	// The verification token does not authenticate
+	if _, err := authService.ValidateToken(ctx, sent); err == nil {
+		t.Error("ValidateToken() accepted an email verification token")
+	}
+}
+
+func TestAuthService_Login_RequiresVerifiedEmail(t *testing.T) {
+	authService, mailer := setupEmailVerificationService(t, true)
+	register(t, authService, "test@example.com")
+	ctx := context.Background()
+	req := &LoginRequest{Email: "test@example.com", Password: "password123"}
+
+	if _, err := authService.Login(ctx, req); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("Login() before verification error = %v, want %v", err, ErrEmailNotVerified)
+	}
+	// The wrong password is reported as such, without revealing the account is unverified
+	if _, err := authService.Login(ctx, &LoginRequest{Email: "test@example.com", Password: "wrong-password"}); errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("Login() with the wrong password error = %v, want invalid credentials", err)
+	}
+
+	if err := authService.VerifyEmail(ctx, mailer.lastToken(t)); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if _, err := authService.Login(ctx, req); err != nil {
+		t.Errorf("Login() after verification error = %v, want nil", err)
+	}
+}
+
+func TestAuthService_ResendVerificationEmail(t *testing.T) {
+	authService, mailer := setupEmailVerificationService(t, false)
+	register(t, authService, "pending@example.com")
+	register(t, authService, "verified@example.com")
+	ctx := context.Background()
+	if err := authService.VerifyEmail(ctx, mailer.lastToken(t)); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+
+	// Steps run in order: the cooldown started by one carries over to the next
+	steps := []struct {
+		name     string
+		email    string
+		wantSent bool
+	}{
+		{"Unverified", " Pending@Example.com ", true},
+		{"Within the cooldown", "pending@example.com", false},
+		{"Already verified", "verified@example.com", false},
+		{"Unknown", "nobody@example.com", false},
+	}
+
+	for _, step := range steps {
+		mailer.sent = nil
+		if err := authService.ResendVerificationEmail(ctx, &ResendVerificationRequest{Email: step.email}); err != nil {
+			t.Fatalf("%s: ResendVerificationEmail() error = %v", step.name, err)
+		}
+		if sent := len(mailer.sent) > 0; sent != step.wantSent {
+			t.Fatalf("%s: ResendVerificationEmail() sent = %v, want %v", step.name, sent, step.wantSent)
+		}
+		if step.wantSent && mailer.sent[0].to != "pending@example.com" {
+			t.Errorf("%s: ResendVerificationEmail() mail to = %q, want %q", step.name, mailer.sent[0].to, "pending@example.com")
+		}
+	}
+}
+
+func TestAuthService_ResendVerificationEmail_SharedCooldown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	// Two instances sharing one store, as behind a load balancer
+	resends := store.NewMemoryStore()
+	mailer := &fakeMailer{}
+	newInstance := func() *AuthService {
+		return NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+			JWTSecret:            "test_secret-key",
+			JWTExpiry:            time.Hour,
+			Mailer:               mailer,
+			EmailVerifyURL:       "https://example.com/verify-email",
+			VerifyResends:        resends,
+			VerifyResendCooldown: 50 * time.Millisecond,
+		})
+	}
+	first, second := newInstance(), newInstance()
+	register(t, first, "test@example.com")
+	ctx := context.Background()
+	req := &ResendVerificationRequest{Email: "test@example.com"}
+
+	mailer.sent = nil
+	_ = first.ResendVerificationEmail(ctx, req)
+	_ = second.ResendVerificationEmail(ctx, req)
+	if len(mailer.sent) != 1 {
+		t.Fatalf("ResendVerificationEmail() on two instances sent %d mails, want 1", len(mailer.sent))
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	_ = second.ResendVerificationEmail(ctx, req)
+	if len(mailer.sent) != 2 {
+		t.Errorf("ResendVerificationEmail() after the cooldown sent %d mails in total, want 2", len(mailer.sent))
+	}
+}
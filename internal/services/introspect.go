@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/jwtutil"
+	"errors"
+	"time"
+)
+
+// ErrInvalidToken is returned when introspecting a token that is forged, malformed or not an
+// access token
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenIntrospectionResponse describes an access token, so clients can decide when to refresh
+// it without decoding it themselves
+type TokenIntrospectionResponse struct {
+	// Active is false once the token expired or was revoked
+	Active    bool      `json:"active"`
+	TokenID   string    `json:"token_id,omitempty"`
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// ExpiresIn is the number of seconds the token remains valid, 0 once it expired
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// Introspect reports the claims and lifetime of an access token. Expired tokens are still
+// described, marked inactive, as are tokens revoked by logging out or by a token version
+// change; only tokens whose signature cannot be verified are refused.
+func (s *AuthService) Introspect(ctx context.Context, tokenString string) (*TokenIntrospectionResponse, error) {
+	claims, err := jwtutil.ParseIgnoringExpiry(tokenString, s.keys().Keyfunc)
+	if err != nil || claims.Subject != jwtutil.SubjectAccess || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+
+	resp := &TokenIntrospectionResponse{
+		TokenID:   claims.ID,
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if claims.IssuedAt != nil {
+		resp.IssuedAt = claims.IssuedAt.Time
+	}
+
+	remaining := time.Until(resp.ExpiresAt)
+	if remaining <= 0 {
+		return resp, nil
+	}
+	resp.ExpiresIn = int64(remaining.Seconds())
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || user == nil || user.TokenVersion != claims.TokenVersion {
+		return resp, nil
+	}
+	if err := s.checkBlacklist(ctx, claims); err != nil {
+		if errors.Is(err, ErrTokenRevoked) {
+			return resp, nil
+		}
+		return nil, err
+	}
+
+	resp.Active = true
+	return resp, nil
+}
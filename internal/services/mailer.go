@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends transactional email such as verification links. NewSMTPMailer returns one
+// that delivers through an SMTP server and NewLogMailer one for development that only logs;
+// tests inject fakes.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// errHeaderInjection is returned for recipients or subjects that would add mail headers
+var errHeaderInjection = errors.New("mail header contains a line break")
+
+// smtpMailer sends plain text email through an SMTP server with STARTTLS when offered
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer creates a mailer sending from the given address through host:port. Without
+// a username the server is used without authentication.
+func NewSMTPMailer(host, port, username, password, from string) Mailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpMailer{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send delivers the message. net/smtp takes no context, so ctx is only checked before sending.
+func (m *smtpMailer) Send(ctx context.Context, to, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return errHeaderInjection
+	}
+
+	message := "From: " + m.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(message))
+}
+
+// logMailer logs messages instead of sending them, for development without an SMTP server
+type logMailer struct{}
+
+// NewLogMailer creates a mailer that writes every message to the standard logger
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+func (logMailer) Send(ctx context.Context, to, subject, body string) error {
+	log.Printf("Email to %s: %s\n%s", to, subject, body)
+	return nil
+}
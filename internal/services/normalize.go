@@ -0,0 +1,93 @@
+package services
+
+import (
+	"customable-corporate-site-api/internal/utils"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Requests that carry emails or names normalize them before the service checks or stores them,
+// so the same person cannot register twice as " John@Example.com" and "john@example.com".
+// Binding validated the names before they were trimmed, so requests with names check them
+// again once normalized.
+
+// Name length limits, matching the min and max binding tags of the requests
+const (
+	minNameLength = 2
+	maxNameLength = 50
+)
+
+func (r *RegisterRequest) normalize() error {
+	r.Email = utils.NormalizeEmail(r.Email)
+	r.FirstName = utils.NormalizeName(r.FirstName)
+	r.LastName = utils.NormalizeName(r.LastName)
+	return checkNames(checkName("first_name", r.FirstName), checkName("last_name", r.LastName))
+}
+
+func (r *LoginRequest) normalize() {
+	r.Email = utils.NormalizeEmail(r.Email)
+}
+
+func (r *UpdateProfileRequest) normalize() error {
+	r.FirstName = utils.NormalizeName(r.FirstName)
+	r.LastName = utils.NormalizeName(r.LastName)
+	return checkNames(checkName("first_name", r.FirstName), checkName("last_name", r.LastName))
+}
+
+func (r *PatchProfileRequest) normalize() error {
+	var errs []*FieldError
+	if r.FirstName != nil {
+		firstName := utils.NormalizeName(*r.FirstName)
+		r.FirstName = &firstName
+		errs = append(errs, checkName("first_name", firstName))
+	}
+	if r.LastName != nil {
+		lastName := utils.NormalizeName(*r.LastName)
+		r.LastName = &lastName
+		errs = append(errs, checkName("last_name", lastName))
+	}
+	return checkNames(errs...)
+}
+
+func (r *ChangeEmailRequest) normalize() {
+	r.Email = utils.NormalizeEmail(r.Email)
+}
+
+func (r *ResendVerificationRequest) normalize() {
+	r.Email = utils.NormalizeEmail(r.Email)
+}
+
+func (r *AdminUpdateUserRequest) normalize() error {
+	r.Email = utils.NormalizeEmail(r.Email)
+	r.FirstName = utils.NormalizeName(r.FirstName)
+	r.LastName = utils.NormalizeName(r.LastName)
+	return checkNames(checkName("first_name", r.FirstName), checkName("last_name", r.LastName))
+}
+
+// checkName reports a normalized name that breaks the length limits: a whitespace-only name
+// is now empty and a padded one may be too short
+func checkName(field, name string) *FieldError {
+	switch length := utf8.RuneCountInString(name); {
+	case length == 0:
+		return &FieldError{Field: field, Code: utils.CodeRequired, Message: field + " is required"}
+	case length < minNameLength:
+		return &FieldError{Field: field, Code: utils.CodeTooShort, Message: fmt.Sprintf("%s must be at least %d characters long", field, minNameLength)}
+	case length > maxNameLength:
+		return &FieldError{Field: field, Code: utils.CodeTooLong, Message: fmt.Sprintf("%s must be at most %d characters long", field, maxNameLength)}
+	}
+	return nil
+}
+
+// checkNames returns the name errors found, or nil when every name is valid
+func checkNames(errs ...*FieldError) error {
+	var found FieldErrors
+	for _, err := range errs {
+		if err != nil {
+			found = append(found, err)
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return found
+}
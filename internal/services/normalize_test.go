@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+	"testing"
+)
+
+func TestAuthService_Register_Normalizes(t *testing.T) {
+	authService, _ := setupTestService(t)
+
+	resp, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "  John.Doe@Example.COM ",
+		Password:  "password123",
+		FirstName: "  John  ",
+		LastName:  " van   Doe ",
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if resp.User.Email != "john.doe@example.com" || resp.User.FirstName != "John" || resp.User.LastName != "van Doe" {
+		t.Errorf("Register() user = %+v, want john.doe@example.com, John, van Doe", resp.User)
+	}
+
+	// The same address in another case or with spaces is a duplicate
+	if _, err := authService.Register(context.Background(), &RegisterRequest{
+		Email:     "JOHN.DOE@example.com",
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+	}); err == nil {
+		t.Error("Register() with a differently cased email error = nil, want duplicate rejected")
+	}
+}
+
+func TestAuthService_UpdateProfile_Normalizes(t *testing.T) {
+	authService, _ := setupTestService(t)
+	login := registerAndLogin(t, authService, "test@example.com")
+	ctx := context.Background()
+
+	user, err := authService.UpdateProfile(ctx, login.User.ID, &UpdateProfileRequest{FirstName: "  John  ", LastName: "Mc   Doe"})
+	if err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+	if user.FirstName != "John" || user.LastName != "Mc Doe" {
+		t.Errorf("UpdateProfile() = %q %q, want %q %q", user.FirstName, user.LastName, "John", "Mc Doe")
+	}
+
+	firstName := " Jane "
+	user, err = authService.PatchProfile(ctx, login.User.ID, &PatchProfileRequest{FirstName: &firstName})
+	if err != nil {
+		t.Fatalf("PatchProfile() error = %v", err)
+	}
+	if user.FirstName != "Jane" || user.LastName != "Mc Doe" {
+		t.Errorf("PatchProfile() = %q %q, want %q %q", user.FirstName, user.LastName, "Jane", "Mc Doe")
+	}
+}
+
+func TestUserService_UpdateUser_Normalizes(t *testing.T) {
+	userService, db := setupUserService(t)
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	target := createTestUser(t, db, "user@example.com", models.RoleUser)
+
+	user, err := userService.UpdateUser(context.Background(), admin.ID, target.ID, &AdminUpdateUserRequest{
+		Email:     " User@Example.com ",
+		FirstName: "  John  ",
+		LastName:  "  Doe  ",
+		Role:      models.RoleUser,
+		IsActive:  boolPtr(true),
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if user.Email != "user@example.com" || user.FirstName != "John" || user.LastName != "Doe" {
+		t.Errorf("UpdateUser() = %+v, want user@example.com, John, Doe", user)
+	}
+}
+
+func TestNormalize_RejectsBlankNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		firstName string
+		wantErr   error
+	}{
+		{"Whitespace-only name", "   ", &FieldError{Field: "first_name", Code: utils.CodeRequired}},
+		{"Padded single character", " J ", &FieldError{Field: "first_name", Code: utils.CodeTooShort}},
+		{"Padded valid name", " Jo ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService, _ := setupTestService(t)
+			login := registerAndLogin(t, authService, "test@example.com")
+			ctx := context.Background()
+
+			_, err := authService.Register(ctx, &RegisterRequest{Email: "new@example.com", Password: "password123", FirstName: tt.firstName, LastName: "Doe"})
+			if !errorMatches(err, tt.wantErr) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, err = authService.UpdateProfile(ctx, login.User.ID, &UpdateProfileRequest{FirstName: tt.firstName, LastName: "Doe"})
+			if !errorMatches(err, tt.wantErr) {
+				t.Errorf("UpdateProfile() error = %v, want %v", err, tt.wantErr)
+			}
+
+			firstName := tt.firstName
+			_, err = authService.PatchProfile(ctx, login.User.ID, &PatchProfileRequest{FirstName: &firstName})
+			if !errorMatches(err, tt.wantErr) {
+				t.Errorf("PatchProfile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserService_UpdateUser_RejectsBlankNames(t *testing.T) {
+	userService, db := setupUserService(t)
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	target := createTestUser(t, db, "user@example.com", models.RoleUser)
+
+	_, err := userService.UpdateUser(context.Background(), admin.ID, target.ID, &AdminUpdateUserRequest{
+		Email:     "user@example.com",
+		FirstName: "   ",
+		LastName:  " D ",
+		Role:      models.RoleUser,
+		IsActive:  boolPtr(true),
+	})
+	if !errors.Is(err, &FieldError{Field: "first_name", Code: utils.CodeRequired}) || !errors.Is(err, &FieldError{Field: "last_name", Code: utils.CodeTooShort}) {
+		t.Errorf("UpdateUser() error = %v, want first_name required and last_name too short", err)
+	}
+
+	var stored models.User
+	db.First(&stored, target.ID)
+	if stored.FirstName != target.FirstName || stored.LastName != target.LastName {
+		t.Errorf("UpdateUser() stored %q %q, want the names unchanged", stored.FirstName, stored.LastName)
+	}
+}
+
+// errorMatches reports whether err is want, where a nil want expects no error
+func errorMatches(err, want error) bool {
+	if want == nil {
+		return err == nil
+	}
+	return errors.Is(err, want)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/utils"
+	"errors"
+)
+
+// ErrPasswordTooShort is returned when a new password is shorter than the password policy
+// allows. The message varies with the policy; errors.Is matches on the field and code.
+var ErrPasswordTooShort = &FieldError{
+	Field:   "new_password",
+	Code:    utils.CodeTooShort,
+	Message: "new_password must be at least 6 characters",
+}
+
+// ErrPasswordUnchanged is returned when the new password is the current password.
+var ErrPasswordUnchanged = &FieldError{
+	Field:   "new_password",
+	Code:    utils.CodePasswordUnchanged,
+	Message: "new password must differ from the current password",
+}
+
+// ChangePassword replaces the authenticated user's password after checking the current one.
+// Every token issued before, including the caller's, stops working and every session is
+// revoked, so the user logs in again with the new password.
+func (s *AuthService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	user, err := s.reauthenticate(ctx, userID, currentPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkPasswordStrength("new_password", newPassword); err != nil {
+		return err
+	}
+	if user.CheckPassword(newPassword) {
+		return ErrPasswordUnchanged
+	}
+
+	if err := user.SetPassword(newPassword); err != nil {
+		return errors.New("failed to hash password")
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return errors.New("failed to change password")
+	}
+
+	if err := s.userRepo.IncrementTokenVersion(ctx, user.ID); err != nil {
+		return errors.New("failed to revoke tokens")
+	}
+	if s.sessionRepo != nil {
+		if _, err := s.sessionRepo.RevokeAllByUser(ctx, user.ID); err != nil {
+			return errors.New("failed to revoke sessions")
+		}
+	}
+	return nil
+}
+
+// checkPasswordStrength checks a new password against the password policy, reporting every
+// broken rule on field
+func (s *AuthService) checkPasswordStrength(field, password string) error {
+	details := s.passwordPolicy.Validate(field, password)
+	if len(details) == 0 {
+		return nil
+	}
+
+	errs := make(FieldErrors, len(details))
+	for i, detail := range details {
+		errs[i] = &FieldError{Field: detail.Field, Code: detail.Code, Message: detail.Message}
+	}
+	return errs
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"customable-corporate-site-api/internal/models"
+	"customable-corporate-site-api/internal/repositories/postgres"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupUserService(t *testing.T) (*UserService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.User{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	userService := NewUserService(postgres.NewUserRepository(db), postgres.NewAuditLogRepository(db))
+	return userService, db
+}
+
+func createTestUser(t *testing.T, db *gorm.DB, email, role string) *models.User {
+	user := &models.User{
+		Email:     email,
+		Password:  "password123",
+		FirstName: "John",
+		LastName:  "Doe",
+		Role:      role,
+		IsActive:  true,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	return user
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestUserService_UpdateUser(t *testing.T) {
+	userService, db := setupUserService(t)
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	target := createTestUser(t, db, "user@example.com", models.RoleUser)
+	createTestUser(t, db, "taken@example.com", models.RoleUser)
+
+	tests := []struct {
+		name     string
+		targetID uint
+		req      *AdminUpdateUserRequest
+		wantErr  error
+	}{
+		{
+			name:     "Update all fields",
+			targetID: target.ID,
+			req: &AdminUpdateUserRequest{
+				Email:     " New@Example.com ",
+				FirstName: "Jane",
+				LastName:  "Smith",
+				Role:      models.RoleEditor,
+				IsActive:  boolPtr(false),
+			},
+		},
+		{
+			name:     "Email already taken",
+			targetID: target.ID,
+			req: &AdminUpdateUserRequest{
+				Email:     "taken@example.com",
+				FirstName: "Jane",
+				LastName:  "Smith",
+				Role:      models.RoleEditor,
+				IsActive:  boolPtr(true),
+			},
+			wantErr: ErrEmailTaken,
+		},
+		{
+			name:     "User not found",
+			targetID: 999,
+			req: &AdminUpdateUserRequest{
+				Email:     "ghost@example.com",
+				FirstName: "Jane",
+				LastName:  "Smith",
+				Role:      models.RoleUser,
+				IsActive:  boolPtr(true),
+			},
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name:     "Admin removing own admin role",
+			targetID: admin.ID,
+			req: &AdminUpdateUserRequest{
+				Email:     "admin@example.com",
+				FirstName: "John",
+				LastName:  "Doe",
+				Role:      models.RoleUser,
+				IsActive:  boolPtr(true),
+			},
+			wantErr: ErrCannotChangeOwnRole,
+		},
+		{
+			name:     "Admin updating own name",
+			targetID: admin.ID,
+			req: &AdminUpdateUserRequest{
+				Email:     "admin@example.com",
+				FirstName: "Ada",
+				LastName:  "Admin",
+				Role:      models.RoleAdmin,
+				IsActive:  boolPtr(true),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := userService.UpdateUser(context.Background(), admin.ID, tt.targetID, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if resp.FirstName != tt.req.FirstName || resp.Role != tt.req.Role || resp.IsActive != *tt.req.IsActive {
+				t.Errorf("UpdateUser() = %+v, want fields from %+v", resp, tt.req)
+			}
+
+			var stored models.User
+			db.First(&stored, tt.targetID)
+			if stored.IsActive != *tt.req.IsActive {
+				t.Errorf("UpdateUser() stored is_active = %v, want %v", stored.IsActive, *tt.req.IsActive)
+			}
+		})
+	}
+
+	var stored models.User
+	db.First(&stored, target.ID)
+	if stored.Email != "new@example.com" {
+		t.Errorf("UpdateUser() stored email = %q, want normalized %q", stored.Email, "new@example.com")
+	}
+}
+
+func TestUserService_UpdateUser_AuditLog(t *testing.T) {
+	userService, db := setupUserService(t)
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	target := createTestUser(t, db, "user@example.com", models.RoleUser)
+
+	req := &AdminUpdateUserRequest{
+		Email:     "user@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+		Role:      models.RoleEditor,
+		IsActive:  boolPtr(true),
+		IPAddress: "127.0.0.1",
+	}
+	if _, err := userService.UpdateUser(context.Background(), admin.ID, target.ID, req); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+
+	var entries []models.AuditLog
+	db.Find(&entries)
+	if len(entries) != 1 {
+		t.Fatalf("audit log entries = %d, want 1", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.ActorID != admin.ID || entry.TargetID != target.ID || entry.Action != models.AuditActionUserUpdated {
+		t.Errorf("audit log entry = %+v, want admin updating target", entry)
+	}
+	if entry.IPAddress != "127.0.0.1" {
+		t.Errorf("audit log ip_address = %q, want %q", entry.IPAddress, "127.0.0.1")
+	}
+
+	var details map[string]fieldChange
+	if err := json.Unmarshal([]byte(entry.Details), &details); err != nil {
+		t.Fatalf("Failed to decode audit details %q: %v", entry.Details, err)
+	}
+	if len(details) != 1 || details["role"].To != models.RoleEditor {
+		t.Errorf("audit log details = %v, want only the role change", details)
+	}
+
+	// An update that changes nothing is not audited
+	if _, err := userService.UpdateUser(context.Background(), admin.ID, target.ID, req); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	var count int64
+	db.Model(&models.AuditLog{}).Count(&count)
+	if count != 1 {
+		t.Errorf("audit log entries after no-op update = %d, want 1", count)
+	}
+}
+
+// A change whose audit entry cannot be written must not be applied either
+func TestUserService_AuditFailureRollsBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(s *UserService, actorID, targetID uint) error
+	}{
+		{"UpdateUser", func(s *UserService, actorID, targetID uint) error {
+			_, err := s.UpdateUser(context.Background(), actorID, targetID, &AdminUpdateUserRequest{
+				Email: "user@example.com", FirstName: "John", LastName: "Doe", Role: models.RoleEditor, IsActive: boolPtr(false),
+			})
+			return err
+		}},
+		{"UpdateUserRole", func(s *UserService, actorID, targetID uint) error {
+			_, err := s.UpdateUserRole(context.Background(), actorID, targetID, &UpdateUserRoleRequest{Role: models.RoleEditor})
+			return err
+		}},
+		{"UpdateUserStatus", func(s *UserService, actorID, targetID uint) error {
+			_, err := s.UpdateUserStatus(context.Background(), actorID, targetID, &UpdateUserStatusRequest{IsActive: boolPtr(false)})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService, db := setupUserService(t)
+			admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+			target := createTestUser(t, db, "user@example.com", models.RoleUser)
+			if err := db.Migrator().DropTable(&models.AuditLog{}); err != nil {
+				t.Fatalf("Failed to drop audit log table: %v", err)
+			}
+
+			if err := tt.update(userService, admin.ID, target.ID); err == nil {
+				t.Fatalf("%s() error = nil, want the audit failure", tt.name)
+			}
+
+			var stored models.User
+			db.First(&stored, target.ID)
+			if stored.Role != models.RoleUser || !stored.IsActive || stored.TokenVersion != target.TokenVersion {
+				t.Errorf("%s() left role = %s, is_active = %v, token_version = %d, want the user unchanged", tt.name, stored.Role, stored.IsActive, stored.TokenVersion)
+			}
+		})
+	}
+}
+
+func TestUserService_PurgeDeletedUsers(t *testing.T) {
+	userService, db := setupUserService(t)
+	retention := 30 * 24 * time.Hour
+
+	active := createTestUser(t, db, "active@example.com", models.RoleUser)
+	recent := createTestUser(t, db, "recent@example.com", models.RoleUser)
+	var expired []*models.User
+	for _, email := range []string{"old1@example.com", "old2@example.com", "old3@example.com"} {
+		expired = append(expired, createTestUser(t, db, email, models.RoleUser))
+	}
+
+	// Soft-delete users inside and outside the retention window
+	db.Model(recent).Update("deleted_at", time.Now().Add(-retention+time.Hour))
+	for _, user := range expired {
+		db.Model(user).Update("deleted_at", time.Now().Add(-retention-time.Hour))
+	}
+
+	// A batch size smaller than the number of expired users needs several batches
+	purged, err := userService.PurgeDeletedUsers(context.Background(), retention, 2)
+	if err != nil {
+		t.Fatalf("PurgeDeletedUsers() error = %v", err)
+	}
+	if purged != int64(len(expired)) {
+		t.Errorf("PurgeDeletedUsers() = %d, want %d", purged, len(expired))
+	}
+
+	var remaining []models.User
+	db.Unscoped().Order("id").Find(&remaining)
+	if len(remaining) != 2 || remaining[0].ID != active.ID || remaining[1].ID != recent.ID {
+		t.Errorf("remaining users = %+v, want the active and recently deleted users", remaining)
+	}
+
+	var entries []models.AuditLog
+	db.Find(&entries)
+	if len(entries) != 1 || entries[0].Action != models.AuditActionUsersPurged {
+		t.Fatalf("audit log entries = %+v, want one purge entry", entries)
+	}
+	var details struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(entries[0].Details), &details); err != nil || details.Count != purged {
+		t.Errorf("audit log details = %q, want count %d", entries[0].Details, purged)
+	}
+
+	// Nothing left to purge, so nothing is audited
+	if purged, err := userService.PurgeDeletedUsers(context.Background(), retention, 2); err != nil || purged != 0 {
+		t.Errorf("PurgeDeletedUsers() second run = %d, %v, want 0, nil", purged, err)
+	}
+	var count int64
+	db.Model(&models.AuditLog{}).Count(&count)
+	if count != 1 {
+		t.Errorf("audit log entries after empty purge = %d, want 1", count)
+	}
+}
+
+func TestUserService_RevokeTokens(t *testing.T) {
+	_, db := setupUserService(t)
+	if err := db.AutoMigrate(&models.Session{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	sessionRepo := postgres.NewSessionRepository(db)
+	userService := NewUserServiceWithConfig(postgres.NewUserRepository(db), postgres.NewAuditLogRepository(db), UserServiceConfig{
+		Sessions: sessionRepo,
+	})
+	ctx := context.Background()
+
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	target := createTestUser(t, db, "user@example.com", models.RoleUser)
+	for _, tokenID := range []string{"token-1", "token-2"} {
+		session := &models.Session{UserID: target.ID, TokenID: tokenID, LastUsedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+		if err := sessionRepo.Create(ctx, session); err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+	}
+
+	result, err := userService.RevokeTokens(ctx, admin.ID, target.ID, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("RevokeTokens() error = %v", err)
+	}
+	if result.SessionsRevoked != 2 {
+		t.Errorf("RevokeTokens() sessions revoked = %d, want 2", result.SessionsRevoked)
+	}
+
+	var reloaded models.User
+	db.First(&reloaded, target.ID)
+	if reloaded.TokenVersion != 1 {
+		t.Errorf("token version = %d, want 1", reloaded.TokenVersion)
+	}
+	if sessions, _ := sessionRepo.ListActiveByUser(ctx, target.ID); len(sessions) != 0 {
+		t.Errorf("active sessions = %d, want 0", len(sessions))
+	}
+
+	var entry models.AuditLog
+	if err := db.Where("action = ?", models.AuditActionUserTokensRevoked).First(&entry).Error; err != nil {
+		t.Fatalf("no audit log entry for the revocation: %v", err)
+	}
+	if entry.ActorID != admin.ID || entry.TargetID != target.ID || entry.IPAddress != "192.0.2.1" {
+		t.Errorf("audit log entry = %+v, want the admin revoking the user's tokens", entry)
+	}
+
+	// Saving a copy of the user loaded before the revocation must not undo it
+	target.FirstName = "Johnny"
+	if err := postgres.NewUserRepository(db).Update(ctx, target); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	db.First(&reloaded, target.ID)
+	if reloaded.TokenVersion != 1 {
+		t.Errorf("token version after saving a stale copy = %d, want 1", reloaded.TokenVersion)
+	}
+
+	if _, err := userService.RevokeTokens(ctx, admin.ID, 99, ""); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("RevokeTokens() unknown user error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestUserService_UpdateUser_TokenVersion(t *testing.T) {
+	userService, db := setupUserService(t)
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	target := createTestUser(t, db, "user@example.com", models.RoleUser)
+
+	tests := []struct {
+		name        string
+		role        string
+		isActive    bool
+		wantVersion uint
+	}{
+		{"Unchanged", models.RoleUser, true, 0},
+		{"Deactivated", models.RoleUser, false, 1},
+		{"Still inactive", models.RoleUser, false, 1},
+		{"Reactivated", models.RoleUser, true, 1},
+		{"Role changed", models.RoleEditor, true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &AdminUpdateUserRequest{
+				Email:     "user@example.com",
+				FirstName: "John",
+				LastName:  "Doe",
+				Role:      tt.role,
+				IsActive:  boolPtr(tt.isActive),
+			}
+			if _, err := userService.UpdateUser(context.Background(), admin.ID, target.ID, req); err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			var reloaded models.User
+			db.First(&reloaded, target.ID)
+			if reloaded.TokenVersion != tt.wantVersion {
+				t.Errorf("token version = %d, want %d", reloaded.TokenVersion, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestUserService_UpdateUser_EmailVerification(t *testing.T) {
+	_, db := setupUserService(t)
+	mailer := &fakeMailer{}
+	authService := NewAuthServiceWithConfig(postgres.NewUserRepository(db), AuthConfig{
+		JWTSecret:      "test_secret-key",
+		JWTExpiry:      time.Hour,
+		Mailer:         mailer,
+		EmailVerifyURL: "https://example.com/verify-email",
+	})
+	userService := NewUserServiceWithConfig(postgres.NewUserRepository(db), postgres.NewAuditLogRepository(db), UserServiceConfig{
+		Verification: authService,
+	})
+
+	admin := createTestUser(t, db, "admin@example.com", models.RoleAdmin)
+	target := createTestUser(t, db, "user@example.com", models.RoleUser)
+	verifiedAt := time.Now()
+	db.Model(target).Updates(map[string]interface{}{"email_verified": true, "email_verified_at": verifiedAt})
+
+	tests := []struct {
+		name         string
+		email        string
+		wantVerified bool
+		wantSent     int
+	}{
+		{"Same email in another case", " USER@example.com ", true, 0},
+		{"Changed email", "new@example.com", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer.sent = nil
+			req := &AdminUpdateUserRequest{
+				Email:     tt.email,
+				FirstName: "John",
+				LastName:  "Doe",
+				Role:      models.RoleUser,
+				IsActive:  boolPtr(true),
+			}
+			if _, err := userService.UpdateUser(context.Background(), admin.ID, target.ID, req); err != nil {
+				t.Fatalf("UpdateUser() error = %v", err)
+			}
+
+			var stored models.User
+			db.First(&stored, target.ID)
+			if stored.EmailVerified != tt.wantVerified {
+				t.Errorf("UpdateUser() stored email_verified = %v, want %v", stored.EmailVerified, tt.wantVerified)
+			}
+			if (stored.EmailVerifiedAt != nil) != tt.wantVerified {
+				t.Errorf("UpdateUser() stored email_verified_at = %v, want set = %v", stored.EmailVerifiedAt, tt.wantVerified)
+			}
+			if len(mailer.sent) != tt.wantSent {
+				t.Fatalf("UpdateUser() sent %d mails, want %d", len(mailer.sent), tt.wantSent)
+			}
+			if tt.wantSent > 0 && mailer.sent[0].to != stored.Email {
+				t.Errorf("UpdateUser() mail to = %q, want %q", mailer.sent[0].to, stored.Email)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import "strings"
+
+// FieldError is a validation failure on a single request field that is only detected by the
+// service, after request binding has succeeded. Handlers report it like a binding error.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Is reports whether target is a FieldError for the same field and code, so checks such as
+// errors.Is(err, ErrPasswordTooShort) also match failures built from a policy
+func (e *FieldError) Is(target error) bool {
+	t, ok := target.(*FieldError)
+	return ok && t.Field == e.Field && t.Code == e.Code
+}
+
+// FieldErrors reports several validation failures found at once, such as every rule a new
+// password breaks. errors.Is and errors.As see each of them.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryStore is an in-process Store. State is not shared between instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates a new in-memory Store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value stored under key, or ErrNotFound
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if entry.expired(s.now()) {
+		delete(s.entries, key)
+		return nil, ErrNotFound
+	}
+
+	value := make([]byte, len(entry.value))
+	copy(value, entry.value)
+	return value, nil
+}
+
+// Set stores value under key; a ttl of zero or less keeps the key until it is deleted
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := memoryEntry{value: make([]byte, len(value))}
+	copy(entry.value, value)
+	if ttl > 0 {
+		entry.expiresAt = s.now().Add(ttl)
+	}
+	s.entries[key] = entry
+
+	s.evictExpired()
+	return nil
+}
+
+// SetNX stores value under key only when key is missing or expired, and reports whether it did
+func (s *MemoryStore) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, ok := s.entries[key]; ok && !entry.expired(now) {
+		return false, nil
+	}
+
+	entry := memoryEntry{value: make([]byte, len(value))}
+	copy(entry.value, value)
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	s.entries[key] = entry
+	return true, nil
+}
+
+// Delete removes key from the store
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Incr atomically increments the counter under key and returns the new count
+func (s *MemoryStore) Incr(key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(now) {
+		entry = memoryEntry{}
+		if ttl > 0 {
+			entry.expiresAt = now.Add(ttl)
+		}
+	}
+
+	count, _ := strconv.ParseInt(string(entry.value), 10, 64)
+	count++
+	entry.value = []byte(strconv.FormatInt(count, 10))
+	s.entries[key] = entry
+
+	return count, nil
+}
+
+// TTL returns how long until key expires, or ErrNotFound
+func (s *MemoryStore) TTL(key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entry, ok := s.entries[key]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	if entry.expired(now) {
+		delete(s.entries, key)
+		return 0, ErrNotFound
+	}
+	if entry.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return entry.expiresAt.Sub(now), nil
+}
+
+// evictExpired drops expired entries so keys that are never read again don't accumulate.
+// Callers must hold s.mu.
+func (s *MemoryStore) evictExpired() {
+	now := s.now()
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	if err := s.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, err := s.Get("key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(got) != "value" {
+		t.Errorf("Get() = %q, want %q", got, "value")
+	}
+
+	if _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() missing key error = %v, want %v", err, ErrNotFound)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := s.Get("key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() expired key error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestMemoryStore_NoTTL(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	s.Set("key", []byte("value"), 0)
+	now = now.Add(24 * time.Hour)
+
+	if _, err := s.Get("key"); err != nil {
+		t.Errorf("Get() error = %v, want key without ttl to persist", err)
+	}
+
+	s.Delete("key")
+	if _, err := s.Get("key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() deleted key error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestMemoryStore_SetNX(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	if ok, err := s.SetNX("key", []byte("first"), time.Minute); err != nil || !ok {
+		t.Fatalf("SetNX() missing key = %v, %v, want true", ok, err)
+	}
+	if ok, err := s.SetNX("key", []byte("second"), time.Minute); err != nil || ok {
+		t.Errorf("SetNX() existing key = %v, %v, want false", ok, err)
+	}
+	if got, _ := s.Get("key"); string(got) != "first" {
+		t.Errorf("Get() after refused SetNX() = %q, want %q", got, "first")
+	}
+
+	now = now.Add(time.Minute)
+	if ok, err := s.SetNX("key", []byte("third"), time.Minute); err != nil || !ok {
+		t.Errorf("SetNX() expired key = %v, %v, want true", ok, err)
+	}
+}
+
+func TestMemoryStore_Incr(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := s.Incr("counter", time.Minute)
+		if err != nil {
+			t.Fatalf("Incr() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Incr() = %d, want %d", got, want)
+		}
+		// Later increments keep the expiry of the first one
+		now = now.Add(10 * time.Second)
+	}
+
+	now = now.Add(30 * time.Second)
+	if got, _ := s.Incr("counter", time.Minute); got != 1 {
+		t.Errorf("Incr() after expiry = %d, want 1", got)
+	}
+}
+
+func TestMemoryStore_TTL(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	s.Incr("counter", time.Minute)
+	s.Set("forever", []byte("value"), 0)
+	now = now.Add(20 * time.Second)
+
+	if got, err := s.TTL("counter"); err != nil || got != 40*time.Second {
+		t.Errorf("TTL() = %v, %v, want %v", got, err, 40*time.Second)
+	}
+	if got, err := s.TTL("forever"); err != nil || got != 0 {
+		t.Errorf("TTL() without expiry = %v, %v, want 0", got, err)
+	}
+	if _, err := s.TTL("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("TTL() missing key error = %v, want %v", err, ErrNotFound)
+	}
+
+	now = now.Add(40 * time.Second)
+	if _, err := s.TTL("counter"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("TTL() expired key error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,27 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist or has expired
+var ErrNotFound = errors.New("key not found")
+
+// Store defines a key-value store with per-key expiry, shared by middleware that needs
+// state across requests (and across instances when backed by a shared implementation)
+type Store interface {
+	// Get returns the value stored under key, or ErrNotFound
+	Get(key string) ([]byte, error)
+	// Set stores value under key; a ttl of zero or less keeps the key until it is deleted
+	Set(key string, value []byte, ttl time.Duration) error
+	// SetNX atomically stores value under key only when key is missing or expired, and
+	// reports whether it did
+	SetNX(key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(key string) error
+	// Incr atomically increments the counter under key and returns the new count. A missing
+	// or expired key starts at 1 and expires after ttl; later increments keep that expiry.
+	Incr(key string, ttl time.Duration) (int64, error)
+	// TTL returns how long until key expires, or ErrNotFound. A key without expiry returns 0.
+	TTL(key string) (time.Duration, error)
+}
@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Header is the W3C Trace Context header carrying the trace and parent span IDs
+const Header = "traceparent"
+
+// version is the only traceparent version this package emits
+const version = "00"
+
+// Traceparent identifies a trace and the span a request belongs to, as defined by
+// W3C Trace Context: version-traceid-parentid-flags, all lowercase hex
+type Traceparent struct {
+	TraceID string // 32 hex digits
+	SpanID  string // 16 hex digits
+	Flags   string // 2 hex digits; 01 means sampled
+}
+
+// String formats the traceparent as a header value
+func (t Traceparent) String() string {
+	return version + "-" + t.TraceID + "-" + t.SpanID + "-" + t.Flags
+}
+
+// Parse parses a traceparent header value, reporting false when it is malformed.
+// Versions other than 00 are accepted as long as their first four fields are valid.
+func Parse(value string) (Traceparent, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return Traceparent{}, false
+	}
+
+	v, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isHex(v, 2) || v == "ff" || (v == version && len(parts) != 4) {
+		return Traceparent{}, false
+	}
+	if !isHex(traceID, 32) || isZero(traceID) || !isHex(spanID, 16) || isZero(spanID) || !isHex(flags, 2) {
+		return Traceparent{}, false
+	}
+
+	return Traceparent{TraceID: traceID, SpanID: spanID, Flags: flags}, true
+}
+
+// New starts a new, unsampled trace
+func New() Traceparent {
+	return Traceparent{TraceID: randomHex(16), SpanID: randomHex(8), Flags: "00"}
+}
+
+// Child returns a traceparent for a new span in the same trace
+func (t Traceparent) Child() Traceparent {
+	return Traceparent{TraceID: t.TraceID, SpanID: randomHex(8), Flags: t.Flags}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the traceparent
+func NewContext(ctx context.Context, t Traceparent) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the traceparent stored in ctx, if any
+func FromContext(ctx context.Context) (Traceparent, bool) {
+	t, ok := ctx.Value(contextKey{}).(Traceparent)
+	return t, ok
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID, so code that only sees the
+// context, such as the database logger, can tag its output with it
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or "" when there is none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Inject sets the traceparent header of an outbound request from its context, so
+// webhooks, OAuth and mail providers receive the trace of the request that caused them
+func Inject(req *http.Request) {
+	if t, ok := FromContext(req.Context()); ok {
+		req.Header.Set(Header, t.String())
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// isHex reports whether s is exactly n lowercase hex digits
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
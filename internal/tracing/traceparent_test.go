@@ -0,0 +1,45 @@
+package tracing
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		wantOK bool
+	}{
+		{"Valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"Future version with extra fields", "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"Version 00 with extra fields", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"Forbidden version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"Uppercase hex", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01", false},
+		{"All-zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"All-zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"Short trace id", "00-4bf92f3577b34da6-00f067aa0ba902b7-01", false},
+		{"Empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("Parse(%q) ok = %v, want %v", tt.value, ok, tt.wantOK)
+			}
+			if ok && got.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("Parse(%q) trace id = %q", tt.value, got.TraceID)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	trace := New()
+	if _, ok := Parse(trace.String()); !ok {
+		t.Errorf("New() = %q, want a valid traceparent", trace)
+	}
+
+	child := trace.Child()
+	if child.TraceID != trace.TraceID || child.SpanID == trace.SpanID {
+		t.Errorf("Child() = %q, want a new span in trace %s", child, trace.TraceID)
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+// Error codes returned in the "code" field of error responses so clients can react without parsing messages
+const (
+	// Authentication errors
+	CodeTokenMissing          = "TOKEN_MISSING"
+	CodeTokenMalformed        = "TOKEN_MALFORMED"
+	CodeTokenExpired          = "TOKEN_EXPIRED"
+	CodeTokenNotYetValid      = "TOKEN_NOT_YET_VALID"
+	CodeTokenSignatureInvalid = "TOKEN_SIGNATURE_INVALID"
+	CodeTokenInvalid          = "TOKEN_INVALID"
+	CodeTokenRevoked          = "TOKEN_REVOKED"
+
+	// Returned when a sensitive change is attempted with the wrong current password
+	CodeInvalidCurrentPassword = "INVALID_CURRENT_PASSWORD"
+
+	// Returned by login while an account is locked after repeated failures
+	CodeAccountLocked = "ACCOUNT_LOCKED"
+
+	// Returned by login when email verification is required and the email is not verified
+	CodeEmailNotVerified = "EMAIL_NOT_VERIFIED"
+
+	// Returned while the API is in maintenance mode
+	CodeMaintenance = "MAINTENANCE"
+)
+
+// Validation error codes set by services on checks that go beyond the request's binding tags
+const (
+	CodeUndeliverableEmail = "UNDELIVERABLE_EMAIL"
+	CodeBlockedEmailDomain = "BLOCKED_EMAIL_DOMAIN"
+	CodeReservedEmail      = "RESERVED_EMAIL"
+	CodeCaptchaRequired    = "CAPTCHA_REQUIRED"
+	CodeCaptchaInvalid     = "CAPTCHA_INVALID"
+	CodeEmailUnchanged     = "EMAIL_UNCHANGED"
+	CodePasswordUnchanged  = "PASSWORD_UNCHANGED"
+	CodeInvalidDate        = "INVALID_DATE"
+	CodeInvalidDateRange   = "INVALID_DATE_RANGE"
+
+	// Returned for new passwords that break the password policy; short ones get TOO_SHORT
+	CodePasswordMissingUpper = "PASSWORD_MISSING_UPPERCASE"
+	CodePasswordMissingLower = "PASSWORD_MISSING_LOWERCASE"
+	CodePasswordMissingDigit = "PASSWORD_MISSING_DIGIT"
+	CodePasswordTooCommon    = "PASSWORD_TOO_COMMON"
+)
+
+// Validation error codes set on ErrorDetail.Code, mapped from validator tags:
+//
+//	required          -> REQUIRED
+//	email             -> INVALID_EMAIL
+//	min (strings)     -> TOO_SHORT
+//	min (numbers)     -> TOO_SMALL
+//	max (strings)     -> TOO_LONG
+//	max (numbers)     -> TOO_LARGE
+//	len               -> INVALID_LENGTH
+//	oneof             -> INVALID_CHOICE
+//	numeric, number   -> NOT_A_NUMBER
+//	url, uri          -> INVALID_URL
+//	any other tag     -> INVALID
+//
+// Request bodies that cannot be decoded at all are reported as MALFORMED_BODY.
+const (
+	CodeRequired      = "REQUIRED"
+	CodeInvalidEmail  = "INVALID_EMAIL"
+	CodeTooShort      = "TOO_SHORT"
+	CodeTooSmall      = "TOO_SMALL"
+	CodeTooLong       = "TOO_LONG"
+	CodeTooLarge      = "TOO_LARGE"
+	CodeInvalidLength = "INVALID_LENGTH"
+	CodeInvalidChoice = "INVALID_CHOICE"
+	CodeNotANumber    = "NOT_A_NUMBER"
+	CodeInvalidURL    = "INVALID_URL"
+	CodeInvalid       = "INVALID"
+	CodeMalformedBody = "MALFORMED_BODY"
+)
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// strictFields controls whether unknown names in the fields query parameter are rejected
+var strictFields = false
+
+// SetStrictFields sets whether SelectFields answers 400 for unknown field names instead of
+// dropping them.
+func SetStrictFields(enabled bool) {
+	strictFields = enabled
+}
+
+// FieldsOf returns the JSON field names of a struct, for use as the allowed fields of
+// SelectFields. Fields tagged json:"-" are left out.
+func FieldsOf(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// SelectFields trims data, an object or a list of objects, to the fields named in the
+// comma-separated fields query parameter, e.g. ?fields=id,email. Without the parameter, or
+// when none of its names are allowed, data is returned unchanged. Names not in allowed are
+// dropped, or in strict mode answered with 400, in which case ok is false and the caller must
+// not write a response.
+func SelectFields(c *gin.Context, data interface{}, allowed []string) (selected interface{}, ok bool) {
+	param := c.Query("fields")
+	if param == "" {
+		return data, true
+	}
+
+	isAllowed := make(map[string]bool, len(allowed))
+	for _, field := range allowed {
+		isAllowed[field] = true
+	}
+
+	wanted := make(map[string]bool)
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !isAllowed[field] {
+			if strictFields {
+				ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", []ErrorDetail{{
+					Code:    CodeInvalidChoice,
+					Field:   "fields",
+					Message: "unknown field " + field + "; allowed fields are " + strings.Join(allowed, ", "),
+					Value:   field,
+				}})
+				return nil, false
+			}
+			continue
+		}
+		wanted[field] = true
+	}
+	if len(wanted) == 0 {
+		return data, true
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return data, true
+	}
+
+	// A list keeps the selected fields of each item
+	var list []map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &list); err == nil {
+		for _, item := range list {
+			keepFields(item, wanted)
+		}
+		return list, true
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &object); err != nil {
+		return data, true
+	}
+	keepFields(object, wanted)
+	return object, true
+}
+
+// keepFields deletes the fields of object that are not wanted
+func keepFields(object map[string]json.RawMessage, wanted map[string]bool) {
+	for field := range object {
+		if !wanted[field] {
+			delete(object, field)
+		}
+	}
+}
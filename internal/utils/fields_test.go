@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fieldsTestUser struct {
+	ID       uint   `json:"id"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Password string `json:"-"`
+	Nickname string `json:"nickname,omitempty"`
+	internal string
+}
+
+func TestFieldsOf(t *testing.T) {
+	got := FieldsOf(fieldsTestUser{})
+	want := []string{"id", "email", "role", "nickname"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldsOf() = %v, want %v", got, want)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	user := fieldsTestUser{ID: 1, Email: "test@example.com", Role: "admin", Nickname: "tester"}
+	allowed := FieldsOf(user)
+
+	tests := []struct {
+		name       string
+		target     string
+		data       interface{}
+		wantFields []string
+	}{
+		{"All fields by default", "/", user, []string{"email", "id", "nickname", "role"}},
+		{"Selected fields", "/?fields=id,email", user, []string{"email", "id"}},
+		{"Spaces and empty names", "/?fields=%20id,,role%20", user, []string{"id", "role"}},
+		{"Unknown fields dropped", "/?fields=id,password,secret", user, []string{"id"}},
+		{"Only unknown fields", "/?fields=password", user, []string{"email", "id", "nickname", "role"}},
+		{"Pointer", "/?fields=role", &user, []string{"role"}},
+		{"List", "/?fields=email", []fieldsTestUser{user, user}, []string{"email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(t, tt.target, func(c *gin.Context) {
+				data, ok := SelectFields(c, tt.data, allowed)
+				if !ok {
+					t.Fatal("SelectFields() ok = false, want true")
+				}
+				c.JSON(http.StatusOK, gin.H{"data": data})
+			})
+
+			data := decodeBody(t, w)["data"]
+			items, isList := data.([]interface{})
+			if !isList {
+				items = []interface{}{data}
+			}
+			for _, item := range items {
+				var got []string
+				for field := range item.(map[string]interface{}) {
+					got = append(got, field)
+				}
+				sort.Strings(got)
+				if !reflect.DeepEqual(got, tt.wantFields) {
+					t.Errorf("SelectFields() fields = %v, want %v", got, tt.wantFields)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectFields_Strict(t *testing.T) {
+	SetStrictFields(true)
+	defer SetStrictFields(false)
+
+	w := performRequest(t, "/?fields=id,password", func(c *gin.Context) {
+		if _, ok := SelectFields(c, fieldsTestUser{ID: 1}, []string{"id", "email"}); ok {
+			t.Error("SelectFields() ok = true, want false for an unknown field")
+		}
+	})
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("SelectFields() status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	details := decodeBody(t, w)["data"].(map[string]interface{})["errors"].([]interface{})
+	detail := details[0].(map[string]interface{})
+	if detail["field"] != "fields" || detail["code"] != CodeInvalidChoice || detail["value"] != "password" {
+		t.Errorf("SelectFields() error detail = %v, want an invalid choice for password", detail)
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GETWithHEAD registers handlers for GET and HEAD on path, so CDNs and link checkers can
+// probe a resource. HEAD runs the GET handlers and answers with the same status and headers,
+// including ETag and Content-Length, but without the body.
+//
+// Only use it for read-only endpoints: HEAD requests run the handlers in full.
+func GETWithHEAD(routes gin.IRoutes, path string, handlers ...gin.HandlerFunc) {
+	routes.GET(path, handlers...)
+	routes.HEAD(path, append([]gin.HandlerFunc{discardBody}, handlers...)...)
+}
+
+// headWriter counts the body written by GET handlers instead of sending it
+type headWriter struct {
+	gin.ResponseWriter
+	size int
+}
+
+func (w *headWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	return len(data), nil
+}
+
+func (w *headWriter) WriteString(s string) (int, error) {
+	w.size += len(s)
+	return len(s), nil
+}
+
+// discardBody lets the rest of the chain write its response, then sends only the headers
+// with the Content-Length of the body that was dropped
+func discardBody(c *gin.Context) {
+	writer := &headWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	defer func() { c.Writer = writer.ResponseWriter }()
+
+	c.Next()
+
+	if writer.Header().Get("Content-Length") == "" && writer.size > 0 {
+		writer.Header().Set("Content-Length", strconv.Itoa(writer.size))
+	}
+	writer.ResponseWriter.WriteHeaderNow()
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupHeadRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	GETWithHEAD(router, "/pages/:slug", func(c *gin.Context) {
+		if c.Param("slug") != "about" {
+			NotFoundResponse(c, "Page")
+			return
+		}
+		content := []byte(`{"title":"About us"}`)
+		c.Header("Cache-Control", "public, max-age=0, no-cache")
+		c.Header("ETag", `"about-v1"`)
+		if c.GetHeader("If-None-Match") == `"about-v1"` {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.Data(http.StatusOK, "application/json", content)
+	})
+	return router
+}
+
+func TestGETWithHEAD(t *testing.T) {
+	router := setupHeadRouter()
+
+	get := httptest.NewRecorder()
+	router.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/pages/about", nil))
+
+	head := httptest.NewRecorder()
+	router.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/pages/about", nil))
+
+	if head.Code != http.StatusOK {
+		t.Fatalf("HEAD status = %d, want %d", head.Code, http.StatusOK)
+	}
+	if head.Body.Len() != 0 {
+		t.Errorf("HEAD body = %q, want empty", head.Body.String())
+	}
+	for _, header := range []string{"ETag", "Cache-Control", "Content-Type"} {
+		if head.Header().Get(header) != get.Header().Get(header) {
+			t.Errorf("HEAD %s = %q, want %q as for GET", header, head.Header().Get(header), get.Header().Get(header))
+		}
+	}
+	if want := strconv.Itoa(get.Body.Len()); head.Header().Get("Content-Length") != want {
+		t.Errorf("HEAD Content-Length = %q, want %q", head.Header().Get("Content-Length"), want)
+	}
+}
+
+func TestGETWithHEAD_StatusCodes(t *testing.T) {
+	router := setupHeadRouter()
+
+	tests := []struct {
+		name        string
+		target      string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{"Missing slug", "/pages/missing", "", http.StatusNotFound},
+		{"Not modified", "/pages/about", `"about-v1"`, http.StatusNotModified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodHead, tt.target, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("HEAD status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Body.Len() != 0 {
+				t.Errorf("HEAD body = %q, want empty", w.Body.String())
+			}
+		})
+	}
+}
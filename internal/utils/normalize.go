@@ -0,0 +1,25 @@
+package utils
+
+import "strings"
+
+// collapseNameWhitespace controls whether NormalizeName collapses whitespace inside names
+var collapseNameWhitespace = true
+
+// SetCollapseNameWhitespace sets whether NormalizeName replaces runs of whitespace inside a
+// name with a single space, so "Mary   Ann" is stored as "Mary Ann".
+func SetCollapseNameWhitespace(enabled bool) {
+	collapseNameWhitespace = enabled
+}
+
+// NormalizeEmail trims and lowercases an email, the form in which emails are stored and looked up
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeName trims a person's name and, unless disabled, collapses the whitespace inside it
+func NormalizeName(name string) string {
+	if collapseNameWhitespace {
+		return strings.Join(strings.Fields(name), " ")
+	}
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,42 @@
+package utils
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"john@example.com", "john@example.com"},
+		{"  John.Doe@Example.COM  ", "john.doe@example.com"},
+		{"\tJOHN@EXAMPLE.COM\n", "john@example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeEmail(tt.email); got != tt.want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		collapse bool
+		want     string
+	}{
+		{"  John  ", true, "John"},
+		{"Mary   Ann", true, "Mary Ann"},
+		{" Mary \t Ann\n", true, "Mary Ann"},
+		{"  Mary   Ann  ", false, "Mary   Ann"},
+		{"   ", true, ""},
+	}
+
+	defer SetCollapseNameWhitespace(true)
+	for _, tt := range tests {
+		SetCollapseNameWhitespace(tt.collapse)
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) with collapse %v = %q, want %q", tt.name, tt.collapse, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sort orders accepted by the order query parameter
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// PageRequest holds the validated pagination and sorting parameters of a list request
+type PageRequest struct {
+	Page     int
+	PageSize int
+	Sort     string
+	Order    string
+}
+
+// Offset returns the number of rows to skip for the requested page
+func (p PageRequest) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// OrderClause returns the ORDER BY clause for the request, or "" when no sort field is set.
+// Sort is only ever one of the configured SortFields, so it is safe to interpolate.
+func (p PageRequest) OrderClause() string {
+	if p.Sort == "" {
+		return ""
+	}
+	return p.Sort + " " + p.Order
+}
+
+// PageConfig sets the pagination defaults and bounds of an endpoint
+type PageConfig struct {
+	DefaultPageSize int
+	MaxPageSize     int
+	// SortFields lists the fields clients may sort by; other values fall back to DefaultSort
+	SortFields   []string
+	DefaultSort  string
+	DefaultOrder string
+}
+
+// DefaultPageConfig returns the pagination defaults used by BindPageRequest
+func DefaultPageConfig() PageConfig {
+	return PageConfig{
+		DefaultPageSize: 10,
+		MaxPageSize:     100,
+		DefaultOrder:    OrderAsc,
+	}
+}
+
+// BindPageRequest parses page, page_size, sort and order with the default bounds
+func BindPageRequest(c *gin.Context) PageRequest {
+	return BindPageRequestWithConfig(c, DefaultPageConfig())
+}
+
+// BindPageRequestWithConfig parses page, page_size, sort and order using the endpoint's config.
+// Values that are missing or invalid fall back to the defaults; page_size is capped at MaxPageSize.
+func BindPageRequestWithConfig(c *gin.Context, config PageConfig) PageRequest {
+	defaults := DefaultPageConfig()
+	if config.DefaultPageSize <= 0 {
+		config.DefaultPageSize = defaults.DefaultPageSize
+	}
+	if config.MaxPageSize <= 0 {
+		config.MaxPageSize = defaults.MaxPageSize
+	}
+	if config.DefaultOrder != OrderDesc {
+		config.DefaultOrder = OrderAsc
+	}
+
+	req := PageRequest{
+		Page:     1,
+		PageSize: config.DefaultPageSize,
+		Sort:     config.DefaultSort,
+		Order:    config.DefaultOrder,
+	}
+
+	if page, err := parseInt(c.Query("page")); err == nil && page > 0 {
+		req.Page = page
+	}
+
+	if pageSize, err := parseInt(c.Query("page_size")); err == nil && pageSize > 0 {
+		req.PageSize = min(pageSize, config.MaxPageSize)
+	}
+
+	if sort := c.Query("sort"); sort != "" && containsString(config.SortFields, sort) {
+		req.Sort = sort
+	}
+
+	switch order := strings.ToLower(c.Query("order")); order {
+	case OrderAsc, OrderDesc:
+		req.Order = order
+	}
+
+	return req
+}
+
+// BindSortedPageRequest is BindPageRequestWithConfig for endpoints that reject, rather than
+// ignore, a sort field outside config.SortFields or an order other than asc or desc. On such
+// values it answers 400 and returns false, in which case the caller must not write a response.
+func BindSortedPageRequest(c *gin.Context, config PageConfig) (PageRequest, bool) {
+	if sort := c.Query("sort"); sort != "" && !containsString(config.SortFields, sort) {
+		ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", []ErrorDetail{{
+			Code:    CodeInvalidChoice,
+			Field:   "sort",
+			Message: "sort must be one of " + strings.Join(config.SortFields, ", "),
+			Value:   sort,
+		}})
+		return PageRequest{}, false
+	}
+
+	switch order := strings.ToLower(c.Query("order")); order {
+	case "", OrderAsc, OrderDesc:
+	default:
+		ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", []ErrorDetail{{
+			Code:    CodeInvalidChoice,
+			Field:   "order",
+			Message: "order must be one of asc, desc",
+			Value:   c.Query("order"),
+		}})
+		return PageRequest{}, false
+	}
+
+	return BindPageRequestWithConfig(c, config), true
+}
+
+// CursorRequest holds the validated parameters of a cursor-paginated list request
+type CursorRequest struct {
+	// Cursor is the ID of the last row of the previous page; 0 starts from the beginning
+	Cursor uint
+	Limit  int
+}
+
+// BindCursorRequest parses cursor and limit for endpoints that page by ID rather than by
+// offset. limit falls back to config.DefaultPageSize and is capped at MaxPageSize like
+// page_size. A cursor that is not an ID is answered 400 and returns false, in which case the
+// caller must not write a response.
+func BindCursorRequest(c *gin.Context, config PageConfig) (CursorRequest, bool) {
+	defaults := DefaultPageConfig()
+	if config.DefaultPageSize <= 0 {
+		config.DefaultPageSize = defaults.DefaultPageSize
+	}
+	if config.MaxPageSize <= 0 {
+		config.MaxPageSize = defaults.MaxPageSize
+	}
+
+	req := CursorRequest{Limit: config.DefaultPageSize}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		id, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", []ErrorDetail{{
+				Code:    CodeNotANumber,
+				Field:   "cursor",
+				Message: "cursor must be the next_cursor of the previous page",
+				Value:   cursor,
+			}})
+			return CursorRequest{}, false
+		}
+		req.Cursor = uint(id)
+	}
+
+	if limit, err := parseInt(c.Query("limit")); err == nil && limit > 0 {
+		req.Limit = min(limit, config.MaxPageSize)
+	}
+
+	return req, true
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RespondPaged sends a paginated success response for req, with pagination metadata
+// and a Link header pointing at the first, previous, next and last pages
+func RespondPaged(c *gin.Context, data interface{}, total int64, req PageRequest) {
+	pagination := CalculatePagination(req.Page, req.PageSize, int(total))
+	if link := pageLinks(c, pagination); link != "" {
+		c.Header("Link", link)
+	}
+
+	PaginatedSuccessResponse(c, http.StatusOK, "Items retrieved successfully", data, pagination)
+}
+
+// pageLinks builds an RFC 8288 Link header value for the pages around the current one
+func pageLinks(c *gin.Context, pagination Pagination) string {
+	if pagination.TotalPages == 0 {
+		return ""
+	}
+
+	link := func(page int, rel string) string {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("page_size", strconv.Itoa(pagination.PageSize))
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, c.Request.URL.Path, query.Encode(), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if pagination.CurrentPage > 1 {
+		links = append(links, link(min(pagination.CurrentPage-1, pagination.TotalPages), "prev"))
+	}
+	if pagination.CurrentPage < pagination.TotalPages {
+		links = append(links, link(pagination.CurrentPage+1, "next"))
+	}
+	links = append(links, link(pagination.TotalPages, "last"))
+
+	return strings.Join(links, ", ")
+}
+
+// RespondCursor sends a cursor-paginated success response for req. next is the cursor of the
+// following page, or 0 on the last page; when set, a Link header points at the next page.
+func RespondCursor(c *gin.Context, data interface{}, next uint, req CursorRequest) {
+	if next != 0 {
+		query := c.Request.URL.Query()
+		query.Set("cursor", strconv.FormatUint(uint64(next), 10))
+		query.Set("limit", strconv.Itoa(req.Limit))
+		c.Header("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, c.Request.URL.Path, query.Encode()))
+	}
+
+	CursorSuccessResponse(c, http.StatusOK, "Items retrieved successfully", data, CursorPagination{
+		NextCursor: next,
+		Limit:      req.Limit,
+	})
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func bindPage(target string, config PageConfig) PageRequest {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return BindPageRequestWithConfig(c, config)
+}
+
+func TestBindPageRequest_Bounds(t *testing.T) {
+	config := PageConfig{
+		DefaultPageSize: 20,
+		MaxPageSize:     50,
+		SortFields:      []string{"id", "email"},
+		DefaultSort:     "id",
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		want   PageRequest
+	}{
+		{"Defaults", "/users", PageRequest{Page: 1, PageSize: 20, Sort: "id", Order: OrderAsc}},
+		{"Valid values", "/users?page=3&page_size=30&sort=email&order=DESC", PageRequest{Page: 3, PageSize: 30, Sort: "email", Order: OrderDesc}},
+		{"Page size above max", "/users?page_size=500", PageRequest{Page: 1, PageSize: 50, Sort: "id", Order: OrderAsc}},
+		{"Zero and negative", "/users?page=0&page_size=-5", PageRequest{Page: 1, PageSize: 20, Sort: "id", Order: OrderAsc}},
+		{"Not numbers", "/users?page=abc&page_size=1e3", PageRequest{Page: 1, PageSize: 20, Sort: "id", Order: OrderAsc}},
+		{"Unknown sort field", "/users?sort=password", PageRequest{Page: 1, PageSize: 20, Sort: "id", Order: OrderAsc}},
+		{"Invalid order", "/users?order=sideways", PageRequest{Page: 1, PageSize: 20, Sort: "id", Order: OrderAsc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bindPage(tt.target, config); got != tt.want {
+				t.Errorf("BindPageRequestWithConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindSortedPageRequest(t *testing.T) {
+	config := PageConfig{
+		SortFields:   []string{"created_at", "email"},
+		DefaultSort:  "created_at",
+		DefaultOrder: OrderDesc,
+	}
+
+	tests := []struct {
+		name      string
+		target    string
+		wantOK    bool
+		wantOrder string
+		wantField string
+	}{
+		{"Default sort", "/users", true, "created_at desc", ""},
+		{"Allowed field", "/users?sort=email", true, "email desc", ""},
+		{"Allowed field and order", "/users?sort=email&order=ASC", true, "email asc", ""},
+		{"Disallowed field", "/users?sort=password", false, "", "sort"},
+		{"Injected clause", "/users?sort=email%3BDROP%20TABLE%20users", false, "", "sort"},
+		{"Invalid order", "/users?sort=email&order=sideways", false, "", "order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, tt.target, nil)
+
+			req, ok := BindSortedPageRequest(c, config)
+			if ok != tt.wantOK {
+				t.Fatalf("BindSortedPageRequest() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok {
+				if got := req.OrderClause(); got != tt.wantOrder {
+					t.Errorf("OrderClause() = %q, want %q", got, tt.wantOrder)
+				}
+				return
+			}
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"field":"`+tt.wantField+`"`) {
+				t.Errorf("BindSortedPageRequest() response = %d %s, want 400 on %s", w.Code, w.Body.String(), tt.wantField)
+			}
+		})
+	}
+}
+
+func TestBindPageRequest_DefaultConfig(t *testing.T) {
+	got := bindPage("/users?page_size=1000&sort=email", PageConfig{})
+	want := PageRequest{Page: 1, PageSize: 100, Order: OrderAsc}
+	if got != want {
+		t.Errorf("BindPageRequestWithConfig() = %+v, want %+v", got, want)
+	}
+	if got.Offset() != 0 || (PageRequest{Page: 3, PageSize: 10}).Offset() != 20 {
+		t.Errorf("Offset() returned the wrong row offset")
+	}
+}
+
+func TestRespondPaged(t *testing.T) {
+	w := performRequest(t, "/api/v1/users?page=2&page_size=10&role=admin", func(c *gin.Context) {
+		req := BindPageRequest(c)
+		RespondPaged(c, []string{"a", "b"}, 35, req)
+	})
+
+	body := decodeBody(t, w)
+	pagination, _ := body["pagination"].(map[string]interface{})
+	if pagination["total_items"] != float64(35) || pagination["total_pages"] != float64(4) || pagination["current_page"] != float64(2) {
+		t.Errorf("RespondPaged() pagination = %v, want 35 items over 4 pages on page 2", pagination)
+	}
+
+	link := w.Header().Get("Link")
+	for _, want := range []string{
+		`</api/v1/users?page=1&page_size=10&role=admin>; rel="first"`,
+		`</api/v1/users?page=1&page_size=10&role=admin>; rel="prev"`,
+		`</api/v1/users?page=3&page_size=10&role=admin>; rel="next"`,
+		`</api/v1/users?page=4&page_size=10&role=admin>; rel="last"`,
+	} {
+		if !strings.Contains(link, want) {
+			t.Errorf("Link = %q, want it to contain %q", link, want)
+		}
+	}
+}
+
+func TestBindCursorRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		wantOK bool
+		want   CursorRequest
+	}{
+		{"First page", "/users?cursor=", true, CursorRequest{Limit: 10}},
+		{"Next page", "/users?cursor=42&limit=5", true, CursorRequest{Cursor: 42, Limit: 5}},
+		{"Limit capped", "/users?cursor=42&limit=1000", true, CursorRequest{Cursor: 42, Limit: 100}},
+		{"Invalid limit", "/users?cursor=42&limit=-1", true, CursorRequest{Cursor: 42, Limit: 10}},
+		{"Invalid cursor", "/users?cursor=abc", false, CursorRequest{}},
+		{"Negative cursor", "/users?cursor=-1", false, CursorRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, tt.target, nil)
+
+			got, ok := BindCursorRequest(c, PageConfig{})
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("BindCursorRequest() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+			if !ok && (w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"field":"cursor"`)) {
+				t.Errorf("BindCursorRequest() response = %d %s, want 400 on cursor", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRespondCursor(t *testing.T) {
+	w := performRequest(t, "/api/v1/users?cursor=10&limit=2", func(c *gin.Context) {
+		req, _ := BindCursorRequest(c, PageConfig{})
+		RespondCursor(c, []string{"a", "b"}, 12, req)
+	})
+
+	body := decodeBody(t, w)
+	cursor, _ := body["cursor"].(map[string]interface{})
+	if cursor["next_cursor"] != float64(12) || cursor["limit"] != float64(2) {
+		t.Errorf("RespondCursor() cursor = %v, want next cursor 12 and limit 2", cursor)
+	}
+
+	want := `</api/v1/users?cursor=12&limit=2>; rel="next"`
+	if link := w.Header().Get("Link"); link != want {
+		t.Errorf("Link = %q, want %q", link, want)
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicy sets the rules a new password must follow
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters (default 6, the binding minimum)
+	MinLength int
+	// RequireUpper, RequireLower and RequireDigit require at least one character of each kind
+	RequireUpper bool
+	RequireLower bool
+	RequireDigit bool
+	// RejectCommon rejects the most commonly used passwords, ignoring case
+	RejectCommon bool
+}
+
+// DefaultPasswordPolicy returns the rules ValidatePasswordStrength checks
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:    8,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		RejectCommon: true,
+	}
+}
+
+// defaultMinPasswordLength is the shortest password any policy accepts
+const defaultMinPasswordLength = 6
+
+// commonPasswords are rejected by policies with RejectCommon, compared in lower case. They
+// are among the most frequent passwords in public breach corpora.
+var commonPasswords = map[string]bool{
+	"123456": true, "1234567": true, "12345678": true, "123456789": true, "1234567890": true,
+	"111111": true, "000000": true, "123123": true, "654321": true, "666666": true,
+	"password": true, "password1": true, "password12": true, "password123": true, "passw0rd": true,
+	"qwerty": true, "qwerty123": true, "qwertyuiop": true, "1q2w3e4r": true, "1qaz2wsx": true,
+	"abc123": true, "abcd1234": true, "iloveyou": true, "admin": true, "admin123": true,
+	"welcome": true, "welcome1": true, "letmein": true, "monkey": true, "dragon": true,
+	"sunshine": true, "princess": true, "football": true, "baseball": true, "superman": true,
+	"trustno1": true, "changeme": true, "secret": true, "master": true, "login": true,
+}
+
+// ValidatePasswordStrength checks password against DefaultPasswordPolicy. It returns an error
+// detail per broken rule, or none when the password is strong enough.
+func ValidatePasswordStrength(password string) []ErrorDetail {
+	return DefaultPasswordPolicy().Validate("password", password)
+}
+
+// Validate checks password against the policy and returns an error detail on field per
+// broken rule, ready for ValidationErrorResponse
+func (p PasswordPolicy) Validate(field, password string) []ErrorDetail {
+	minLength := p.MinLength
+	if minLength <= 0 {
+		minLength = defaultMinPasswordLength
+	}
+
+	var details []ErrorDetail
+	fail := func(code, message string) {
+		details = append(details, ErrorDetail{Code: code, Field: field, Message: field + " " + message})
+	}
+
+	if utf8.RuneCountInString(password) < minLength {
+		fail(CodeTooShort, fmt.Sprintf("must be at least %d characters", minLength))
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		fail(CodePasswordMissingUpper, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		fail(CodePasswordMissingLower, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		fail(CodePasswordMissingDigit, "must contain a digit")
+	}
+
+	if p.RejectCommon && commonPasswords[strings.ToLower(password)] {
+		fail(CodePasswordTooCommon, "is too common")
+	}
+
+	return details
+}
@@ -0,0 +1,62 @@
+package utils
+
+import "testing"
+
+func TestValidatePasswordStrength(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		wantCodes []string
+	}{
+		{"Strong password", "Correct7Horse", nil},
+		{"Strong password with symbols", "Ünïcödé-Pässw0rd!", nil},
+		{"Too short", "Ab1defg", []string{CodeTooShort}},
+		{"Missing uppercase", "correct7horse", []string{CodePasswordMissingUpper}},
+		{"Missing lowercase", "CORRECT7HORSE", []string{CodePasswordMissingLower}},
+		{"Missing digit", "CorrectHorse", []string{CodePasswordMissingDigit}},
+		{"Common password", "Password123", []string{CodePasswordTooCommon}},
+		{"Every rule broken", "123456", []string{CodeTooShort, CodePasswordMissingUpper, CodePasswordMissingLower, CodePasswordTooCommon}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := ValidatePasswordStrength(tt.password)
+			if len(details) != len(tt.wantCodes) {
+				t.Fatalf("ValidatePasswordStrength(%q) = %+v, want codes %v", tt.password, details, tt.wantCodes)
+			}
+			for i, detail := range details {
+				if detail.Code != tt.wantCodes[i] || detail.Field != "password" {
+					t.Errorf("ValidatePasswordStrength(%q)[%d] = %s on %s, want %s on password", tt.password, i, detail.Code, detail.Field, tt.wantCodes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    PasswordPolicy
+		password  string
+		wantCodes []string
+	}{
+		{"Relaxed policy accepts weak passwords", PasswordPolicy{}, "password123", nil},
+		{"Relaxed policy still checks the length", PasswordPolicy{}, "short", []string{CodeTooShort}},
+		{"Custom minimum length", PasswordPolicy{MinLength: 12}, "password123", []string{CodeTooShort}},
+		{"Single rule", PasswordPolicy{RequireDigit: true}, "password", []string{CodePasswordMissingDigit}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := tt.policy.Validate("new_password", tt.password)
+			if len(details) != len(tt.wantCodes) {
+				t.Fatalf("Validate(%q) = %+v, want codes %v", tt.password, details, tt.wantCodes)
+			}
+			for i, detail := range details {
+				if detail.Code != tt.wantCodes[i] || detail.Field != "new_password" {
+					t.Errorf("Validate(%q)[%d] = %s on %s, want %s on new_password", tt.password, i, detail.Code, detail.Field, tt.wantCodes[i])
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +15,7 @@ type APIResponse struct {
 	Message   string      `json:"message,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
 	Error     string      `json:"error,omitempty"`
+	Code      string      `json:"code,omitempty"`
 	Timestamp time.Time   `json:"timestamp"`
 	RequestID string      `json:"request_id,omitempty"`
 }
@@ -35,6 +38,22 @@ type Pagination struct {
 	PageSize    int `json:"page_size"`
 }
 
+// Cursor Pagination Response represents a cursor-paginated API response
+type CursorPaginationResponse struct {
+	Success   bool             `json:"success"`
+	Message   string           `json:"message,omitempty"`
+	Data      interface{}      `json:"data,omitempty"`
+	Cursor    CursorPagination `json:"cursor"`
+	Timestamp time.Time        `json:"timestamp"`
+	RequestID string           `json:"request_id,omitempty"`
+}
+
+// CursorPagination represents cursor pagination metadata; NextCursor is omitted on the last page
+type CursorPagination struct {
+	NextCursor uint `json:"next_cursor,omitempty"`
+	Limit      int  `json:"limit"`
+}
+
 // Error Detail represents a single error detail
 type ErrorDetail struct {
 	Code    string      `json:"code,omitempty"`
@@ -49,13 +68,38 @@ type ValidationError struct {
 	Error   []ErrorDetail `json:"errors"`
 }
 
+// envelopeEnabled controls whether success responses are wrapped in APIResponse by default
+var envelopeEnabled = true
+
+// SetEnvelope sets whether success responses are wrapped in the standard envelope by default.
+// Error responses are always wrapped.
+func SetEnvelope(enabled bool) {
+	envelopeEnabled = enabled
+}
+
+// useEnvelope reports whether the success response for this request should be wrapped,
+// letting the envelope query parameter override the configured default
+func useEnvelope(c *gin.Context) bool {
+	if value, ok := c.GetQuery("envelope"); ok {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			return enabled
+		}
+	}
+	return envelopeEnabled
+}
+
 // Success Response sends a success response
 func SuccessResponse(c *gin.Context, statusCode int, message string, data interface{}) {
+	if !useEnvelope(c) {
+		c.JSON(statusCode, data)
+		return
+	}
+
 	response := APIResponse{
 		Success:   true,
 		Message:   message,
 		Data:      data,
-		Timestamp: time.Now(),
+		Timestamp: now(),
 		RequestID: getRequestID(c),
 	}
 
@@ -73,7 +117,26 @@ func ErrorResponse(c *gin.Context, statusCode int, message string, err error) {
 		Success:   false,
 		Message:   message,
 		Error:     errorData,
-		Timestamp: time.Now(),
+		Timestamp: now(),
+		RequestID: getRequestID(c),
+	}
+
+	c.JSON(statusCode, response)
+}
+
+// Error Response With Code sends an error response carrying a machine-readable error code
+func ErrorResponseWithCode(c *gin.Context, statusCode int, code string, message string, err error) {
+	var errorData string
+	if err != nil {
+		errorData = err.Error()
+	}
+
+	response := APIResponse{
+		Success:   false,
+		Message:   message,
+		Error:     errorData,
+		Code:      code,
+		Timestamp: now(),
 		RequestID: getRequestID(c),
 	}
 
@@ -91,7 +154,7 @@ func ValidationErrorResponse(c *gin.Context, statusCode int, message string, err
 		Success:   false,
 		Message:   "Validation Error",
 		Data:      ValidationError,
-		Timestamp: time.Now(),
+		Timestamp: now(),
 		RequestID: getRequestID(c),
 	}
 
@@ -100,18 +163,51 @@ func ValidationErrorResponse(c *gin.Context, statusCode int, message string, err
 
 // Paginated Success Response sends a paginated success response
 func PaginatedSuccessResponse(c *gin.Context, statusCode int, message string, data interface{}, pagination Pagination) {
+	if !useEnvelope(c) {
+		// Without the envelope, pagination metadata travels in headers
+		c.Header("X-Total-Count", strconv.Itoa(pagination.TotalItems))
+		c.Header("X-Total-Pages", strconv.Itoa(pagination.TotalPages))
+		c.Header("X-Page", strconv.Itoa(pagination.CurrentPage))
+		c.Header("X-Page-Size", strconv.Itoa(pagination.PageSize))
+		c.JSON(statusCode, data)
+		return
+	}
+
 	response := PaginationResponse{
 		Success:    true,
 		Message:    message,
 		Data:       data,
 		Pagination: pagination,
-		Timestamp:  time.Now(),
+		Timestamp:  now(),
 		RequestID:  getRequestID(c),
 	}
 
 	c.JSON(statusCode, response)
 }
 
+// Cursor Paginated Success Response sends a cursor-paginated success response
+func CursorSuccessResponse(c *gin.Context, statusCode int, message string, data interface{}, cursor CursorPagination) {
+	if !useEnvelope(c) {
+		// Without the envelope, the next cursor travels in a header
+		if cursor.NextCursor != 0 {
+			c.Header("X-Next-Cursor", strconv.FormatUint(uint64(cursor.NextCursor), 10))
+		}
+		c.JSON(statusCode, data)
+		return
+	}
+
+	response := CursorPaginationResponse{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Cursor:    cursor,
+		Timestamp: now(),
+		RequestID: getRequestID(c),
+	}
+
+	c.JSON(statusCode, response)
+}
+
 // NotFoundResponse sends a 404 not found response
 func NotFoundResponse(c *gin.Context, message string) {
 	ErrorResponse(c, 404, message+" not found", nil)
@@ -157,6 +253,46 @@ func ConflictResponse(c *gin.Context, message string, err error) {
 	ErrorResponse(c, 409, message, err)
 }
 
+// MethodNotAllowedResponse sends a 405 method not allowed response
+func MethodNotAllowedResponse(c *gin.Context, message string) {
+	if message == "" {
+		message = "Method Not Allowed"
+	}
+	ErrorResponse(c, 405, message, nil)
+}
+
+// UnsupportedMediaTypeResponse sends a 415 unsupported media type response
+func UnsupportedMediaTypeResponse(c *gin.Context, message string) {
+	if message == "" {
+		message = "Unsupported Media Type"
+	}
+	ErrorResponse(c, 415, message, nil)
+}
+
+// TooManyRequestsResponse sends a 429 too many requests response
+func TooManyRequestsResponse(c *gin.Context, message string) {
+	if message == "" {
+		message = "Too Many Requests"
+	}
+	ErrorResponse(c, 429, message, nil)
+}
+
+// ServiceUnavailableResponse sends a 503 service unavailable response
+func ServiceUnavailableResponse(c *gin.Context, message string) {
+	if message == "" {
+		message = "Service Unavailable"
+	}
+	ErrorResponse(c, 503, message, nil)
+}
+
+// GatewayTimeoutResponse sends a 504 gateway timeout response
+func GatewayTimeoutResponse(c *gin.Context, message string) {
+	if message == "" {
+		message = "Gateway Timeout"
+	}
+	ErrorResponse(c, 504, message, nil)
+}
+
 // CreatedResponse sends a 201 created response
 func CreatedResponse(c *gin.Context, message string, data interface{}) {
 	if message == "" {
@@ -174,7 +310,7 @@ func NoContentResponse(c *gin.Context) {
 func HealthCheckResponse(c *gin.Context, status string, details interface{}) {
 	response := gin.H{
 		"status":    status,
-		"timestamp": time.Now(),
+		"timestamp": now(),
 		"service":   getEnv("APP_NAME", "Customable Corporate Site API"),
 		"version":   "1.0.0",
 		"details":   details,
@@ -213,42 +349,17 @@ func CalculatePagination(currentPage, pageSize int, totalItems int) Pagination {
 	}
 }
 
-// Parse Pagination extracts pagination parameters from the request
+// Parse Pagination extracts pagination parameters from the request with the default bounds:
+// the page defaults to 1 and page_size to 10, capped at 100. Values that are missing, not
+// numbers or out of range, such as page=0, fall back to the defaults.
 func ParsePagination(c *gin.Context) (int, int) {
-	page := 1
-	pageSize := 10
-
-	if p := c.Query("page"); p != "" {
-		if pageNum := parseInt(p); pageNum > 0 {
-			page = pageNum
-		}
-	}
-
-	if ps := c.Query("page_size"); ps != "" {
-		if pageSizeNum := parseInt(ps); pageSizeNum > 0 && pageSizeNum <= 100 {
-			pageSize = pageSizeNum
-		}
-	}
-
-	return page, pageSize
+	page := BindPageRequest(c)
+	return page.Page, page.PageSize
 }
 
-// parseInt is a helper function to parse string to int with error handling
-func parseInt(s string) int {
-	if s == "" {
-		return 0
-	}
-
-	// simple conversion, can be enhanced with more error handling
-	result := 0
-	for _, char := range s {
-		if char >= '0' && char <= '9' {
-			result = result*10 + int(char-'0')
-		} else {
-			return 0
-		}
-	}
-	return result
+// parseInt parses a decimal integer query value, ignoring surrounding whitespace
+func parseInt(s string) (int, error) {
+	return strconv.Atoi(strings.TrimSpace(s))
 }
 
 // Response With Metadata sends a response with additional metadata
@@ -258,7 +369,7 @@ func ResponseWithMetadata(c *gin.Context, statusCode int, message string, data i
 		"message":    message,
 		"data":       data,
 		"metadata":   metadata,
-		"timestamp":  time.Now(),
+		"timestamp":  now(),
 		"request_id": getRequestID(c),
 	}
 	c.JSON(statusCode, response)
@@ -270,3 +381,9 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// now returns the current time in UTC, so every timestamp in a response is serialized as
+// RFC 3339 with a Z suffix regardless of the server's local timezone
+func now() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func performRequest(t *testing.T, target string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	handler(c)
+	return w
+}
+
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response body %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+func TestSuccessResponse_Envelope(t *testing.T) {
+	data := gin.H{"id": 1, "email": "test@example.com"}
+
+	tests := []struct {
+		name        string
+		envelope    bool
+		target      string
+		wantWrapped bool
+	}{
+		{"Wrapped by default", true, "/", true},
+		{"Flat by config", false, "/", false},
+		{"Flat by query", true, "/?envelope=false", false},
+		{"Wrapped by query", false, "/?envelope=true", true},
+		{"Invalid query uses config", true, "/?envelope=maybe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetEnvelope(tt.envelope)
+			defer SetEnvelope(true)
+
+			w := performRequest(t, tt.target, func(c *gin.Context) {
+				SuccessResponse(c, http.StatusOK, "User retrieved", data)
+			})
+
+			body := decodeBody(t, w)
+			_, wrapped := body["success"]
+			if wrapped != tt.wantWrapped {
+				t.Fatalf("SuccessResponse() wrapped = %v, want %v (body %s)", wrapped, tt.wantWrapped, w.Body.String())
+			}
+			if !tt.wantWrapped && body["email"] != "test@example.com" {
+				t.Errorf("SuccessResponse() flat body = %v, want the raw data", body)
+			}
+		})
+	}
+}
+
+func TestErrorResponse_AlwaysWrapped(t *testing.T) {
+	SetEnvelope(false)
+	defer SetEnvelope(true)
+
+	w := performRequest(t, "/?envelope=false", func(c *gin.Context) {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid request data", errors.New("bad input"))
+	})
+
+	body := decodeBody(t, w)
+	if body["success"] != false {
+		t.Errorf("ErrorResponse() body = %v, want wrapped error", body)
+	}
+}
+
+func TestPaginatedSuccessResponse_Flat(t *testing.T) {
+	w := performRequest(t, "/?envelope=false", func(c *gin.Context) {
+		PaginatedSuccessResponse(c, http.StatusOK, "Users retrieved", []string{"a", "b"}, CalculatePagination(2, 2, 5))
+	})
+
+	var body []string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode flat paginated body %q: %v", w.Body.String(), err)
+	}
+	if len(body) != 2 {
+		t.Errorf("PaginatedSuccessResponse() returned %d items, want 2", len(body))
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "5" {
+		t.Errorf("X-Total-Count = %q, want %q", got, "5")
+	}
+	if got := w.Header().Get("X-Total-Pages"); got != "3" {
+		t.Errorf("X-Total-Pages = %q, want %q", got, "3")
+	}
+}
+
+func TestResponseTimestamps_UTC(t *testing.T) {
+	// Run as if the server were in a non-UTC timezone
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+
+	handlers := map[string]gin.HandlerFunc{
+		"SuccessResponse": func(c *gin.Context) {
+			SuccessResponse(c, http.StatusOK, "OK", nil)
+		},
+		"ErrorResponse": func(c *gin.Context) {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid request data", errors.New("bad input"))
+		},
+		"ValidationErrorResponse": func(c *gin.Context) {
+			ValidationErrorResponse(c, http.StatusBadRequest, "Invalid request data", nil)
+		},
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			body := decodeBody(t, performRequest(t, "/", handler))
+
+			timestamp, _ := body["timestamp"].(string)
+			if !strings.HasSuffix(timestamp, "Z") {
+				t.Errorf("%s() timestamp = %q, want UTC with a Z suffix", name, timestamp)
+			}
+			if _, err := time.Parse(time.RFC3339, timestamp); err != nil {
+				t.Errorf("%s() timestamp = %q, want RFC 3339: %v", name, timestamp, err)
+			}
+		})
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		wantPage     int
+		wantPageSize int
+	}{
+		{"Defaults", "", 1, 10},
+		{"Valid values", "page=3&page_size=25", 3, 25},
+		{"Whitespace", "page=%202%20&page_size=%0930", 2, 30},
+		{"Plus sign", "page=%2B5", 5, 10},
+		{"Zero page", "page=0", 1, 10},
+		{"Negative values", "page=-2&page_size=-5", 1, 10},
+		{"Zero page size", "page_size=0", 1, 10},
+		{"Page size above max", "page_size=500", 1, 100},
+		{"Overflow", "page=99999999999999999999&page_size=99999999999999999999", 1, 10},
+		{"Not numbers", "page=two&page_size=1e3", 1, 10},
+		{"Trailing garbage", "page=2abc&page_size=20px", 1, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/users?"+tt.query, nil)
+
+			page, pageSize := ParsePagination(c)
+			if page != tt.wantPage || pageSize != tt.wantPageSize {
+				t.Errorf("ParsePagination() = %d, %d, want %d, %d", page, pageSize, tt.wantPage, tt.wantPageSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Binding errors name fields as clients send them, e.g. email rather than Email
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+// requestFieldName returns the JSON name of a request field, or its form name for query
+// parameters. An empty name makes the validator fall back to the Go field name.
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// sensitiveFields are never echoed back as the rejected value of a validation error
+var sensitiveFields = []string{"password", "token", "secret"}
+
+// ValidationErrorDetails converts a binding error into error details carrying a
+// machine-readable code per field, so clients can localize messages themselves. The rejected
+// value is included unless it is empty or the field holds a secret.
+func ValidationErrorDetails(err error) []ErrorDetail {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []ErrorDetail{{
+			Code:    CodeMalformedBody,
+			Message: "Request body could not be parsed",
+		}}
+	}
+
+	details := make([]ErrorDetail, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		details = append(details, ErrorDetail{
+			Code:    validationCode(fieldError),
+			Field:   fieldError.Field(),
+			Message: validationMessage(fieldError),
+			Value:   rejectedValue(fieldError),
+		})
+	}
+	return details
+}
+
+// validationCode maps a validator tag to its error code
+func validationCode(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return CodeRequired
+	case "email":
+		return CodeInvalidEmail
+	case "min":
+		if isLengthKind(fieldError.Kind()) {
+			return CodeTooShort
+		}
+		return CodeTooSmall
+	case "max":
+		if isLengthKind(fieldError.Kind()) {
+			return CodeTooLong
+		}
+		return CodeTooLarge
+	case "len":
+		return CodeInvalidLength
+	case "oneof":
+		return CodeInvalidChoice
+	case "numeric", "number":
+		return CodeNotANumber
+	case "url", "uri":
+		return CodeInvalidURL
+	default:
+		return CodeInvalid
+	}
+}
+
+// validationMessage builds a human-readable message for a validation failure
+func validationMessage(fieldError validator.FieldError) string {
+	field := fieldError.Field()
+	switch validationCode(fieldError) {
+	case CodeRequired:
+		return fmt.Sprintf("%s is required", field)
+	case CodeInvalidEmail:
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case CodeTooShort:
+		return fmt.Sprintf("%s must be at least %s characters long", field, fieldError.Param())
+	case CodeTooSmall:
+		return fmt.Sprintf("%s must be at least %s", field, fieldError.Param())
+	case CodeTooLong:
+		return fmt.Sprintf("%s must be at most %s characters long", field, fieldError.Param())
+	case CodeTooLarge:
+		return fmt.Sprintf("%s must be at most %s", field, fieldError.Param())
+	case CodeInvalidLength:
+		return fmt.Sprintf("%s must be exactly %s characters long", field, fieldError.Param())
+	case CodeInvalidChoice:
+		return fmt.Sprintf("%s must be one of: %s", field, fieldError.Param())
+	case CodeNotANumber:
+		return fmt.Sprintf("%s must be a number", field)
+	case CodeInvalidURL:
+		return fmt.Sprintf("%s must be a valid URL", field)
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
+
+// rejectedValue returns the value that failed validation, or nil when there is nothing worth
+// echoing back
+func rejectedValue(fieldError validator.FieldError) interface{} {
+	name := strings.ToLower(fieldError.Field())
+	for _, sensitive := range sensitiveFields {
+		if strings.Contains(name, sensitive) {
+			return nil
+		}
+	}
+
+	value := reflect.ValueOf(fieldError.Value())
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() || value.IsZero() {
+		return nil
+	}
+	return value.Interface()
+}
+
+func isLengthKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+type validationTestRequest struct {
+	Email    string   `json:"email" binding:"required,email"`
+	Name     string   `json:"name" binding:"omitempty,min=2,max=5"`
+	Code     string   `json:"code" binding:"omitempty,len=3"`
+	Role     string   `json:"role" binding:"omitempty,oneof=admin user"`
+	Age      int      `json:"age" binding:"omitempty,min=18,max=99"`
+	Zip      string   `json:"zip" binding:"omitempty,numeric"`
+	Website  string   `json:"website" binding:"omitempty,url"`
+	Username string   `json:"username" binding:"omitempty,alphanum"`
+	Tags     []string `json:"tags" binding:"omitempty,max=1"`
+}
+
+func TestValidationErrorDetails_Codes(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       validationTestRequest
+		wantField string
+		wantCode  string
+	}{
+		{"required", validationTestRequest{}, "email", CodeRequired},
+		{"email", validationTestRequest{Email: "not-an-email"}, "email", CodeInvalidEmail},
+		{"min string", validationTestRequest{Email: "a@b.co", Name: "J"}, "name", CodeTooShort},
+		{"max string", validationTestRequest{Email: "a@b.co", Name: "Jonathan"}, "name", CodeTooLong},
+		{"len", validationTestRequest{Email: "a@b.co", Code: "ab"}, "code", CodeInvalidLength},
+		{"oneof", validationTestRequest{Email: "a@b.co", Role: "root"}, "role", CodeInvalidChoice},
+		{"min number", validationTestRequest{Email: "a@b.co", Age: 12}, "age", CodeTooSmall},
+		{"max number", validationTestRequest{Email: "a@b.co", Age: 120}, "age", CodeTooLarge},
+		{"numeric", validationTestRequest{Email: "a@b.co", Zip: "12ab"}, "zip", CodeNotANumber},
+		{"url", validationTestRequest{Email: "a@b.co", Website: "not a url"}, "website", CodeInvalidURL},
+		{"max slice", validationTestRequest{Email: "a@b.co", Tags: []string{"a", "b"}}, "tags", CodeTooLong},
+		{"unmapped tag", validationTestRequest{Email: "a@b.co", Username: "john doe"}, "username", CodeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(&tt.req)
+			if err == nil {
+				t.Fatalf("ValidateStruct() expected an error")
+			}
+
+			details := ValidationErrorDetails(err)
+			if len(details) != 1 {
+				t.Fatalf("ValidationErrorDetails() returned %d details, want 1: %+v", len(details), details)
+			}
+			if details[0].Field != tt.wantField {
+				t.Errorf("ValidationErrorDetails() field = %q, want %q", details[0].Field, tt.wantField)
+			}
+			if details[0].Code != tt.wantCode {
+				t.Errorf("ValidationErrorDetails() code = %q, want %q", details[0].Code, tt.wantCode)
+			}
+			if details[0].Message == "" {
+				t.Errorf("ValidationErrorDetails() returned an empty message")
+			}
+		})
+	}
+}
+
+func TestValidationErrorDetails_MalformedBody(t *testing.T) {
+	var req validationTestRequest
+	err := json.Unmarshal([]byte(`{"email":`), &req)
+
+	details := ValidationErrorDetails(err)
+	if len(details) != 1 || details[0].Code != CodeMalformedBody {
+		t.Errorf("ValidationErrorDetails() = %+v, want a single %s detail", details, CodeMalformedBody)
+	}
+}
+
+func TestValidationErrorDetails_Value(t *testing.T) {
+	type passwordRequest struct {
+		Password string `json:"password" binding:"min=8"`
+	}
+
+	tests := []struct {
+		name      string
+		req       interface{}
+		wantValue interface{}
+	}{
+		{"Rejected value", &validationTestRequest{Email: "not-an-email"}, "not-an-email"},
+		{"Missing value", &validationTestRequest{}, nil},
+		{"Secret value", &passwordRequest{Password: "hunter2"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := ValidationErrorDetails(binding.Validator.ValidateStruct(tt.req))
+			if len(details) != 1 || details[0].Value != tt.wantValue {
+				t.Errorf("ValidationErrorDetails() = %+v, want value %v", details, tt.wantValue)
+			}
+		})
+	}
+}